@@ -79,6 +79,59 @@ func (rs *ReturnStatement) String() string {
 	return out.String()
 }
 
+// BreakStatement represents a break statement
+type BreakStatement struct {
+	Token token.Token // the 'break' token
+}
+
+func (bs *BreakStatement) statementNode() {}
+
+// TokenLiteral prints the literal value of the token associated with this node
+func (bs *BreakStatement) TokenLiteral() string { return bs.Token.Literal }
+
+// String returns a stringified version of the AST for debugging
+func (bs *BreakStatement) String() string { return bs.TokenLiteral() + ";" }
+
+// ContinueStatement represents a continue statement
+type ContinueStatement struct {
+	Token token.Token // the 'continue' token
+}
+
+func (cs *ContinueStatement) statementNode() {}
+
+// TokenLiteral prints the literal value of the token associated with this node
+func (cs *ContinueStatement) TokenLiteral() string { return cs.Token.Literal }
+
+// String returns a stringified version of the AST for debugging
+func (cs *ContinueStatement) String() string { return cs.TokenLiteral() + ";" }
+
+// ImportStatement represents an import statement and holds the
+// path of the module and the name the module is bound to
+type ImportStatement struct {
+	Token token.Token // the 'import' token
+	Path  *StringLiteral
+	Name  *Identifier // nil if the name is derived from the path
+}
+
+func (is *ImportStatement) statementNode() {}
+
+// TokenLiteral prints the literal value of the token associated with this node
+func (is *ImportStatement) TokenLiteral() string { return is.Token.Literal }
+
+// String returns a stringified version of the AST for debugging
+func (is *ImportStatement) String() string {
+	var out bytes.Buffer
+
+	out.WriteString(is.TokenLiteral() + " ")
+	out.WriteString(fmt.Sprintf("%q", is.Path.Value))
+	if is.Name != nil {
+		out.WriteString(" as " + is.Name.String())
+	}
+	out.WriteString(";")
+
+	return out.String()
+}
+
 // ExpressionStatement represents an expression statement and holds an
 // expression
 type ExpressionStatement struct {
@@ -313,6 +366,34 @@ func (we *WhileExpression) String() string {
 	return out.String()
 }
 
+// ForInExpression represents a `for` expression and holds the loop variable,
+// the iterable expression and the body of the loop
+type ForInExpression struct {
+	Token    token.Token // The 'for' token
+	Variable *Identifier
+	Iterable Expression
+	Body     *BlockStatement
+}
+
+func (fe *ForInExpression) expressionNode() {}
+
+// TokenLiteral prints the literal value of the token associated with this node
+func (fe *ForInExpression) TokenLiteral() string { return fe.Token.Literal }
+
+// String returns a stringified version of the AST for debugging
+func (fe *ForInExpression) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("for ")
+	out.WriteString(fe.Variable.String())
+	out.WriteString(" in ")
+	out.WriteString(fe.Iterable.String())
+	out.WriteString(" ")
+	out.WriteString(fe.Body.String())
+
+	return out.String()
+}
+
 // FunctionLiteral represents a literal functions and holds the function's
 // formal parameters and boy of the function as a block statement
 type FunctionLiteral struct {
@@ -402,6 +483,35 @@ func (al *ArrayLiteral) String() string {
 	return out.String()
 }
 
+// HashLiteral represents a hash map literal and holds the
+// key and value expressions in the same order of the source
+type HashLiteral struct {
+	Token  token.Token // the '{' token
+	Keys   []Expression
+	Values []Expression
+}
+
+func (hl *HashLiteral) expressionNode() {}
+
+// TokenLiteral prints the literal value of the token associated with this node
+func (hl *HashLiteral) TokenLiteral() string { return hl.Token.Literal }
+
+// String returns a stringified version of the AST for debugging
+func (hl *HashLiteral) String() string {
+	var out bytes.Buffer
+
+	pairs := []string{}
+	for i, key := range hl.Keys {
+		pairs = append(pairs, key.String()+":"+hl.Values[i].String())
+	}
+
+	out.WriteString("{")
+	out.WriteString(strings.Join(pairs, ", "))
+	out.WriteString("}")
+
+	return out.String()
+}
+
 // BindExpression represents a binding expression of the form:
 // x := 1
 type BindExpression struct {
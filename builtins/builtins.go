@@ -24,7 +24,12 @@ var Builtins = map[string]*object.Builtin{
 	"str":     &object.Builtin{Name: "str", Fn: core.Str},
 	"len":     &object.Builtin{Name: "len", Fn: core.Len},
 	"append":  &object.Builtin{Name: "append", Fn: core.Append},
+	"range":   &object.Builtin{Name: "range", Fn: core.Range},
 	"type":    &object.Builtin{Name: "type", Fn: core.TypeOf},
+	"keys":    &object.Builtin{Name: "keys", Fn: core.Keys},
+	"values":  &object.Builtin{Name: "values", Fn: core.Values},
+	"has":     &object.Builtin{Name: "has", Fn: core.Has},
+	"delete":  &object.Builtin{Name: "delete", Fn: core.Delete},
 
 	// Calculation
 	"abs":     &object.Builtin{Name: "abs", Fn: calc.Abs},
@@ -44,10 +49,27 @@ var Builtins = map[string]*object.Builtin{
 	"sin":     &object.Builtin{Name: "sin", Fn: calc.Sin},
 	"sqrt":    &object.Builtin{Name: "sqrt", Fn: calc.Sqrt},
 
+	// Randomness
+	"randomSeed":   &object.Builtin{Name: "randomSeed", Fn: calc.RandomSeed},
+	"randGaussian": &object.Builtin{Name: "randGaussian", Fn: calc.RandomGaussian},
+	"choice":       &object.Builtin{Name: "choice", Fn: calc.Choice},
+	"shuffle":      &object.Builtin{Name: "shuffle", Fn: calc.Shuffle},
+
+	// Noise
+	"noise":        &object.Builtin{Name: "noise", Fn: calc.Noise},
+	"noiseDetail":  &object.Builtin{Name: "noiseDetail", Fn: calc.NoiseDetail},
+	"simplexNoise": &object.Builtin{Name: "simplexNoise", Fn: calc.SimplexNoise},
+	"worleyNoise":  &object.Builtin{Name: "worleyNoise", Fn: calc.WorleyNoise},
+
 	// Graphic Context
 	"size":          &object.Builtin{Name: "size", Fn: graphics.Size},
 	"clear":         &object.Builtin{Name: "clear", Fn: graphics.Clear},
 	"dashes":        &object.Builtin{Name: "dashes", Fn: graphics.Dashes},
+	"dashOffset":    &object.Builtin{Name: "dashOffset", Fn: graphics.DashOffset},
+	"strokeCap":     &object.Builtin{Name: "strokeCap", Fn: graphics.StrokeCap},
+	"strokeJoin":    &object.Builtin{Name: "strokeJoin", Fn: graphics.StrokeJoin},
+	"miterLimit":    &object.Builtin{Name: "miterLimit", Fn: graphics.MiterLimit},
+	"fillRule":      &object.Builtin{Name: "fillRule", Fn: graphics.FillRule},
 	"strokeColor":   &object.Builtin{Name: "strokeColor", Fn: graphics.StrokeColor},
 	"fillColor":     &object.Builtin{Name: "fillColor", Fn: graphics.FillColor},
 	"strokeWeight":  &object.Builtin{Name: "strokeWeight", Fn: graphics.StrokeWeight},
@@ -62,15 +84,41 @@ var Builtins = map[string]*object.Builtin{
 	"fill":          &object.Builtin{Name: "fill", Fn: graphics.Fill},
 	"fillAndStroke": &object.Builtin{Name: "fillAndStroke", Fn: graphics.FillAndStroke},
 	"viewport":      &object.Builtin{Name: "viewport", Fn: graphics.Viewport},
+	"clip":          &object.Builtin{Name: "clip", Fn: graphics.Clip},
+	"clipPreserve":  &object.Builtin{Name: "clipPreserve", Fn: graphics.ClipPreserve},
+	"resetClip":     &object.Builtin{Name: "resetClip", Fn: graphics.ResetClip},
+
+	// Gradients
+	"linearGradient": &object.Builtin{Name: "linearGradient", Fn: graphics.LinearGradient},
+	"radialGradient": &object.Builtin{Name: "radialGradient", Fn: graphics.RadialGradient},
+	"conicGradient":  &object.Builtin{Name: "conicGradient", Fn: graphics.ConicGradient},
+
+	// Patterns
+	"fillPattern":   &object.Builtin{Name: "fillPattern", Fn: graphics.FillPattern},
+	"strokePattern": &object.Builtin{Name: "strokePattern", Fn: graphics.StrokePattern},
 
 	// Path
 	"beginPath":        &object.Builtin{Name: "beginPath", Fn: graphics.BeginPath},
 	"closePath":        &object.Builtin{Name: "closePath", Fn: graphics.ClosePath},
 	"quadraticCurveTo": &object.Builtin{Name: "quadraticCurveTo", Fn: graphics.QuadraticCurveTo},
+	"bezierCurveTo":    &object.Builtin{Name: "bezierCurveTo", Fn: graphics.BezierCurveTo},
+	"curveThrough":     &object.Builtin{Name: "curveThrough", Fn: graphics.CurveThrough},
 	"arcTo":            &object.Builtin{Name: "arcTo", Fn: graphics.ArcTo},
 	"lineTo":           &object.Builtin{Name: "lineTo", Fn: graphics.LineTo},
 	"moveTo":           &object.Builtin{Name: "moveTo", Fn: graphics.MoveTo},
 	"routeTo":          &object.Builtin{Name: "routeTo", Fn: graphics.RouteTo},
+	"path":             &object.Builtin{Name: "path", Fn: graphics.NewPath},
+	"bounds":           &object.Builtin{Name: "bounds", Fn: graphics.Bounds},
+	"contains":         &object.Builtin{Name: "contains", Fn: graphics.Contains},
+	"pathLength":       &object.Builtin{Name: "pathLength", Fn: graphics.PathLength},
+	"pointAtLength":    &object.Builtin{Name: "pointAtLength", Fn: graphics.PointAtLength},
+	"tangentAtLength":  &object.Builtin{Name: "tangentAtLength", Fn: graphics.TangentAtLength},
+
+	// Boolean operations
+	"union":      &object.Builtin{Name: "union", Fn: graphics.Union},
+	"intersect":  &object.Builtin{Name: "intersect", Fn: graphics.Intersect},
+	"difference": &object.Builtin{Name: "difference", Fn: graphics.Difference},
+	"xor":        &object.Builtin{Name: "xor", Fn: graphics.Xor},
 
 	// Transform
 	"rotate":    &object.Builtin{Name: "rotate", Fn: graphics.RotateAbout},
@@ -91,13 +139,23 @@ var Builtins = map[string]*object.Builtin{
 	"star":     &object.Builtin{Name: "star", Fn: graphics.Star},
 
 	// Text
-	"text":      &object.Builtin{Name: "text", Fn: graphics.Text},
-	"textWidth": &object.Builtin{Name: "textWidth", Fn: graphics.TextWidth},
-	"fontSize":  &object.Builtin{Name: "fontSize", Fn: graphics.FontSize},
+	"text":       &object.Builtin{Name: "text", Fn: graphics.Text},
+	"textWidth":  &object.Builtin{Name: "textWidth", Fn: graphics.TextWidth},
+	"textBox":    &object.Builtin{Name: "textBox", Fn: graphics.TextBox},
+	"textBounds": &object.Builtin{Name: "textBounds", Fn: graphics.TextBounds},
+	"textOnPath": &object.Builtin{Name: "textOnPath", Fn: graphics.TextOnPath},
+	"fontSize":   &object.Builtin{Name: "fontSize", Fn: graphics.FontSize},
+	"loadFont":   &object.Builtin{Name: "loadFont", Fn: graphics.LoadFont},
+	"textFont":   &object.Builtin{Name: "textFont", Fn: graphics.TextFont},
 
 	// Images
-	"imageGet": &object.Builtin{Name: "imageGet", Fn: graphics.LoadPNG},
+	"imageGet": &object.Builtin{Name: "imageGet", Fn: graphics.LoadImage},
 	"imageAt":  &object.Builtin{Name: "imageAt", Fn: graphics.ImageAnchored},
+
+	// Animations
+	"animationBegin": &object.Builtin{Name: "animationBegin", Fn: graphics.AnimationBegin},
+	"frame":          &object.Builtin{Name: "frame", Fn: graphics.Frame},
+	"animationEnd":   &object.Builtin{Name: "animationEnd", Fn: graphics.AnimationEnd},
 }
 
 // BuiltinsIndex ...
@@ -115,6 +173,16 @@ func init() {
 	}
 }
 
+// Method returns the method with the specified name bound to the object
+func Method(obj object.Object, name string) (*object.Builtin, bool) {
+	switch obj := obj.(type) {
+	case *object.Path:
+		return graphics.PathMethod(obj, name)
+	default:
+		return nil, false
+	}
+}
+
 func newError(format string, a ...interface{}) *object.Error {
 	return &object.Error{Message: fmt.Sprintf(format, a...)}
 }
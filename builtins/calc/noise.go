@@ -0,0 +1,75 @@
+package calc
+
+import (
+	"github.com/lucasepe/g2d/noise"
+	"github.com/lucasepe/g2d/object"
+	"github.com/lucasepe/g2d/typing"
+)
+
+// Noise returns the Perlin noise value at the specified coordinates.
+// noise(x, [y, [z]]) returns a float between 0.0 and 1.0
+func Noise(env *object.Environment, args ...object.Object) object.Object {
+	return sampleNoise("noise", env.Noise().Perlin, args)
+}
+
+// SimplexNoise returns the simplex noise value at the specified coordinates.
+// simplexNoise(x, [y, [z]]) returns a float between 0.0 and 1.0
+func SimplexNoise(env *object.Environment, args ...object.Object) object.Object {
+	return sampleNoise("simplexNoise", env.Noise().Simplex, args)
+}
+
+// WorleyNoise returns the cellular noise value at the specified coordinates.
+// worleyNoise(x, [y, [z]]) returns a float between 0.0 and 1.0
+func WorleyNoise(env *object.Environment, args ...object.Object) object.Object {
+	return sampleNoise("worleyNoise", env.Noise().Worley, args)
+}
+
+// NoiseDetail adjusts the character and level of detail of the noise.
+// noiseDetail(octaves, [falloff]) sets the number of octaves and the
+// falloff factor of each octave (by default 4 octaves and 0.5 falloff),
+// at most 16 octaves are allowed
+func NoiseDetail(env *object.Environment, args ...object.Object) object.Object {
+	if err := typing.Check("noiseDetail", args,
+		typing.RangeOfArgs(1, 2),
+		typing.WithTypes(object.INTEGER),
+	); err != nil {
+		return object.NewError(err.Error())
+	}
+
+	octaves := args[0].(*object.Integer).Value
+	if octaves < 1 || octaves > noise.MaxOctaves {
+		return object.NewError("ValueError: noiseDetail() argument #1 `octaves` must be between 1 and %d", noise.MaxOctaves)
+	}
+
+	falloff := noise.DefaultFalloff
+	if len(args) == 2 {
+		var err error
+		falloff, err = typing.ToFloat(args[1])
+		if err != nil {
+			return object.NewError("TypeError: noiseDetail() argument #2 `falloff` %s", err.Error())
+		}
+		if falloff <= 0 {
+			return object.NewError("ValueError: noiseDetail() argument #2 `falloff` must be > 0")
+		}
+	}
+
+	env.Noise().SetDetail(int(octaves), falloff)
+	return &object.Null{}
+}
+
+func sampleNoise(name string, fn func(x, y, z float64) float64, args []object.Object) object.Object {
+	if err := typing.Check(name, args, typing.RangeOfArgs(1, 3)); err != nil {
+		return object.NewError(err.Error())
+	}
+
+	xyz := [3]float64{}
+	for i, el := range args {
+		val, err := typing.ToFloat(el)
+		if err != nil {
+			return object.NewError("TypeError: %s() argument #%d %s", name, i+1, err.Error())
+		}
+		xyz[i] = val
+	}
+
+	return &object.Float{Value: fn(xyz[0], xyz[1], xyz[2])}
+}
@@ -0,0 +1,56 @@
+package calc
+
+import (
+	"testing"
+
+	"github.com/lucasepe/g2d/object"
+)
+
+func TestNoise(t *testing.T) {
+	sample := func(env *object.Environment) []string {
+		res := []string{}
+		for i := 0; i < 5; i++ {
+			x := &object.Float{Value: float64(i) * 0.25}
+			res = append(res,
+				Noise(env, x).Inspect(),
+				SimplexNoise(env, x, &object.Integer{Value: 1}).Inspect(),
+				WorleyNoise(env, x, x, x).Inspect())
+		}
+		return res
+	}
+
+	a := sample(object.NewEnvironment(nil, object.WithSeed(42)))
+
+	env := object.NewEnvironment(nil, object.WithSeed(1))
+	RandomSeed(env, &object.Integer{Value: 42})
+	b := sample(env)
+
+	for i := range a {
+		if a[i] != b[i] {
+			t.Fatalf("same seed, different values: %v %v", a, b)
+		}
+	}
+
+	errs := []object.Object{
+		Noise(env),
+		Noise(env, &object.String{Value: "x"}),
+		NoiseDetail(env, &object.Integer{Value: 0}),
+		NoiseDetail(env, &object.Integer{Value: 17}),
+		NoiseDetail(env, &object.Integer{Value: 1000000000}),
+		NoiseDetail(env, &object.Integer{Value: 2}, &object.Integer{Value: -1}),
+	}
+	for i, el := range errs {
+		if _, ok := el.(*object.Error); !ok {
+			t.Errorf("[%d] expected error got [%s]", i, el.Inspect())
+		}
+	}
+
+	if res := NoiseDetail(env, &object.Integer{Value: 16}); res.Type() == object.ERROR {
+		t.Errorf("unexpected error: %s", res.Inspect())
+	}
+
+	NoiseDetail(env, &object.Integer{Value: 1})
+	if got := Noise(env, &object.Float{Value: 0.25}).Inspect(); got == b[0] {
+		t.Errorf("noiseDetail() has no effect")
+	}
+}
@@ -1,26 +1,41 @@
 package calc
 
 import (
-	"math/rand"
-	"time"
-
 	"github.com/lucasepe/g2d/object"
 	"github.com/lucasepe/g2d/typing"
 )
 
-func init() {
-	rand.Seed(time.Now().UnixNano())
+// RandomSeed sets the seed of the random numbers generator, so that
+// the same sequence of random numbers is generated on each run.
+// randomSeed(n) restarts the generator with the seed n
+// randomSeed() returns the current seed
+func RandomSeed(env *object.Environment, args ...object.Object) object.Object {
+	if err := typing.Check("randomSeed", args,
+		typing.RangeOfArgs(0, 1),
+		typing.WithTypes(object.INTEGER),
+	); err != nil {
+		return object.NewError(err.Error())
+	}
+
+	if len(args) == 0 {
+		return &object.Integer{Value: env.Seed()}
+	}
+
+	env.SetSeed(args[0].(*object.Integer).Value)
+	return &object.Null{}
 }
 
 // RandomFloat returns a random float.
 // randf() returns a random float between 0.0 and 1.0
 // randf(max) returns a random float between 0.0 and max
 // randf(min, max) returns a random float between min and max
-func RandomFloat(_ *object.Environment, args ...object.Object) object.Object {
+func RandomFloat(env *object.Environment, args ...object.Object) object.Object {
 	if err := typing.Check("randf", args, typing.RangeOfArgs(0, 2)); err != nil {
 		return object.NewError(err.Error())
 	}
 
+	rnd := env.Rand()
+
 	if len(args) == 1 {
 		max, err := typing.ToFloat(args[0])
 		if err != nil {
@@ -29,7 +44,7 @@ func RandomFloat(_ *object.Environment, args ...object.Object) object.Object {
 		if max <= 0 {
 			return object.NewError("ValueError: randf() argument #1 must be > 0")
 		}
-		return &object.Float{Value: rand.Float64() * max}
+		return &object.Float{Value: rnd.Float64() * max}
 	}
 
 	if len(args) == 2 {
@@ -47,18 +62,18 @@ func RandomFloat(_ *object.Environment, args ...object.Object) object.Object {
 			return object.NewError("ValueError: randf() argument #1 `min` must be > argument #2 `max`")
 		}
 		return &object.Float{
-			Value: min + rand.Float64()*(max-min),
+			Value: min + rnd.Float64()*(max-min),
 		}
 	}
 
-	return &object.Float{Value: rand.Float64()}
+	return &object.Float{Value: rnd.Float64()}
 }
 
 // RandomInt returns a ramdom int
 // randi() returns a random integer
 // randi(max) returns a random integer between 0 and max
 // randi(min, max) returns a random integer between min and max
-func RandomInt(_ *object.Environment, args ...object.Object) object.Object {
+func RandomInt(env *object.Environment, args ...object.Object) object.Object {
 	if err := typing.Check("randi", args,
 		typing.RangeOfArgs(0, 2),
 		typing.WithTypes(object.INTEGER, object.INTEGER),
@@ -66,12 +81,14 @@ func RandomInt(_ *object.Environment, args ...object.Object) object.Object {
 		return object.NewError(err.Error())
 	}
 
+	rnd := env.Rand()
+
 	if len(args) == 1 {
 		max := args[0].(*object.Integer).Value
 		if max <= 0 {
 			return object.NewError("ValueError: randi() argument #1 must be > 0")
 		}
-		return &object.Integer{Value: rand.Int63n(max + 1)}
+		return &object.Integer{Value: rnd.Int63n(max + 1)}
 	}
 
 	if len(args) == 2 {
@@ -80,8 +97,123 @@ func RandomInt(_ *object.Environment, args ...object.Object) object.Object {
 		if min > max {
 			return object.NewError("ValueError: randi() argument #1 `min` must be > argument #2 `max`")
 		}
-		return &object.Integer{Value: rand.Int63n(max-min+1) + min}
+		return &object.Integer{Value: rnd.Int63n(max-min+1) + min}
+	}
+
+	return &object.Integer{Value: rnd.Int63()}
+}
+
+// RandomGaussian returns a random float from a normal distribution
+// randGaussian() with mean 0.0 and standard deviation 1.0
+// randGaussian(mean) with the specified mean and standard deviation 1.0
+// randGaussian(mean, sd) with the specified mean and standard deviation
+func RandomGaussian(env *object.Environment, args ...object.Object) object.Object {
+	if err := typing.Check("randGaussian", args, typing.RangeOfArgs(0, 2)); err != nil {
+		return object.NewError(err.Error())
+	}
+
+	mean, sd := 0.0, 1.0
+
+	if len(args) > 0 {
+		val, err := typing.ToFloat(args[0])
+		if err != nil {
+			return object.NewError("TypeError: randGaussian() argument #1 `mean` %s", err.Error())
+		}
+		mean = val
+	}
+
+	if len(args) > 1 {
+		val, err := typing.ToFloat(args[1])
+		if err != nil {
+			return object.NewError("TypeError: randGaussian() argument #2 `sd` %s", err.Error())
+		}
+		if val < 0 {
+			return object.NewError("ValueError: randGaussian() argument #2 `sd` must be >= 0")
+		}
+		sd = val
+	}
+
+	return &object.Float{Value: mean + env.Rand().NormFloat64()*sd}
+}
+
+// Choice returns a random element of an array
+// choice(array) all the elements have the same probability
+// choice(array, weights) the probability of each element is
+// proportional to its weight (a non negative number)
+func Choice(env *object.Environment, args ...object.Object) object.Object {
+	if err := typing.Check("choice", args,
+		typing.RangeOfArgs(1, 2),
+		typing.WithTypes(object.ARRAY, object.ARRAY),
+	); err != nil {
+		return object.NewError(err.Error())
+	}
+
+	elements := args[0].(*object.Array).Elements
+	if len(elements) == 0 {
+		return object.NewError("ValueError: choice() argument #1 must not be empty")
+	}
+
+	if len(args) == 1 {
+		return elements[env.Rand().Intn(len(elements))]
+	}
+
+	weights, err := typing.ToFloatArray(args[1])
+	if err != nil {
+		return object.NewError("TypeError: choice() argument #2 `weights` %s", err.Error())
+	}
+
+	if len(weights) != len(elements) {
+		return object.NewError("ValueError: choice() argument #2 `weights` must have %d elements, got %d",
+			len(elements), len(weights))
+	}
+
+	total := 0.0
+	for _, w := range weights {
+		if w < 0 {
+			return object.NewError("ValueError: choice() argument #2 `weights` must be >= 0")
+		}
+		total = total + w
+	}
+
+	if total <= 0 {
+		return object.NewError("ValueError: choice() argument #2 `weights` must not be all zeros")
 	}
 
-	return &object.Integer{Value: rand.Int63()}
+	x := env.Rand().Float64() * total
+	for i, w := range weights {
+		if x < w {
+			return elements[i]
+		}
+		x = x - w
+	}
+
+	// rounding errors, the last element with a weight
+	for i := len(weights) - 1; i >= 0; i-- {
+		if weights[i] > 0 {
+			return elements[i]
+		}
+	}
+	return elements[len(elements)-1]
+}
+
+// Shuffle returns a new array with the elements of the array in random order
+// shuffle(array)
+func Shuffle(env *object.Environment, args ...object.Object) object.Object {
+	if err := typing.Check("shuffle", args,
+		typing.ExactArgs(1),
+		typing.WithTypes(object.ARRAY),
+	); err != nil {
+		return object.NewError(err.Error())
+	}
+
+	elements := args[0].(*object.Array).Elements
+
+	res := make([]object.Object, len(elements))
+	copy(res, elements)
+
+	env.Rand().Shuffle(len(res), func(i, j int) {
+		res[i], res[j] = res[j], res[i]
+	})
+
+	return &object.Array{Elements: res}
 }
@@ -0,0 +1,97 @@
+package calc
+
+import (
+	"testing"
+
+	"github.com/lucasepe/g2d/object"
+)
+
+func TestRandomSeed(t *testing.T) {
+	sequence := func(seed int64) []string {
+		env := object.NewEnvironment(nil, object.WithSeed(seed))
+
+		res := []string{}
+		for i := 0; i < 5; i++ {
+			res = append(res,
+				RandomFloat(env).Inspect(),
+				RandomInt(env, &object.Integer{Value: 100}).Inspect(),
+				RandomGaussian(env).Inspect())
+		}
+		return res
+	}
+
+	a, b := sequence(42), sequence(42)
+	for i := range a {
+		if a[i] != b[i] {
+			t.Fatalf("same seed, different sequences: %v %v", a, b)
+		}
+	}
+
+	env := object.NewEnvironment(nil, object.WithSeed(1))
+	RandomSeed(env, &object.Integer{Value: 42})
+	if got := RandomSeed(env).(*object.Integer).Value; got != 42 {
+		t.Errorf("got seed [%d] want [42]", got)
+	}
+	if got := RandomFloat(env).Inspect(); got != a[0] {
+		t.Errorf("got [%s] want [%s]", got, a[0])
+	}
+}
+
+func TestChoice(t *testing.T) {
+	env := object.NewEnvironment(nil, object.WithSeed(7))
+
+	items := &object.Array{Elements: []object.Object{
+		&object.String{Value: "a"},
+		&object.String{Value: "b"},
+		&object.String{Value: "c"},
+	}}
+	weights := &object.Array{Elements: []object.Object{
+		&object.Integer{Value: 0},
+		&object.Float{Value: 1.5},
+		&object.Integer{Value: 0},
+	}}
+
+	for i := 0; i < 20; i++ {
+		if got := Choice(env, items, weights).Inspect(); got != "b" {
+			t.Fatalf("got [%s] want [b]", got)
+		}
+	}
+
+	errs := []object.Object{
+		Choice(env, &object.Array{}),
+		Choice(env, items, &object.Array{Elements: []object.Object{&object.Integer{Value: 1}}}),
+		Choice(env, items, &object.Array{Elements: []object.Object{
+			&object.Integer{Value: 0}, &object.Integer{Value: 0}, &object.Integer{Value: 0},
+		}}),
+	}
+	for _, el := range errs {
+		if el.Type() != object.ERROR {
+			t.Errorf("expected error got [%v]", el)
+		}
+	}
+}
+
+func TestShuffle(t *testing.T) {
+	env := object.NewEnvironment(nil, object.WithSeed(3))
+
+	items := &object.Array{}
+	for i := 0; i < 10; i++ {
+		items.Elements = append(items.Elements, &object.Integer{Value: int64(i)})
+	}
+
+	res := Shuffle(env, items).(*object.Array)
+	if len(res.Elements) != len(items.Elements) {
+		t.Fatalf("got %d elements want %d", len(res.Elements), len(items.Elements))
+	}
+
+	sum := int64(0)
+	for i, el := range res.Elements {
+		sum = sum + el.(*object.Integer).Value
+		if items.Elements[i].(*object.Integer).Value != int64(i) {
+			t.Fatalf("shuffle() changed the original array")
+		}
+	}
+	if sum != 45 {
+		t.Errorf("got sum [%d] want [45]", sum)
+	}
+}
@@ -0,0 +1,59 @@
+package core
+
+import (
+	"github.com/lucasepe/g2d/object"
+	"github.com/lucasepe/g2d/typing"
+)
+
+// Keys keys(hash) Returns an array with the keys of the hash (in insertion order).
+func Keys(_ *object.Environment, args ...object.Object) object.Object {
+	if err := typing.Check("keys", args,
+		typing.ExactArgs(1),
+		typing.WithTypes(object.HASH),
+	); err != nil {
+		return object.NewError(err.Error())
+	}
+
+	hash := args[0].(*object.Hash)
+	return &object.Array{Elements: hash.Keys()}
+}
+
+// Values values(hash) Returns an array with the values of the hash (in insertion order).
+func Values(_ *object.Environment, args ...object.Object) object.Object {
+	if err := typing.Check("values", args,
+		typing.ExactArgs(1),
+		typing.WithTypes(object.HASH),
+	); err != nil {
+		return object.NewError(err.Error())
+	}
+
+	hash := args[0].(*object.Hash)
+	return &object.Array{Elements: hash.Values()}
+}
+
+// Has has(hash, key) Returns true if the hash contains the key.
+func Has(_ *object.Environment, args ...object.Object) object.Object {
+	if err := typing.Check("has", args,
+		typing.ExactArgs(2),
+		typing.WithTypes(object.HASH),
+	); err != nil {
+		return object.NewError(err.Error())
+	}
+
+	hash := args[0].(*object.Hash)
+	return &object.Boolean{Value: hash.Has(args[1])}
+}
+
+// Delete delete(hash, key) Removes the key from the hash,
+// returns true if the key was present.
+func Delete(_ *object.Environment, args ...object.Object) object.Object {
+	if err := typing.Check("delete", args,
+		typing.ExactArgs(2),
+		typing.WithTypes(object.HASH),
+	); err != nil {
+		return object.NewError(err.Error())
+	}
+
+	hash := args[0].(*object.Hash)
+	return &object.Boolean{Value: hash.Delete(args[1])}
+}
@@ -0,0 +1,66 @@
+package core
+
+import (
+	"math"
+
+	"github.com/lucasepe/g2d/object"
+	"github.com/lucasepe/g2d/typing"
+)
+
+// maxRange is the max number of elements of a range
+const maxRange = 10000000
+
+// Range returns an array with a progression of numbers.
+// `range(stop)` from 0 up to stop (excluded).
+// `range(start, stop, [step])` from start up to stop (excluded), by step (1 by default).
+// The numbers are floats if any of the arguments is a float.
+func Range(_ *object.Environment, args ...object.Object) object.Object {
+	if err := typing.Check("range", args, typing.RangeOfArgs(1, 3)); err != nil {
+		return object.NewError(err.Error())
+	}
+
+	vals := make([]float64, len(args))
+	floats := false
+	for i, el := range args {
+		val, err := typing.ToFloat(el)
+		if err != nil {
+			return object.NewError("TypeError: range() argument #%d %s", i+1, err.Error())
+		}
+		vals[i] = val
+		floats = floats || el.Type() == object.FLOAT
+	}
+
+	start, stop, step := 0.0, vals[0], 1.0
+	if len(vals) > 1 {
+		start, stop = vals[0], vals[1]
+	}
+	if len(vals) > 2 {
+		step = vals[2]
+	}
+
+	if step == 0 {
+		return object.NewError("ValueError: range() argument #3 `step` must not be zero")
+	}
+
+	// the array is built eagerly: reject the ranges too big to fit in memory
+	if n := math.Ceil((stop - start) / step); !(n <= maxRange) {
+		return object.NewError("ValueError: range() too many elements, at most %d are allowed", maxRange)
+	}
+
+	res := &object.Array{}
+	for i := 0; ; i++ {
+		// multiply instead of accumulate to avoid rounding errors with floats
+		val := start + float64(i)*step
+		if (step > 0 && val >= stop) || (step < 0 && val <= stop) {
+			break
+		}
+
+		if floats {
+			res.Append(&object.Float{Value: val})
+		} else {
+			res.Append(&object.Integer{Value: int64(val)})
+		}
+	}
+
+	return res
+}
@@ -0,0 +1,101 @@
+package graphics
+
+import (
+	"image"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/lucasepe/g2d/gg/anim"
+	"github.com/lucasepe/g2d/object"
+	"github.com/lucasepe/g2d/typing"
+)
+
+// imager is implemented by the raster graphic contexts
+type imager interface {
+	Image() image.Image
+}
+
+// AnimationBegin starts collecting the frames of an animation.
+// `animationBegin(fps)` - fps is the number of frames per second.
+func AnimationBegin(env *object.Environment, args ...object.Object) object.Object {
+	if err := typing.Check("animationBegin", args, typing.ExactArgs(1)); err != nil {
+		return object.NewError(err.Error())
+	}
+
+	fps, err := typing.ToFloat(args[0])
+	if err != nil {
+		return object.NewError("TypeError: animationBegin() argument #1 `fps` %s", err.Error())
+	}
+
+	if fps <= 0 {
+		return object.NewError("animationBegin() argument #1 `fps` must be greater than zero")
+	}
+
+	env.SetAnimation(anim.NewAnimation(fps))
+	return &object.Null{}
+}
+
+// Frame adds the current drawings as a new frame of the animation.
+func Frame(env *object.Environment, args ...object.Object) object.Object {
+	if err := typing.Check("frame", args, typing.ExactArgs(0)); err != nil {
+		return object.NewError(err.Error())
+	}
+
+	a := env.Animation()
+	if a == nil {
+		return object.NewError("frame() no animation in progress, call animationBegin() first")
+	}
+
+	ctx, ok := env.GraphicContext().(imager)
+	if !ok {
+		return object.NewError("frame() expected a raster graphic context, got: %v",
+			reflect.TypeOf(env.GraphicContext()))
+	}
+
+	if err := a.AddFrame(ctx.Image()); err != nil {
+		return object.NewError("frame() %s", err.Error())
+	}
+
+	return &object.Null{}
+}
+
+// AnimationEnd encodes the animation frames and saves them on the filesystem.
+// The format is chosen by the filename extension: .gif for an animated GIF,
+// .png (or .apng) for an animated PNG.
+// If file name is omitted it will be autogenerated and the animation saved as GIF.
+func AnimationEnd(env *object.Environment, args ...object.Object) object.Object {
+	if err := typing.Check("animationEnd", args,
+		typing.RangeOfArgs(0, 1),
+		typing.WithTypes(object.STRING),
+	); err != nil {
+		return object.NewError(err.Error())
+	}
+
+	a := env.Animation()
+	if a == nil {
+		return object.NewError("animationEnd() no animation in progress, call animationBegin() first")
+	}
+	env.SetAnimation(nil)
+
+	filename := env.DocumentFilename(".gif")
+	if len(args) == 1 {
+		filename = args[0].(*object.String).Value
+	}
+
+	format := strings.ToLower(strings.TrimPrefix(filepath.Ext(filename), "."))
+	if !containsString(a.Formats(), format) {
+		return object.NewError("animationEnd() unsupported format '%s', expected one of: %s",
+			format, strings.Join(a.Formats(), ", "))
+	}
+
+	if folder := env.SnapshotFolder(); folder != "" {
+		filename = filepath.Join(folder, filename)
+	}
+
+	if err := saveEncoded(filename, a, format); err != nil {
+		return object.NewError(err.Error())
+	}
+
+	return &object.Null{}
+}
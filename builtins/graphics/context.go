@@ -1,11 +1,13 @@
 package graphics
 
 import (
-	"image"
+	"fmt"
+	"os"
 	"path/filepath"
 	"reflect"
+	"strings"
 
-	"github.com/lucasepe/g2d/gg/img"
+	"github.com/lucasepe/g2d/gg"
 	"github.com/lucasepe/g2d/object"
 	"github.com/lucasepe/g2d/typing"
 )
@@ -30,7 +32,7 @@ func Size(env *object.Environment, args ...object.Object) object.Object {
 		}
 	}
 
-	ctx := img.NewContextForRGBA(image.NewRGBA(image.Rect(0, 0, w, h)))
+	ctx := newGraphicContextLike(env.GraphicContext(), w, h)
 	env.SetGraphicContext(ctx)
 	return &object.Null{}
 }
@@ -47,6 +49,7 @@ func Clear(env *object.Environment, args ...object.Object) object.Object {
 
 // StrokeColor returns or sets the stroke color.
 // strokeColor(hexcolor) - sets the stroke color to `hexcolor`.
+// strokeColor(pattern) - strokes using the pattern (i.e. a gradient).
 // strokeColor(r, g, b) - sets the stroke color to `r,g,b` values.
 // strokeColor(r, g, b, a) -  sets the stroke color to `r,g,b,a` values.
 func StrokeColor(env *object.Environment, args ...object.Object) object.Object {
@@ -61,7 +64,12 @@ func StrokeColor(env *object.Environment, args ...object.Object) object.Object {
 			return &object.Null{}
 		}
 
-		return object.NewError("TypeError: strokeColor() argument #1 expected to be `string` got `%s`", args[0].Type())
+		if args[0].Type() == object.PATTERN {
+			env.GraphicContext().SetStrokeStyle(args[0].(*object.Pattern).Value)
+			return &object.Null{}
+		}
+
+		return object.NewError("TypeError: strokeColor() argument #1 expected to be `string` or `pattern` got `%s`", args[0].Type())
 	}
 
 	if err := typing.Check("strokeColor", args, typing.RangeOfArgs(3, 4)); err != nil {
@@ -94,6 +102,7 @@ func StrokeColor(env *object.Environment, args ...object.Object) object.Object {
 
 // FillColor returns or sets the fill color.
 // fillColor(hexcolor) - sets the fill color to `hexcolor`.
+// fillColor(pattern) - fills using the pattern (i.e. a gradient).
 // fillColor(r, g, b) - sets the fill color to `r,g,b` values.
 // fillColor(r, g, b, a) -  sets the fill color to `r,g,b,a` values.
 func FillColor(env *object.Environment, args ...object.Object) object.Object {
@@ -108,7 +117,12 @@ func FillColor(env *object.Environment, args ...object.Object) object.Object {
 			return &object.Null{}
 		}
 
-		return object.NewError("TypeError: fillColor() argument #1 expected to be `string` got `%s`", args[0].Type())
+		if args[0].Type() == object.PATTERN {
+			env.GraphicContext().SetFillStyle(args[0].(*object.Pattern).Value)
+			return &object.Null{}
+		}
+
+		return object.NewError("TypeError: fillColor() argument #1 expected to be `string` or `pattern` got `%s`", args[0].Type())
 	}
 
 	if err := typing.Check("fillColor", args, typing.RangeOfArgs(3, 4)); err != nil {
@@ -195,6 +209,120 @@ func Dashes(env *object.Environment, args ...object.Object) object.Object {
 	return &object.Null{}
 }
 
+// DashOffset sets the initial offset into the dash pattern
+// to use when stroking dashed paths.
+// dashOffset(offset) - sets the offset to `offset`.
+func DashOffset(env *object.Environment, args ...object.Object) object.Object {
+	if err := typing.Check("dashOffset", args, typing.ExactArgs(1)); err != nil {
+		return object.NewError(err.Error())
+	}
+
+	offset, err := typing.ToFloat(args[0])
+	if err != nil {
+		return object.NewError("TypeError: dashOffset() argument #1 %s", err.Error())
+	}
+
+	env.GraphicContext().SetLineDashOffset(offset)
+	return &object.Null{}
+}
+
+// StrokeCap sets the style of the ends of the stroked lines.
+// strokeCap(cap) - cap is one of "round" (the default), "butt" or "square".
+func StrokeCap(env *object.Environment, args ...object.Object) object.Object {
+	if err := typing.Check("strokeCap", args,
+		typing.ExactArgs(1),
+		typing.WithTypes(object.STRING),
+	); err != nil {
+		return object.NewError(err.Error())
+	}
+
+	val, ok := lineCaps[args[0].(*object.String).Value]
+	if !ok {
+		return object.NewError("ValueError: strokeCap() argument #1 must be `round`, `butt` or `square`")
+	}
+
+	env.GraphicContext().SetLineCap(val)
+	return &object.Null{}
+}
+
+// StrokeJoin sets the style of the corners where two stroked lines meet.
+// strokeJoin(join) - join is one of "round" (the default), "bevel" or "miter".
+// strokeJoin("miter", limit) - also sets the miter limit (see miterLimit).
+func StrokeJoin(env *object.Environment, args ...object.Object) object.Object {
+	if err := typing.Check("strokeJoin", args,
+		typing.RangeOfArgs(1, 2),
+		typing.WithTypes(object.STRING),
+	); err != nil {
+		return object.NewError(err.Error())
+	}
+
+	val, ok := lineJoins[args[0].(*object.String).Value]
+	if !ok {
+		return object.NewError("ValueError: strokeJoin() argument #1 must be `round`, `bevel` or `miter`")
+	}
+
+	if len(args) == 2 {
+		if res := MiterLimit(env, args[1]); res.Type() == object.ERROR {
+			return object.NewError(strings.Replace(res.(*object.Error).Message,
+				"miterLimit() argument #1", "strokeJoin() argument #2", 1))
+		}
+	}
+
+	env.GraphicContext().SetLineJoin(val)
+	return &object.Null{}
+}
+
+// MiterLimit sets the limit of the ratio of the miter length to the stroke
+// weight, beyond which the miter joins are drawn as bevel joins.
+// miterLimit(limit) - sets the limit to `limit` (by default 10).
+func MiterLimit(env *object.Environment, args ...object.Object) object.Object {
+	if err := typing.Check("miterLimit", args, typing.ExactArgs(1)); err != nil {
+		return object.NewError(err.Error())
+	}
+
+	limit, err := typing.ToFloat(args[0])
+	if err != nil {
+		return object.NewError("TypeError: miterLimit() argument #1 %s", err.Error())
+	}
+	if limit < 1 {
+		return object.NewError("ValueError: miterLimit() argument #1 must be >= 1")
+	}
+
+	env.GraphicContext().SetMiterLimit(limit)
+	return &object.Null{}
+}
+
+// FillRule sets the rule used to tell the inside of the paths when filling.
+// fillRule(rule) - rule is "nonzero" (the default) or "evenodd".
+func FillRule(env *object.Environment, args ...object.Object) object.Object {
+	if err := typing.Check("fillRule", args,
+		typing.ExactArgs(1),
+		typing.WithTypes(object.STRING),
+	); err != nil {
+		return object.NewError(err.Error())
+	}
+
+	val, ok := fillRules[args[0].(*object.String).Value]
+	if !ok {
+		return object.NewError("ValueError: fillRule() argument #1 must be `nonzero` or `evenodd`")
+	}
+
+	env.GraphicContext().SetFillRule(val)
+	return &object.Null{}
+}
+
+var lineCaps = map[string]gg.LineCap{
+	"round":  gg.LineCapRound,
+	"butt":   gg.LineCapButt,
+	"square": gg.LineCapSquare,
+}
+
+var lineJoins = map[string]gg.LineJoin{
+	"round": gg.LineJoinRound,
+	"bevel": gg.LineJoinBevel,
+	"miter": gg.LineJoinMiter,
+}
+
 // GetCurrentX returns the current X position if there is a current point.
 func GetCurrentX(env *object.Environment, args ...object.Object) object.Object {
 	if x, _, ok := env.GraphicContext().CurrentPoint(); ok {
@@ -264,8 +392,14 @@ func Pop(env *object.Environment, args ...object.Object) object.Object {
 	return &object.Null{}
 }
 
-// Snapshot encodes as PNG the image with the current drawings and saves the image on the filesystem.
-// If file name is omitted it will be autogenerated adn the PNG saved in the .g2d file folder.
+// Snapshot encodes the current drawings and saves them on the filesystem.
+// The format is chosen by the filename extension among the ones supported by the graphic
+// context: .png and .jpg with the img driver, .svg with the svg driver, .pdf with the pdf driver.
+// Drawings are not converted between raster and vector formats: the extension of a format
+// of another driver is replaced with the default one of the current driver (i.e. "out.png"
+// is saved as "out.svg" by the svg driver), so that scripts work with any driver.
+// Using multi-page documents (i.e. PDF), each snapshot adds a new page to the same document.
+// If file name is omitted it will be autogenerated adn the file saved in the .g2d file folder.
 func Snapshot(env *object.Environment, args ...object.Object) object.Object {
 	if err := typing.Check("snapshot", args,
 		typing.RangeOfArgs(0, 1),
@@ -274,16 +408,44 @@ func Snapshot(env *object.Environment, args ...object.Object) object.Object {
 		return object.NewError(err.Error())
 	}
 
-	ctx, ok := env.GraphicContext().(*img.Context)
+	enc, ok := env.GraphicContext().(gg.Encoder)
 	if !ok {
-		return object.NewError(
-			"expected graphic context of type img.Context, got: %v (not impl yet)",
+		return object.NewError("snapshot() graphic context of type %v can't be encoded",
 			reflect.TypeOf(env.GraphicContext()))
 	}
+	formats := enc.Formats()
 
-	filename := env.SnapshotFilename()
+	_, paginated := enc.(gg.Paginator)
+
+	var filename string
 	if len(args) == 1 {
 		filename = args[0].(*object.String).Value
+	} else if paginated {
+		// all the snapshots are pages of the same document
+		filename = env.DocumentFilename("." + formats[0])
+	} else {
+		filename = env.SnapshotFilename("." + formats[0])
+	}
+
+	format := strings.ToLower(strings.TrimPrefix(filepath.Ext(filename), "."))
+	if format == "" {
+		format = formats[0]
+		filename = filename + "." + format
+	}
+
+	if !containsString(formats, format) {
+		driver, ok := formatDrivers[format]
+		if !ok {
+			return object.NewError("snapshot() unsupported format '%s', expected one of: %s",
+				format, strings.Join(formats, ", "))
+		}
+
+		// the format of another driver: the script works unchanged
+		// saving the drawings in the default format of this one
+		name := strings.TrimSuffix(filename, filepath.Ext(filename)) + "." + formats[0]
+		fmt.Fprintf(os.Stderr, "Warning snapshot() can't save '%s' with the %s driver, saved '%s' instead (use --driver %s to save .%s files)\n",
+			filename, formatDrivers[formats[0]], name, driver, format)
+		filename, format = name, formats[0]
 	}
 
 	if folder := env.SnapshotFolder(); folder != "" {
@@ -295,33 +457,128 @@ func Snapshot(env *object.Environment, args ...object.Object) object.Object {
 		filename = filepath.Join(folder, filename)
 	}
 
-	if err := savePNG(filename, ctx.Image()); err != nil {
+	if paginated {
+		enc.(gg.Paginator).ShowPage()
+	}
+
+	if err := saveEncoded(filename, enc, format); err != nil {
 		return object.NewError(err.Error())
 	}
 
 	return &object.Null{}
 }
 
+// formatDrivers is the driver able to save each format
+var formatDrivers = map[string]string{
+	"png":  "img",
+	"jpg":  "img",
+	"jpeg": "img",
+	"svg":  "svg",
+	"pdf":  "pdf",
+}
+
 // Stroke strokes the current path with the current color and line width
 // the path is cleared after this operation.
+// stroke(p) - replaces the current path with the path `p` and strokes it.
 func Stroke(env *object.Environment, args ...object.Object) object.Object {
+	usePath(env.GraphicContext(), args)
 	env.GraphicContext().Stroke()
 	return &object.Null{}
 }
 
 // Fill fills the current path with the current color.
 // Open subpaths are implicity closed. The path is cleared after this operation.
+// fill(p) - replaces the current path with the path `p` and fills it.
 func Fill(env *object.Environment, args ...object.Object) object.Object {
+	usePath(env.GraphicContext(), args)
 	env.GraphicContext().Fill()
 	return &object.Null{}
 }
 
 // FillAndStroke first fills the current path and than strokes it
+// fillAndStroke(p) - replaces the current path with the path `p`.
 func FillAndStroke(env *object.Environment, args ...object.Object) object.Object {
+	usePath(env.GraphicContext(), args)
 	env.GraphicContext().FillAndStroke()
 	return &object.Null{}
 }
 
+// usePath replaces the current path with the path argument, if any;
+// other arguments are ignored, as older scripts may pass them
+func usePath(dc gg.GraphicContext, args []object.Object) {
+	if len(args) == 0 {
+		return
+	}
+
+	if p, ok := args[0].(*object.Path); ok {
+		dc.BeginPath()
+		dc.AppendPath(p.Value)
+	}
+}
+
+// Clip intersects the clipping region with the current path; the drawings
+// outside the clipping region are discarded. The path is cleared after this
+// operation.
+// clip([rule]) - rule is the fill rule used to tell the inside of the path:
+// "nonzero" (the default) or "evenodd".
+func Clip(env *object.Environment, args ...object.Object) object.Object {
+	return clip("clip", env, args, false)
+}
+
+// ClipPreserve intersects the clipping region with the current path.
+// The path is preserved after this operation.
+// clipPreserve([rule]) - see clip.
+func ClipPreserve(env *object.Environment, args ...object.Object) object.Object {
+	return clip("clipPreserve", env, args, true)
+}
+
+// ResetClip clears the clipping region.
+func ResetClip(env *object.Environment, args ...object.Object) object.Object {
+	if err := typing.Check("resetClip", args, typing.ExactArgs(0)); err != nil {
+		return object.NewError(err.Error())
+	}
+
+	env.GraphicContext().ResetClip()
+	return &object.Null{}
+}
+
+func clip(name string, env *object.Environment, args []object.Object, preserve bool) object.Object {
+	if err := typing.Check(name, args,
+		typing.RangeOfArgs(0, 1),
+		typing.WithTypes(object.STRING),
+	); err != nil {
+		return object.NewError(err.Error())
+	}
+
+	dc := env.GraphicContext()
+
+	prev := dc.FillRule()
+
+	rule := prev
+	if len(args) == 1 {
+		val, ok := fillRules[args[0].(*object.String).Value]
+		if !ok {
+			return object.NewError("ValueError: %s() argument #1 must be `nonzero` or `evenodd`", name)
+		}
+		rule = val
+	}
+
+	dc.SetFillRule(rule)
+	if preserve {
+		dc.ClipPreserve()
+	} else {
+		dc.Clip()
+	}
+	dc.SetFillRule(prev)
+
+	return &object.Null{}
+}
+
+var fillRules = map[string]gg.FillRule{
+	"nonzero": gg.FillRuleWinding,
+	"evenodd": gg.FillRuleEvenOdd,
+}
+
 // Viewport sets up user-defined coordinate system.
 // This performs a screen reset, all drawings are cleared.
 func Viewport(env *object.Environment, args ...object.Object) object.Object {
@@ -3,19 +3,23 @@ package graphics
 import (
 	"fmt"
 	"image"
-	"image/png"
 	"math"
 	"os"
 	"strings"
 
 	"github.com/lucasepe/g2d/gg"
+	"github.com/lucasepe/g2d/gg/img"
+	"github.com/lucasepe/g2d/gg/pdf"
+	"github.com/lucasepe/g2d/gg/svg"
+	"github.com/lucasepe/g2d/object"
+	"github.com/lucasepe/g2d/typing"
 )
 
 func radians(degrees float64) float64 {
 	return degrees * math.Pi / 180.0
 }
 
-func drawArc(dc gg.GraphicContext, x, y, r, angle1, angle2 float64) {
+func drawArc(dc gg.PathBuilder, x, y, r, angle1, angle2 float64) {
 	dc.DrawEllipticalArc(x, y, r, r, angle1, angle2)
 }
 
@@ -222,11 +226,48 @@ func parseHexColor(x string) (r, g, b, a int) {
 	return
 }
 
-func savePNG(path string, im image.Image) error {
+// newGraphicContextLike creates a new graphic context of the specified size
+// using the same backend of the given one (raster images by default).
+func newGraphicContextLike(dc gg.GraphicContext, w, h int) gg.GraphicContext {
+	switch ctx := dc.(type) {
+	case *svg.Context:
+		return svg.NewContext(w, h)
+	case *pdf.Context:
+		// the document is the same, only the page size changes
+		ctx.SetPageSize(w, h)
+		return ctx
+	default:
+		return img.NewContextForRGBA(image.NewRGBA(image.Rect(0, 0, w, h)))
+	}
+}
+
+func saveEncoded(path string, enc gg.Encoder, format string) error {
 	file, err := os.Create(path)
 	if err != nil {
 		return err
 	}
 	defer file.Close()
-	return png.Encode(file, im)
+	return enc.Encode(file, format)
+}
+
+// floatArgs converts all the arguments to floats
+func floatArgs(name string, args []object.Object) ([]float64, error) {
+	res := make([]float64, len(args))
+	for i, el := range args {
+		val, err := typing.ToFloat(el)
+		if err != nil {
+			return nil, fmt.Errorf("TypeError: %s() argument #%d %s", name, i+1, err.Error())
+		}
+		res[i] = val
+	}
+	return res, nil
+}
+
+func containsString(list []string, s string) bool {
+	for _, el := range list {
+		if el == s {
+			return true
+		}
+	}
+	return false
 }
@@ -1,16 +1,31 @@
 package graphics
 
 import (
-	"image/png"
-	"os"
+	"bytes"
+	"image"
 
+	// image decoders
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+
+	_ "golang.org/x/image/bmp"
+	_ "golang.org/x/image/tiff"
+	_ "golang.org/x/image/webp"
+
+	"github.com/lucasepe/g2d/data"
 	"github.com/lucasepe/g2d/object"
 	"github.com/lucasepe/g2d/typing"
 )
 
-// LoadPNG loads a PNG image file
-func LoadPNG(env *object.Environment, args ...object.Object) object.Object {
-	if err := typing.Check("loadPNG", args,
+// max size (in bytes) of a loaded image
+const imageLimit = 64 * 1024 * 1024
+
+// LoadImage loads a PNG, JPEG, GIF, BMP, TIFF or WebP image.
+// The format is detected from the content; the path is
+// relative to the script and can also be an http URL.
+func LoadImage(env *object.Environment, args ...object.Object) object.Object {
+	if err := typing.Check("imageGet", args,
 		typing.ExactArgs(1), typing.WithTypes(object.STRING),
 	); err != nil {
 		return object.NewError(err.Error())
@@ -18,18 +33,24 @@ func LoadPNG(env *object.Environment, args ...object.Object) object.Object {
 
 	name, err := typing.ToString(args[0])
 	if err != nil {
-		return object.NewError("TypeError: loadPNG() argument #1 %s", err.Error())
+		return object.NewError("TypeError: imageGet() argument #1 %s", err.Error())
 	}
 
-	fd, err := os.Open(name)
+	uri, err := data.Resolve(env.ScriptPath(), name)
+	if err != nil {
+		return object.NewError("IOError: imageGet() - %s", err.Error())
+	}
+
+	env.AddDependency(uri)
+
+	buf, err := data.Fetch(uri, imageLimit)
 	if err != nil {
-		return object.NewError("TypeError: loadPNG() - %s", err.Error())
+		return object.NewError("IOError: imageGet() - %s", err.Error())
 	}
-	defer fd.Close()
 
-	im, err := png.Decode(fd)
+	im, _, err := image.Decode(bytes.NewReader(buf))
 	if err != nil {
-		return object.NewError("DecodeError: loadPNG() - %s", err.Error())
+		return object.NewError("DecodeError: imageGet() - %s", err.Error())
 	}
 
 	return &object.Image{Value: im}
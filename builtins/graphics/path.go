@@ -1,8 +1,10 @@
 package graphics
 
 import (
+	"fmt"
 	"math"
 
+	"github.com/lucasepe/g2d/gg"
 	"github.com/lucasepe/g2d/object"
 	"github.com/lucasepe/g2d/typing"
 )
@@ -23,6 +25,10 @@ func ClosePath(env *object.Environment, args ...object.Object) object.Object {
 // RouteTo adds a line segment to the current path starting at the current point.
 // If there is no current point, it is equivalent to MoveTo(x, y)
 func RouteTo(env *object.Environment, args ...object.Object) object.Object {
+	return routeTo(env.GraphicContext(), args)
+}
+
+func routeTo(pb gg.PathBuilder, args []object.Object) object.Object {
 	if err := typing.Check("routeTo", args, typing.ExactArgs(2)); err != nil {
 		return object.NewError(err.Error())
 	}
@@ -40,11 +46,10 @@ func RouteTo(env *object.Environment, args ...object.Object) object.Object {
 	// displacements in x and y directions
 	dx, dy := d*math.Cos(a), d*math.Sin(a)
 
-	dc := env.GraphicContext()
-	if x, y, ok := dc.CurrentPoint(); !ok {
-		dc.MoveTo(dx, dy)
+	if x, y, ok := pb.CurrentPoint(); !ok {
+		pb.MoveTo(dx, dy)
 	} else {
-		dc.LineTo(x+dx, y+dy)
+		pb.LineTo(x+dx, y+dy)
 	}
 
 	return &object.Null{}
@@ -52,6 +57,10 @@ func RouteTo(env *object.Environment, args ...object.Object) object.Object {
 
 // MoveTo starts a new subpath within the current path starting at the specified point.
 func MoveTo(env *object.Environment, args ...object.Object) object.Object {
+	return moveTo(env.GraphicContext(), args)
+}
+
+func moveTo(pb gg.PathBuilder, args []object.Object) object.Object {
 	if err := typing.Check("moveTo", args, typing.ExactArgs(2)); err != nil {
 		return object.NewError(err.Error())
 	}
@@ -66,13 +75,17 @@ func MoveTo(env *object.Environment, args ...object.Object) object.Object {
 		return object.NewError("TypeError: moveTo() argument #2 %s", err.Error())
 	}
 
-	env.GraphicContext().MoveTo(x, y)
+	pb.MoveTo(x, y)
 	return &object.Null{}
 }
 
 // LineTo adds a line segment to the current path starting at the current point.
 // If there is no current point, it is equivalent to MoveTo(x, y)
 func LineTo(env *object.Environment, args ...object.Object) object.Object {
+	return lineTo(env.GraphicContext(), args)
+}
+
+func lineTo(pb gg.PathBuilder, args []object.Object) object.Object {
 	if err := typing.Check("lineTo", args, typing.ExactArgs(2)); err != nil {
 		return object.NewError(err.Error())
 	}
@@ -87,7 +100,7 @@ func LineTo(env *object.Environment, args ...object.Object) object.Object {
 		return object.NewError("TypeError: lineTo() argument #2 %s", err.Error())
 	}
 
-	env.GraphicContext().LineTo(x, y)
+	pb.LineTo(x, y)
 	return &object.Null{}
 }
 
@@ -96,6 +109,10 @@ func LineTo(env *object.Environment, args ...object.Object) object.Object {
 // The arc is automatically connected to the path's latest point
 // with a straight line, if necessary for the specified parameters.
 func ArcTo(env *object.Environment, args ...object.Object) object.Object {
+	return arcTo(env.GraphicContext(), args)
+}
+
+func arcTo(pb gg.PathBuilder, args []object.Object) object.Object {
 	if err := typing.Check("arcTo", args, typing.ExactArgs(5)); err != nil {
 		return object.NewError(err.Error())
 	}
@@ -125,7 +142,7 @@ func ArcTo(env *object.Environment, args ...object.Object) object.Object {
 		return object.NewError("TypeError: arcTo() argument #5 `r` %s", err.Error())
 	}
 
-	env.GraphicContext().ArcTo(x1, y1, x2, y2, r)
+	pb.ArcTo(x1, y1, x2, y2, r)
 	return &object.Null{}
 }
 
@@ -134,6 +151,10 @@ func ArcTo(env *object.Environment, args ...object.Object) object.Object {
 // The starting point is the latest point in the current path, which can be
 // changed using `moveTo()` before creating the quadratic Bézier curve.
 func QuadraticCurveTo(env *object.Environment, args ...object.Object) object.Object {
+	return quadraticCurveTo(env.GraphicContext(), args)
+}
+
+func quadraticCurveTo(pb gg.PathBuilder, args []object.Object) object.Object {
 	if err := typing.Check("quadraticCurveTo", args, typing.ExactArgs(4)); err != nil {
 		return object.NewError(err.Error())
 	}
@@ -158,6 +179,344 @@ func QuadraticCurveTo(env *object.Environment, args ...object.Object) object.Obj
 		return object.NewError("TypeError: quadraticCurveTo() argument #4 %s", err.Error())
 	}
 
-	env.GraphicContext().QuadraticTo(x1, y1, x2, y2)
+	pb.QuadraticTo(x1, y1, x2, y2)
+	return &object.Null{}
+}
+
+// BezierCurveTo adds a cubic Bézier curve to the current sub-path.
+// It requires three points: the first two are control points and the third one is the end point.
+// The starting point is the latest point in the current path, which can be
+// changed using `moveTo()` before creating the Bézier curve.
+func BezierCurveTo(env *object.Environment, args ...object.Object) object.Object {
+	return bezierCurveTo(env.GraphicContext(), args)
+}
+
+func bezierCurveTo(pb gg.PathBuilder, args []object.Object) object.Object {
+	if err := typing.Check("bezierCurveTo", args, typing.ExactArgs(6)); err != nil {
+		return object.NewError(err.Error())
+	}
+
+	xy, err := floatArgs("bezierCurveTo", args)
+	if err != nil {
+		return object.NewError(err.Error())
+	}
+
+	pb.CubicTo(xy[0], xy[1], xy[2], xy[3], xy[4], xy[5])
 	return &object.Null{}
 }
+
+// CurveThrough adds to the current sub-path a smooth curve (a Catmull-Rom spline)
+// passing through all the specified points.
+// curveThrough(points, [tension]) - points is an array of [x, y] pairs; tension
+// ranges from 0 (the default, smoothest curve) to 1 (straight segments).
+func CurveThrough(env *object.Environment, args ...object.Object) object.Object {
+	return curveThrough(env.GraphicContext(), args)
+}
+
+func curveThrough(pb gg.PathBuilder, args []object.Object) object.Object {
+	if err := typing.Check("curveThrough", args,
+		typing.RangeOfArgs(1, 2),
+		typing.WithTypes(object.ARRAY),
+	); err != nil {
+		return object.NewError(err.Error())
+	}
+
+	points, err := pathPoints("curveThrough", 1, args[0])
+	if err != nil {
+		return object.NewError(err.Error())
+	}
+
+	tension := 0.0
+	if len(args) == 2 {
+		tension, err = typing.ToFloat(args[1])
+		if err != nil {
+			return object.NewError("TypeError: curveThrough() argument #2 `tension` %s", err.Error())
+		}
+	}
+
+	gg.CurveThrough(pb, points, tension)
+	return &object.Null{}
+}
+
+// pathPoints converts an array of [x, y] pairs
+func pathPoints(name string, pos int, obj object.Object) ([][2]float64, error) {
+	arr, ok := obj.(*object.Array)
+	if !ok {
+		return nil, fmt.Errorf("TypeError: %s() argument #%d `points` expected to be `array` got `%s`",
+			name, pos, obj.Type())
+	}
+
+	res := make([][2]float64, len(arr.Elements))
+	for i, el := range arr.Elements {
+		pair, ok := el.(*object.Array)
+		if !ok || len(pair.Elements) != 2 {
+			return nil, fmt.Errorf("TypeError: %s() argument #%d `points` element #%d expected to be an [x, y] pair",
+				name, pos, i+1)
+		}
+
+		for j, v := range pair.Elements {
+			val, err := typing.ToFloat(v)
+			if err != nil {
+				return nil, fmt.Errorf("TypeError: %s() argument #%d `points` element #%d %s",
+					name, pos, i+1, err.Error())
+			}
+			res[i][j] = val
+		}
+	}
+
+	return res, nil
+}
+
+// NewPath creates a standalone path, that can be built once and drawn many times.
+// path() - returns an empty path, build it with its methods: p.moveTo(x, y),
+// p.lineTo(x, y), p.quadraticCurveTo(...), p.bezierCurveTo(...), p.arcTo(...),
+// p.arc(...), p.curveThrough(...), p.routeTo(...) and p.closePath().
+// p.translate(x, y), p.rotate(angle, [x, y]), p.scale(sx, sy, [x, y]) and
+// p.transform(a, b, c, d, e, f) return a transformed copy of the path.
+func NewPath(env *object.Environment, args ...object.Object) object.Object {
+	if err := typing.Check("path", args, typing.ExactArgs(0)); err != nil {
+		return object.NewError(err.Error())
+	}
+
+	return &object.Path{Value: gg.NewPath()}
+}
+
+// PathMethod returns the method of the path with the specified name
+func PathMethod(p *object.Path, name string) (*object.Builtin, bool) {
+	if fn, ok := pathBuilders[name]; ok {
+		return &object.Builtin{
+			Name: name,
+			Fn: func(env *object.Environment, args ...object.Object) object.Object {
+				return fn(p.Value, args)
+			},
+		}, true
+	}
+
+	if fn, ok := pathTransforms[name]; ok {
+		return &object.Builtin{
+			Name: name,
+			Fn: func(env *object.Environment, args ...object.Object) object.Object {
+				m, err := fn(args)
+				if err != nil {
+					return object.NewError(err.Error())
+				}
+				return &object.Path{Value: p.Value.Transform(m)}
+			},
+		}, true
+	}
+
+	return nil, false
+}
+
+var pathBuilders = map[string]func(pb gg.PathBuilder, args []object.Object) object.Object{
+	"moveTo":           moveTo,
+	"lineTo":           lineTo,
+	"routeTo":          routeTo,
+	"arcTo":            arcTo,
+	"arc":              arc,
+	"quadraticCurveTo": quadraticCurveTo,
+	"bezierCurveTo":    bezierCurveTo,
+	"curveThrough":     curveThrough,
+	"closePath": func(pb gg.PathBuilder, args []object.Object) object.Object {
+		pb.ClosePath()
+		return &object.Null{}
+	},
+}
+
+var pathTransforms = map[string]func(args []object.Object) (gg.Matrix, error){
+	"translate": func(args []object.Object) (gg.Matrix, error) {
+		if err := typing.Check("translate", args, typing.ExactArgs(2)); err != nil {
+			return gg.Matrix{}, err
+		}
+		v, err := floatArgs("translate", args)
+		if err != nil {
+			return gg.Matrix{}, err
+		}
+		return gg.Translate(v[0], v[1]), nil
+	},
+	"rotate": func(args []object.Object) (gg.Matrix, error) {
+		if err := typing.Check("rotate", args, typing.RangeOfArgs(1, 3)); err != nil {
+			return gg.Matrix{}, err
+		}
+		v, err := floatArgs("rotate", args)
+		if err != nil {
+			return gg.Matrix{}, err
+		}
+		if len(v) == 1 {
+			return gg.Rotate(v[0]), nil
+		}
+		if len(v) != 3 {
+			return gg.Matrix{}, fmt.Errorf("TypeError: rotate() takes 1 or 3 arguments, given: %d", len(v))
+		}
+		about := gg.Translate(-v[1], -v[2]).Multiply(gg.Rotate(v[0]))
+		return about.Multiply(gg.Translate(v[1], v[2])), nil
+	},
+	"scale": func(args []object.Object) (gg.Matrix, error) {
+		if err := typing.Check("scale", args, typing.RangeOfArgs(2, 4)); err != nil {
+			return gg.Matrix{}, err
+		}
+		v, err := floatArgs("scale", args)
+		if err != nil {
+			return gg.Matrix{}, err
+		}
+		if len(v) == 2 {
+			return gg.Scale(v[0], v[1]), nil
+		}
+		if len(v) != 4 {
+			return gg.Matrix{}, fmt.Errorf("TypeError: scale() takes 2 or 4 arguments, given: %d", len(v))
+		}
+		about := gg.Translate(-v[2], -v[3]).Multiply(gg.Scale(v[0], v[1]))
+		return about.Multiply(gg.Translate(v[2], v[3])), nil
+	},
+	"transform": func(args []object.Object) (gg.Matrix, error) {
+		if err := typing.Check("transform", args, typing.ExactArgs(6)); err != nil {
+			return gg.Matrix{}, err
+		}
+		v, err := floatArgs("transform", args)
+		if err != nil {
+			return gg.Matrix{}, err
+		}
+		return gg.Matrix{XX: v[0], YX: v[1], XY: v[2], YY: v[3], X0: v[4], Y0: v[5]}, nil
+	},
+}
+
+// Bounds returns the bounding box of a path as [x, y, w, h].
+func Bounds(env *object.Environment, args ...object.Object) object.Object {
+	if err := typing.Check("bounds", args,
+		typing.ExactArgs(1),
+		typing.WithTypes(object.PATH),
+	); err != nil {
+		return object.NewError(err.Error())
+	}
+
+	x0, y0, x1, y1 := args[0].(*object.Path).Value.Bounds()
+	return &object.Array{
+		Elements: []object.Object{
+			&object.Float{Value: x0},
+			&object.Float{Value: y0},
+			&object.Float{Value: x1 - x0},
+			&object.Float{Value: y1 - y0},
+		},
+	}
+}
+
+// Contains tells if a point is inside a path.
+// contains(p, x, y, [rule]) - rule is the fill rule used to tell the inside
+// of the path: "nonzero" (the default) or "evenodd".
+func Contains(env *object.Environment, args ...object.Object) object.Object {
+	if err := typing.Check("contains", args,
+		typing.RangeOfArgs(3, 4),
+		typing.WithTypes(object.PATH),
+	); err != nil {
+		return object.NewError(err.Error())
+	}
+
+	x, err := typing.ToFloat(args[1])
+	if err != nil {
+		return object.NewError("TypeError: contains() argument #2 `x` %s", err.Error())
+	}
+
+	y, err := typing.ToFloat(args[2])
+	if err != nil {
+		return object.NewError("TypeError: contains() argument #3 `y` %s", err.Error())
+	}
+
+	rule := gg.FillRuleWinding
+	if len(args) == 4 {
+		name, err := typing.ToString(args[3])
+		if err != nil {
+			return object.NewError("TypeError: contains() argument #4 %s", err.Error())
+		}
+		var ok bool
+		if rule, ok = fillRules[name]; !ok {
+			return object.NewError("ValueError: contains() argument #4 must be `nonzero` or `evenodd`")
+		}
+	}
+
+	return &object.Boolean{Value: args[0].(*object.Path).Value.Contains(x, y, rule)}
+}
+
+// PathLength returns the length of a path.
+// pathLength() - returns the length of the current path.
+// pathLength(p) - returns the length of the path `p`.
+func PathLength(env *object.Environment, args ...object.Object) object.Object {
+	if err := typing.Check("pathLength", args, typing.RangeOfArgs(0, 1)); err != nil {
+		return object.NewError(err.Error())
+	}
+
+	p, err := measuredPath("pathLength", env, args, 1)
+	if err != nil {
+		return object.NewError(err.Error())
+	}
+
+	return &object.Float{Value: p.Length()}
+}
+
+// PointAtLength returns the point at the specified distance along a path.
+// pointAtLength(d, [p]) - returns the [x, y] point at distance `d` along the
+// current path (or the path `p`); `d` is clamped to the length of the path.
+func PointAtLength(env *object.Environment, args ...object.Object) object.Object {
+	pt, _, ok, err := atLength("pointAtLength", env, args)
+	if err != nil {
+		return object.NewError(err.Error())
+	}
+	if !ok {
+		return &object.Null{}
+	}
+
+	return &object.Array{
+		Elements: []object.Object{
+			&object.Float{Value: pt.X},
+			&object.Float{Value: pt.Y},
+		},
+	}
+}
+
+// TangentAtLength returns the direction of a path at the specified distance along it.
+// tangentAtLength(d, [p]) - returns the angle (in radians) of the tangent at
+// distance `d` along the current path (or the path `p`).
+func TangentAtLength(env *object.Environment, args ...object.Object) object.Object {
+	_, angle, ok, err := atLength("tangentAtLength", env, args)
+	if err != nil {
+		return object.NewError(err.Error())
+	}
+	if !ok {
+		return &object.Null{}
+	}
+
+	return &object.Float{Value: angle}
+}
+
+func atLength(name string, env *object.Environment, args []object.Object) (gg.Point, float64, bool, error) {
+	if err := typing.Check(name, args, typing.RangeOfArgs(1, 2)); err != nil {
+		return gg.Point{}, 0, false, err
+	}
+
+	d, err := typing.ToFloat(args[0])
+	if err != nil {
+		return gg.Point{}, 0, false, fmt.Errorf("TypeError: %s() argument #1 `d` %s", name, err.Error())
+	}
+
+	p, err := measuredPath(name, env, args, 2)
+	if err != nil {
+		return gg.Point{}, 0, false, err
+	}
+
+	pt, angle, ok := p.PointAt(d)
+	return pt, angle, ok, nil
+}
+
+// measuredPath returns the path argument at the specified position,
+// the current path if it is missing
+func measuredPath(name string, env *object.Environment, args []object.Object, pos int) (*gg.Path, error) {
+	if len(args) < pos {
+		return env.GraphicContext().CurrentPath(), nil
+	}
+
+	p, ok := args[pos-1].(*object.Path)
+	if !ok {
+		return nil, fmt.Errorf("TypeError: %s() expected argument #%d to be `path` got `%s`",
+			name, pos, args[pos-1].Type())
+	}
+	return p.Value, nil
+}
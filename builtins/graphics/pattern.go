@@ -0,0 +1,200 @@
+package graphics
+
+import (
+	"fmt"
+	"image/color"
+
+	"github.com/lucasepe/g2d/gg"
+	"github.com/lucasepe/g2d/object"
+	"github.com/lucasepe/g2d/typing"
+)
+
+// LinearGradient returns a gradient along a line, usable as fill or stroke color.
+// linearGradient(x0, y0, x1, y1, stops) - creates a gradient from the start
+// point (x0, y0) to the end point (x1, y1); stops is an array of [offset, color]
+// pairs, where offset ranges from 0.0 to 1.0 and color is an hex color string.
+func LinearGradient(env *object.Environment, args ...object.Object) object.Object {
+	if err := typing.Check("linearGradient", args, typing.ExactArgs(5)); err != nil {
+		return object.NewError(err.Error())
+	}
+
+	coords, err := floatArgs("linearGradient", args[:4])
+	if err != nil {
+		return object.NewError(err.Error())
+	}
+
+	stops, err := gradientStops("linearGradient", 5, args[4])
+	if err != nil {
+		return object.NewError(err.Error())
+	}
+
+	res := gg.NewLinearGradient(coords[0], coords[1], coords[2], coords[3])
+	res.Stops = stops
+	return &object.Pattern{Value: res}
+}
+
+// RadialGradient returns a radial gradient, usable as fill or stroke color.
+// radialGradient(x, y, r, stops) - creates a gradient from the center (x, y)
+// to the circle of radius r.
+// radialGradient(x0, y0, r0, x1, y1, r1, stops) - creates a gradient from the
+// start circle (x0, y0, r0) to the end circle (x1, y1, r1).
+func RadialGradient(env *object.Environment, args ...object.Object) object.Object {
+	if len(args) != 4 && len(args) != 7 {
+		return object.NewError("TypeError: radialGradient() takes 4 or 7 arguments (%d given)", len(args))
+	}
+
+	coords, err := floatArgs("radialGradient", args[:len(args)-1])
+	if err != nil {
+		return object.NewError(err.Error())
+	}
+
+	stops, err := gradientStops("radialGradient", len(args), args[len(args)-1])
+	if err != nil {
+		return object.NewError(err.Error())
+	}
+
+	var res *gg.RadialGradient
+	if len(coords) == 3 {
+		res = gg.NewRadialGradient(coords[0], coords[1], 0, coords[0], coords[1], coords[2])
+	} else {
+		res = gg.NewRadialGradient(coords[0], coords[1], coords[2], coords[3], coords[4], coords[5])
+	}
+	res.Stops = stops
+	return &object.Pattern{Value: res}
+}
+
+// ConicGradient returns a gradient around a point, usable as fill or stroke color.
+// conicGradient(x, y, angle, stops) - creates a gradient around the center (x, y)
+// starting at the specified angle (in radians) and going clockwise.
+func ConicGradient(env *object.Environment, args ...object.Object) object.Object {
+	if err := typing.Check("conicGradient", args, typing.ExactArgs(4)); err != nil {
+		return object.NewError(err.Error())
+	}
+
+	coords, err := floatArgs("conicGradient", args[:3])
+	if err != nil {
+		return object.NewError(err.Error())
+	}
+
+	stops, err := gradientStops("conicGradient", 4, args[3])
+	if err != nil {
+		return object.NewError(err.Error())
+	}
+
+	res := gg.NewConicGradient(coords[0], coords[1], coords[2])
+	res.Stops = stops
+	return &object.Pattern{Value: res}
+}
+
+// gradientStops converts an array of [offset, color] pairs to color stops
+func gradientStops(name string, pos int, obj object.Object) (gg.Stops, error) {
+	arr, ok := obj.(*object.Array)
+	if !ok {
+		return nil, fmt.Errorf("TypeError: %s() argument #%d `stops` expected to be `array` got `%s`",
+			name, pos, obj.Type())
+	}
+
+	res := gg.Stops{}
+	for i, el := range arr.Elements {
+		pair, ok := el.(*object.Array)
+		if !ok || len(pair.Elements) != 2 {
+			return nil, fmt.Errorf("TypeError: %s() argument #%d `stops` element #%d expected to be an [offset, color] pair",
+				name, pos, i+1)
+		}
+
+		offset, err := typing.ToFloat(pair.Elements[0])
+		if err != nil {
+			return nil, fmt.Errorf("TypeError: %s() argument #%d `stops` element #%d offset %s",
+				name, pos, i+1, err.Error())
+		}
+
+		hex, ok := pair.Elements[1].(*object.String)
+		if !ok {
+			return nil, fmt.Errorf("TypeError: %s() argument #%d `stops` element #%d color expected to be `str` got `%s`",
+				name, pos, i+1, pair.Elements[1].Type())
+		}
+
+		r, g, b, a := parseHexColor(hex.Value)
+		res.Add(offset, color.NRGBA{uint8(r), uint8(g), uint8(b), uint8(a)})
+	}
+
+	return res, nil
+}
+
+// FillPattern fills using an image repeated as a texture.
+// fillPattern(img, [repeat, [x, y, [scale, [angle]]]]) - repeat is one of
+// "repeat" (the default), "repeat-x", "repeat-y" or "no-repeat"; the top left
+// corner of the image is placed at (x, y), then the image is scaled by scale
+// and rotated by angle (in radians) about that point.
+func FillPattern(env *object.Environment, args ...object.Object) object.Object {
+	p, err := imagePattern("fillPattern", args)
+	if err != nil {
+		return object.NewError(err.Error())
+	}
+
+	env.GraphicContext().SetFillStyle(p)
+	return &object.Null{}
+}
+
+// StrokePattern strokes using an image repeated as a texture.
+// strokePattern(img, [repeat, [x, y, [scale, [angle]]]]) - see fillPattern.
+func StrokePattern(env *object.Environment, args ...object.Object) object.Object {
+	p, err := imagePattern("strokePattern", args)
+	if err != nil {
+		return object.NewError(err.Error())
+	}
+
+	env.GraphicContext().SetStrokeStyle(p)
+	return &object.Null{}
+}
+
+var repeatOps = map[string]gg.RepeatOp{
+	"repeat":    gg.RepeatBoth,
+	"repeat-x":  gg.RepeatX,
+	"repeat-y":  gg.RepeatY,
+	"no-repeat": gg.RepeatNone,
+}
+
+func imagePattern(name string, args []object.Object) (gg.Pattern, error) {
+	if err := typing.Check(name, args,
+		typing.RangeOfArgs(1, 6),
+		typing.WithTypes(object.IMAGE, object.STRING),
+	); err != nil {
+		return nil, err
+	}
+
+	if len(args) == 3 {
+		return nil, fmt.Errorf("TypeError: %s() argument #4 `y` is missing", name)
+	}
+
+	im := args[0].(*object.Image).Value
+	if im == nil {
+		return nil, fmt.Errorf("ValueError: %s() argument #1 is an empty image", name)
+	}
+
+	op := gg.RepeatBoth
+	if len(args) > 1 {
+		val, ok := repeatOps[args[1].(*object.String).Value]
+		if !ok {
+			return nil, fmt.Errorf("ValueError: %s() argument #2 must be one of "+
+				"`repeat`, `repeat-x`, `repeat-y` or `no-repeat`", name)
+		}
+		op = val
+	}
+
+	// x, y, scale, angle
+	params := []float64{0, 0, 1, 0}
+	for i := 2; i < len(args); i++ {
+		val, err := typing.ToFloat(args[i])
+		if err != nil {
+			return nil, fmt.Errorf("TypeError: %s() argument #%d %s", name, i+1, err.Error())
+		}
+		params[i-2] = val
+	}
+
+	m := gg.Scale(params[2], params[2]).
+		Multiply(gg.Rotate(params[3])).
+		Multiply(gg.Translate(params[0], params[1]))
+
+	return gg.NewTransformedSurfacePattern(im, op, m), nil
+}
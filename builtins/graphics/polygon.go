@@ -0,0 +1,108 @@
+package graphics
+
+import (
+	"fmt"
+
+	"github.com/lucasepe/g2d/geom"
+	"github.com/lucasepe/g2d/gg"
+	"github.com/lucasepe/g2d/object"
+	"github.com/lucasepe/g2d/typing"
+)
+
+// Union returns the region covered by the shape `a` or by the shape `b`.
+// union(a, b) - the shapes are paths, or polygons as arrays of [x, y]
+// points (or arrays of them, for polygons with holes); the inside of the
+// shapes is given by the even-odd rule. It returns a path.
+func Union(env *object.Environment, args ...object.Object) object.Object {
+	return polygonOp("union", geom.Union, args)
+}
+
+// Intersect returns the region covered by both the shapes `a` and `b`.
+// intersect(a, b) - see union() for the arguments.
+func Intersect(env *object.Environment, args ...object.Object) object.Object {
+	return polygonOp("intersect", geom.Intersection, args)
+}
+
+// Difference returns the region covered by the shape `a` but not by the shape `b`.
+// difference(a, b) - see union() for the arguments.
+func Difference(env *object.Environment, args ...object.Object) object.Object {
+	return polygonOp("difference", geom.Difference, args)
+}
+
+// Xor returns the region covered by either the shape `a` or `b`, but not by both.
+// xor(a, b) - see union() for the arguments.
+func Xor(env *object.Environment, args ...object.Object) object.Object {
+	return polygonOp("xor", geom.Xor, args)
+}
+
+func polygonOp(name string, op func(a, b geom.Polygon) geom.Polygon, args []object.Object) object.Object {
+	if err := typing.Check(name, args, typing.ExactArgs(2)); err != nil {
+		return object.NewError(err.Error())
+	}
+
+	a, err := polygon(name, 1, args[0])
+	if err != nil {
+		return object.NewError(err.Error())
+	}
+
+	b, err := polygon(name, 2, args[1])
+	if err != nil {
+		return object.NewError(err.Error())
+	}
+
+	res := gg.NewPath()
+	for _, c := range op(a, b) {
+		res.MoveTo(c[0].X, c[0].Y)
+		for _, pt := range c[1:] {
+			res.LineTo(pt.X, pt.Y)
+		}
+		res.ClosePath()
+	}
+
+	return &object.Path{Value: res}
+}
+
+// polygon converts a path or an array of points (or an array of arrays
+// of points) to a polygon
+func polygon(name string, pos int, obj object.Object) (geom.Polygon, error) {
+	switch obj := obj.(type) {
+	case *object.Path:
+		res := geom.Polygon{}
+		for _, line := range obj.Value.Flatten() {
+			c := make(geom.Contour, len(line))
+			for i, pt := range line {
+				c[i] = geom.Point{X: pt.X, Y: pt.Y}
+			}
+			res = append(res, c)
+		}
+		return res, nil
+
+	case *object.Array:
+		contours := []object.Object{obj}
+		if len(obj.Elements) > 0 {
+			if el, ok := obj.Elements[0].(*object.Array); ok && len(el.Elements) > 0 {
+				if _, ok := el.Elements[0].(*object.Array); ok {
+					contours = obj.Elements
+				}
+			}
+		}
+
+		res := geom.Polygon{}
+		for _, el := range contours {
+			points, err := pathPoints(name, pos, el)
+			if err != nil {
+				return nil, err
+			}
+			c := make(geom.Contour, len(points))
+			for i, pt := range points {
+				c[i] = geom.Point{X: pt[0], Y: pt[1]}
+			}
+			res = append(res, c)
+		}
+		return res, nil
+
+	default:
+		return nil, fmt.Errorf("TypeError: %s() argument #%d expected to be `path` or `array` got `%s`",
+			name, pos, obj.Type())
+	}
+}
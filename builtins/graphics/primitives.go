@@ -1,6 +1,7 @@
 package graphics
 
 import (
+	"github.com/lucasepe/g2d/gg"
 	"github.com/lucasepe/g2d/object"
 	"github.com/lucasepe/g2d/typing"
 )
@@ -8,6 +9,10 @@ import (
 // Arc draws a circular arc centered at `x, y` with a radius of `r`.
 // The path starts at `angle1`, ends at `angle2`, and travels in the direction given by anticlockwise.
 func Arc(env *object.Environment, args ...object.Object) object.Object {
+	return arc(env.GraphicContext(), args)
+}
+
+func arc(pb gg.PathBuilder, args []object.Object) object.Object {
 	if err := typing.Check("arc", args, typing.RangeOfArgs(5, 6)); err != nil {
 		return object.NewError(err.Error())
 	}
@@ -38,7 +43,7 @@ func Arc(env *object.Environment, args ...object.Object) object.Object {
 			return object.NewError("TypeError: arc() argument #5 `ea` %s", err.Error())
 		}
 
-		drawArc(env.GraphicContext(), x, y, r, sa, ea)
+		drawArc(pb, x, y, r, sa, ea)
 		return &object.Null{}
 	}
 
@@ -62,9 +67,8 @@ func Arc(env *object.Environment, args ...object.Object) object.Object {
 		return object.NewError("TypeError: arc() argument #6 `ea` %s", err.Error())
 	}
 
-	env.GraphicContext().DrawEllipticalArc(x, y, rx, ry, sa, ea)
+	pb.DrawEllipticalArc(x, y, rx, ry, sa, ea)
 	return &object.Null{}
-
 }
 
 // Point draws a point at specified coordinates.
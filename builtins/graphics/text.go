@@ -1,10 +1,18 @@
 package graphics
 
 import (
+	"math"
+	"strings"
+
+	"github.com/lucasepe/g2d/data"
+	"github.com/lucasepe/g2d/gg"
 	"github.com/lucasepe/g2d/object"
 	"github.com/lucasepe/g2d/typing"
 )
 
+// max size (in bytes) of a loaded font
+const fontLimit = 32 * 1024 * 1024
+
 // Text draws the specified text at the specified anchor point.
 // The anchor point is x - w * ax, y - h * ay, where w, h is the size of the
 // text. Use ax=0.5, ay=0.5 to center the text at the specified point.
@@ -70,6 +78,125 @@ func TextWidth(env *object.Environment, args ...object.Object) object.Object {
 	*/
 }
 
+// TextBox draws a multi-line text wrapped to the specified width.
+// textBox(str, x, y, width, [align], [lineSpacing]) - x, y is the top left
+// corner of the box, align is one of "left" (the default), "center" or
+// "right", lineSpacing is the distance between the lines as a multiple of
+// the font height (by default 1). Returns the height of the box.
+func TextBox(env *object.Environment, args ...object.Object) object.Object {
+	if err := typing.Check("textBox", args, typing.RangeOfArgs(4, 6)); err != nil {
+		return object.NewError(err.Error())
+	}
+
+	txt, err := typing.ToString(args[0])
+	if err != nil {
+		return object.NewError("TypeError: textBox() argument #1 %s", err.Error())
+	}
+
+	vals := make([]float64, 3)
+	for i := range vals {
+		if vals[i], err = typing.ToFloat(args[i+1]); err != nil {
+			return object.NewError("TypeError: textBox() argument #%d %s", i+2, err.Error())
+		}
+	}
+
+	align := gg.AlignLeft
+	if len(args) > 4 {
+		str, ok := args[4].(*object.String)
+		if !ok {
+			return object.NewError("TypeError: textBox() expected argument #5 to be `str` got `%s`", args[4].Type())
+		}
+		if align, ok = textAligns[str.Value]; !ok {
+			return object.NewError("ValueError: textBox() argument #5 must be `left`, `center` or `right`")
+		}
+	}
+
+	spacing := 1.0
+	if len(args) > 5 {
+		if spacing, err = typing.ToFloat(args[5]); err != nil {
+			return object.NewError("TypeError: textBox() argument #6 %s", err.Error())
+		}
+	}
+
+	h := gg.DrawStringWrapped(env.GraphicContext(), txt, vals[0], vals[1], vals[2], spacing, align)
+	return &object.Float{Value: h}
+}
+
+var textAligns = map[string]gg.Align{
+	"left":   gg.AlignLeft,
+	"center": gg.AlignCenter,
+	"right":  gg.AlignRight,
+}
+
+// TextBounds returns the size of the specified text given the current font
+// face, as an array [width, height, ascent, descent]. The text may span
+// several lines, the height is the number of lines times the font height.
+func TextBounds(env *object.Environment, args ...object.Object) object.Object {
+	if err := typing.Check("textBounds", args, typing.ExactArgs(1)); err != nil {
+		return object.NewError(err.Error())
+	}
+
+	txt, err := typing.ToString(args[0])
+	if err != nil {
+		return object.NewError("TypeError: textBounds() argument #1 %s", err.Error())
+	}
+
+	dc := env.GraphicContext()
+	w, h := 0.0, 0.0
+	for _, line := range strings.Split(txt, "\n") {
+		lw, lh := dc.MeasureString(line)
+		w, h = math.Max(w, lw), h+lh
+	}
+	ascent, descent := dc.FontMetrics()
+
+	return &object.Array{
+		Elements: []object.Object{
+			&object.Float{Value: w},
+			&object.Float{Value: h},
+			&object.Float{Value: ascent},
+			&object.Float{Value: descent},
+		},
+	}
+}
+
+// TextOnPath draws the text along the current path, each glyph rotated
+// to follow the direction of the path.
+// textOnPath(str, [offset], [align]) - align is one of "left" (the default),
+// "center" or "right" and places the text at the start, in the middle or
+// at the end of the path; offset moves it forward along the path.
+func TextOnPath(env *object.Environment, args ...object.Object) object.Object {
+	if err := typing.Check("textOnPath", args, typing.RangeOfArgs(1, 3)); err != nil {
+		return object.NewError(err.Error())
+	}
+
+	txt, err := typing.ToString(args[0])
+	if err != nil {
+		return object.NewError("TypeError: textOnPath() argument #1 %s", err.Error())
+	}
+
+	offset := 0.0
+	if len(args) > 1 {
+		if offset, err = typing.ToFloat(args[1]); err != nil {
+			return object.NewError("TypeError: textOnPath() argument #2 %s", err.Error())
+		}
+	}
+
+	align := gg.AlignLeft
+	if len(args) > 2 {
+		str, ok := args[2].(*object.String)
+		if !ok {
+			return object.NewError("TypeError: textOnPath() expected argument #3 to be `str` got `%s`", args[2].Type())
+		}
+		if align, ok = textAligns[str.Value]; !ok {
+			return object.NewError("ValueError: textOnPath() argument #3 must be `left`, `center` or `right`")
+		}
+	}
+
+	dc := env.GraphicContext()
+	gg.DrawStringOnPath(dc, dc.CurrentPath(), txt, offset, align)
+	return &object.Null{}
+}
+
 // FontSize sets the size of the current font face.
 // `fontSize()` returns the current font height
 // `fontSize(size)` sets the current font height
@@ -91,3 +218,80 @@ func FontSize(env *object.Environment, args ...object.Object) object.Object {
 	env.GraphicContext().SetFontSize(size)
 	return &object.Null{}
 }
+
+// LoadFont loads a TrueType (or OpenType with TrueType outlines) font.
+// loadFont(name) - name is the path (or URL) of the font file, relative
+// to the script, or one of the bundled Go fonts: "regular", "bold",
+// "italic", "bold-italic", "mono" and "mono-bold".
+func LoadFont(env *object.Environment, args ...object.Object) object.Object {
+	if err := typing.Check("loadFont", args,
+		typing.ExactArgs(1),
+		typing.WithTypes(object.STRING),
+	); err != nil {
+		return object.NewError(err.Error())
+	}
+
+	return loadFont("loadFont", env, args[0].(*object.String).Value)
+}
+
+// loadFont loads the font, a bundled one or a font file; name is the
+// builtin loading it, used in the error messages
+func loadFont(name string, env *object.Environment, font string) object.Object {
+	if f, err := gg.BundledFont(font); err == nil {
+		return &object.Font{Name: font, Value: f}
+	}
+
+	uri, err := data.Resolve(env.ScriptPath(), font)
+	if err != nil {
+		return object.NewError("IOError: %s() - %s", name, err.Error())
+	}
+
+	env.AddDependency(uri)
+
+	ttf, err := data.Fetch(uri, fontLimit)
+	if err != nil {
+		return object.NewError("IOError: %s() - %s (bundled fonts are: %s)",
+			name, err.Error(), strings.Join(gg.BundledFontNames(), ", "))
+	}
+
+	f, err := gg.ParseFont(uri, ttf)
+	if err != nil {
+		return object.NewError("DecodeError: %s() - %s", name, err.Error())
+	}
+
+	return &object.Font{Name: font, Value: f}
+}
+
+// TextFont sets the font used to draw and measure the text.
+// textFont(font, [size]) - font is a font loaded with loadFont() or
+// a name accepted by loadFont(); size optionally sets the font size too.
+func TextFont(env *object.Environment, args ...object.Object) object.Object {
+	if err := typing.Check("textFont", args, typing.RangeOfArgs(1, 2)); err != nil {
+		return object.NewError(err.Error())
+	}
+
+	var font *object.Font
+	switch arg := args[0].(type) {
+	case *object.Font:
+		font = arg
+	case *object.String:
+		res := loadFont("textFont", env, arg.Value)
+		if res.Type() == object.ERROR {
+			return res
+		}
+		font = res.(*object.Font)
+	default:
+		return object.NewError("TypeError: textFont() expected argument #1 to be `font` or `str` got `%s`", arg.Type())
+	}
+
+	if len(args) == 2 {
+		size, err := typing.ToFloat(args[1])
+		if err != nil {
+			return object.NewError("TypeError: textFont() argument #2 %s", err.Error())
+		}
+		env.GraphicContext().SetFontSize(size)
+	}
+
+	env.GraphicContext().SetFont(font.Value)
+	return &object.Null{}
+}
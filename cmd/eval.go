@@ -8,13 +8,19 @@ import (
 	"path"
 	"path/filepath"
 	"strings"
+	"time"
 
+	"github.com/lucasepe/g2d/ast"
 	"github.com/lucasepe/g2d/data"
 	"github.com/lucasepe/g2d/eval"
+	"github.com/lucasepe/g2d/gg"
 	"github.com/lucasepe/g2d/gg/img"
+	"github.com/lucasepe/g2d/gg/pdf"
+	"github.com/lucasepe/g2d/gg/svg"
 	"github.com/lucasepe/g2d/lexer"
 	"github.com/lucasepe/g2d/object"
 	"github.com/lucasepe/g2d/parser"
+	"github.com/lucasepe/g2d/vm"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 )
@@ -24,6 +30,11 @@ const (
 
 	optDirectory = "directory"
 	optPrefix    = "prefix"
+	optDriver    = "driver"
+	optWatch     = "watch"
+	optInterval  = "interval"
+	optEngine    = "engine"
+	optSeed      = "seed"
 )
 
 // renderCmd represents the render command
@@ -35,6 +46,17 @@ var evalCmd = &cobra.Command{
 	Short:                 "Evaluate a g2d script",
 	Example:               evalCmdExample(),
 	Run: func(cmd *cobra.Command, args []string) {
+		watch, err := cmd.Flags().GetBool(optWatch)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %s\n", err.Error())
+			os.Exit(1)
+		}
+
+		if watch {
+			runWatch(cmd, args)
+			return
+		}
+
 		src, err := data.Fetch(args[0], limit)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "error: %s\n", err.Error())
@@ -47,13 +69,32 @@ var evalCmd = &cobra.Command{
 			os.Exit(1)
 		}
 
+		driver, err := cmd.Flags().GetString(optDriver)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %s\n", err.Error())
+			os.Exit(1)
+		}
+
+		engine, err := cmd.Flags().GetString(optEngine)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %s\n", err.Error())
+			os.Exit(1)
+		}
+
+		seed, err := seedFlag(cmd)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %s\n", err.Error())
+			os.Exit(1)
+		}
+
 		prefix, err := lastPathSegment(args[0])
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "error: %s\n", err.Error())
 			os.Exit(1)
 		}
 
-		if err := doEval(src, directory, prefix); err != nil {
+		fmt.Fprintf(os.Stderr, "seed: %d\n", seed)
+		if _, err := doEval(src, args[0], directory, prefix, driver, engine, seed); err != nil {
 			fmt.Fprintf(os.Stderr, "error: %s\n", err.Error())
 			os.Exit(1)
 		}
@@ -63,6 +104,11 @@ var evalCmd = &cobra.Command{
 func init() {
 
 	evalCmd.Flags().StringP(optDirectory, "d", "", "snapshots destination folder (note that must exist)")
+	evalCmd.Flags().String(optDriver, "img", "graphic backend: img (raster images), svg or pdf (vector documents)")
+	evalCmd.Flags().String(optEngine, "vm", "execution engine: vm (bytecode virtual machine) or eval (tree-walking evaluator)")
+	evalCmd.Flags().Int64(optSeed, 0, "seed of the random numbers generator (default a new one on each run)")
+	evalCmd.Flags().Bool(optWatch, false, "re-evaluate the script each time it (or any file it loads) changes")
+	evalCmd.Flags().Duration(optInterval, defaultInterval, "polling interval of the watch mode")
 	//evalCmd.MarkFlagRequired(optDirectory)
 
 	rootCmd.AddCommand(evalCmd)
@@ -70,18 +116,29 @@ func init() {
 
 func evalCmdExample() string {
 	tpl := `  {{APP}} eval https://github.com/lucasepe/g2d/_examples/circles.g2d
-  {{APP}} eval /path/to/my_script.g2d`
+  {{APP}} eval /path/to/my_script.g2d
+  {{APP}} eval --driver svg /path/to/my_script.g2d
+  {{APP}} eval --engine eval /path/to/my_script.g2d
+  {{APP}} eval --seed 42 /path/to/my_script.g2d
+  {{APP}} eval --watch /path/to/my_script.g2d`
 
 	return strings.Replace(tpl, "{{APP}}", appName(), -1)
 }
 
-// Eval parses and evalulates the program given by f and returns the resulting
-// environment, any errors are printed to stderr
-func doEval(src []byte, directory, prefix string) error {
-	ctx := img.NewContextForRGBA(image.NewRGBA(image.Rect(0, 0, 1024, 1024)))
+// Eval parses and evalulates the program given by f with the specified engine
+// and random seed and returns the resulting environment (nil only for unknown
+// drivers); imports are resolved relative to the script path (or URL)
+func doEval(src []byte, script, directory, prefix, driver, engine string, seed int64) (*object.Environment, error) {
+	ctx, err := newGraphicContext(driver, 1024, 1024)
+	if err != nil {
+		return nil, err
+	}
+
 	env := object.NewEnvironment(ctx,
 		object.WithOutputDir(directory),
-		object.WithSnapshotPrefix(prefix))
+		object.WithSnapshotPrefix(prefix),
+		object.WithScriptPath(script),
+		object.WithSeed(seed))
 
 	l := lexer.New(string(src))
 	p := parser.New(l)
@@ -97,15 +154,56 @@ func doEval(src []byte, directory, prefix string) error {
 				err = errors.Wrapf(err, "\n%s", p.Errors()[i])
 			}
 		}
-		return err
+		return env, err
 	}
 
 	// if obj := eval.Eval(program, env); obj.Type() == object.ERROR {//
-	if obj := eval.BeginEval(program, env, l); (obj != nil) && (obj.Type() == object.ERROR) {
-		return errors.New(obj.String())
+	obj, err := run(engine, program, env, l)
+	if err != nil {
+		return env, err
+	}
+
+	if (obj != nil) && (obj.Type() == object.ERROR) {
+		return env, errors.New(obj.String())
+	}
+
+	return env, nil
+}
+
+// run runs the program with the specified engine: vm (the bytecode
+// virtual machine, the default) or eval (the tree-walking evaluator)
+func run(engine string, program *ast.Program, env *object.Environment, l *lexer.Lexer) (object.Object, error) {
+	switch engine {
+	case "", "vm":
+		return vm.BeginRun(program, env, l), nil
+	case "eval":
+		return eval.BeginEval(program, env, l), nil
+	default:
+		return nil, fmt.Errorf("unknown engine '%s'", engine)
 	}
+}
 
-	return nil
+// seedFlag returns the seed given by the flag, a new one if not set
+func seedFlag(cmd *cobra.Command) (int64, error) {
+	if !cmd.Flags().Changed(optSeed) {
+		return time.Now().UnixNano(), nil
+	}
+
+	return cmd.Flags().GetInt64(optSeed)
+}
+
+// newGraphicContext creates a graphic context for the specified driver
+func newGraphicContext(driver string, w, h int) (gg.GraphicContext, error) {
+	switch driver {
+	case "", "img":
+		return img.NewContextForRGBA(image.NewRGBA(image.Rect(0, 0, w, h))), nil
+	case "svg":
+		return svg.NewContext(w, h), nil
+	case "pdf":
+		return pdf.NewContext(w, h), nil
+	default:
+		return nil, fmt.Errorf("unknown driver '%s'", driver)
+	}
 }
 
 func lastPathSegment(uri string) (string, error) {
@@ -1,6 +1,13 @@
 package cmd
 
-import "testing"
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
 
 func TestLastPathSegment(t *testing.T) {
 	test := []struct {
@@ -25,3 +32,76 @@ func TestLastPathSegment(t *testing.T) {
 		})
 	}
 }
+
+func TestSnapshotFormats(t *testing.T) {
+	dir, err := ioutil.TempDir("", "g2d-snapshot")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	tests := []struct {
+		driver   string
+		filename string
+		saved    string
+		err      string
+	}{
+		{"img", "out.png", "out.png", ""},
+		{"img", "out.JPG", "out.JPG", ""},
+		{"img", "out", "out.png", ""},
+		{"svg", "out.svg", "out.svg", ""},
+		{"pdf", "out.pdf", "out.pdf", ""},
+
+		// the formats of the other drivers are replaced
+		{"img", "vector.svg", "vector.png", ""},
+		{"img", "vector.pdf", "vector.png", ""},
+		{"svg", "raster.png", "raster.svg", ""},
+		{"pdf", "raster.jpg", "raster.pdf", ""},
+		{"pdf", "out.gif", "", "snapshot() unsupported format 'gif', expected one of: pdf"},
+	}
+
+	for _, tt := range tests {
+		src := fmt.Sprintf(`size(10, 10); clear(); snapshot(%q)`, tt.filename)
+		_, err := doEval([]byte(src), filepath.Join(dir, "main.g2d"), dir, "main", tt.driver, "vm", 1)
+
+		if tt.err == "" {
+			if err != nil {
+				t.Errorf("%s %s: unexpected error: %s", tt.driver, tt.filename, err)
+				continue
+			}
+			if _, err := os.Stat(filepath.Join(dir, tt.saved)); err != nil {
+				t.Errorf("%s %s: snapshot not saved: %s", tt.driver, tt.filename, err)
+			}
+			continue
+		}
+
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("%s %s: wrong error. expected=%q, got=%v", tt.driver, tt.filename, tt.err, err)
+		}
+	}
+}
+
+func TestExampleDrivers(t *testing.T) {
+	dir, err := ioutil.TempDir("", "g2d-drivers")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	script := "../_examples/star.g2d"
+	src, err := ioutil.ReadFile(script)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the example saves "star.png"
+	for driver, saved := range map[string]string{"img": "star.png", "svg": "star.svg", "pdf": "star.pdf"} {
+		if _, err := doEval(src, script, dir, "star", driver, "vm", 1); err != nil {
+			t.Errorf("%s: unexpected error: %s", driver, err)
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(dir, saved)); err != nil {
+			t.Errorf("%s: snapshot not saved: %s", driver, err)
+		}
+	}
+}
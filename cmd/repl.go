@@ -0,0 +1,350 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/lucasepe/g2d/builtins"
+	"github.com/lucasepe/g2d/lexer"
+	"github.com/lucasepe/g2d/object"
+	"github.com/lucasepe/g2d/parser"
+	"github.com/lucasepe/g2d/token"
+	"github.com/spf13/cobra"
+)
+
+const (
+	prompt       = ">> "
+	promptMore   = ".. "
+	historyFile  = ".g2d_history"
+	historyLimit = 500
+)
+
+// replCmd represents the repl command
+var replCmd = &cobra.Command{
+	DisableSuggestions:    true,
+	DisableFlagsInUseLine: true,
+	Args:                  cobra.NoArgs,
+	Use:                   "repl",
+	Short:                 "Start an interactive g2d session",
+	Example:               replCmdExample(),
+	Run: func(cmd *cobra.Command, args []string) {
+		directory, err := cmd.Flags().GetString(optDirectory)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %s\n", err.Error())
+			os.Exit(1)
+		}
+
+		driver, err := cmd.Flags().GetString(optDriver)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %s\n", err.Error())
+			os.Exit(1)
+		}
+
+		engine, err := cmd.Flags().GetString(optEngine)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %s\n", err.Error())
+			os.Exit(1)
+		}
+
+		seed, err := seedFlag(cmd)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %s\n", err.Error())
+			os.Exit(1)
+		}
+
+		r := &repl{
+			in:        os.Stdin,
+			out:       os.Stdout,
+			directory: directory,
+			driver:    driver,
+			engine:    engine,
+			seed:      seed,
+		}
+		if home, err := os.UserHomeDir(); err == nil {
+			r.historyPath = filepath.Join(home, historyFile)
+		}
+
+		fmt.Fprintf(r.out, "%s\n\nType :help for the list of commands (random seed %d)\n\n", banner, r.seed)
+		if err := r.run(); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %s\n", err.Error())
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	replCmd.Flags().StringP(optDirectory, "d", "", "snapshots destination folder (note that must exist)")
+	replCmd.Flags().String(optDriver, "img", "graphic backend: img (raster images), svg or pdf (vector documents)")
+	replCmd.Flags().Int64(optSeed, 0, "seed of the random numbers generator (default a new one, kept on :reset)")
+	replCmd.Flags().String(optEngine, "vm", "execution engine: vm (bytecode virtual machine) or eval (tree-walking evaluator)")
+
+	rootCmd.AddCommand(replCmd)
+}
+
+func replCmdExample() string {
+	tpl := `  {{APP}} repl
+  {{APP}} repl --driver svg -d /tmp/sketches`
+
+	return strings.Replace(tpl, "{{APP}}", appName(), -1)
+}
+
+// repl reads, evaluates and prints g2d statements using
+// an environment that persists across the inputs.
+type repl struct {
+	in          io.Reader
+	out         io.Writer
+	directory   string
+	driver      string
+	engine      string
+	seed        int64
+	historyPath string
+
+	env     *object.Environment
+	history []string
+}
+
+// reset creates a new environment (and a new blank canvas).
+func (r *repl) reset() error {
+	ctx, err := newGraphicContext(r.driver, 1024, 1024)
+	if err != nil {
+		return err
+	}
+
+	r.env = object.NewEnvironment(ctx,
+		object.WithOutputDir(r.directory),
+		object.WithSnapshotPrefix("repl"),
+		object.WithSeed(r.seed))
+
+	return nil
+}
+
+func (r *repl) run() error {
+	if err := r.reset(); err != nil {
+		return err
+	}
+	r.loadHistory()
+
+	scanner := bufio.NewScanner(r.in)
+
+	var buf strings.Builder
+	fmt.Fprint(r.out, prompt)
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		if buf.Len() == 0 {
+			trimmed := strings.TrimSpace(line)
+			if strings.HasPrefix(trimmed, ":") {
+				if quit := r.command(trimmed); quit {
+					return nil
+				}
+				fmt.Fprint(r.out, prompt)
+				continue
+			}
+
+			if strings.HasPrefix(trimmed, "!") {
+				src, err := r.recall(trimmed)
+				if err != nil {
+					fmt.Fprintf(r.out, "error: %s\n", err.Error())
+					fmt.Fprint(r.out, prompt)
+					continue
+				}
+				fmt.Fprintln(r.out, src)
+				line = src
+			}
+		}
+
+		buf.WriteString(line)
+		buf.WriteString("\n")
+
+		// wait for the remaining lines of the unfinished blocks
+		if openBlocks(buf.String()) > 0 {
+			fmt.Fprint(r.out, promptMore)
+			continue
+		}
+
+		src := strings.TrimSpace(buf.String())
+		buf.Reset()
+		if src != "" {
+			r.addHistory(src)
+			r.eval(src)
+		}
+		fmt.Fprint(r.out, prompt)
+	}
+	fmt.Fprintln(r.out)
+
+	return scanner.Err()
+}
+
+// eval parses and evaluates the source printing the result
+// (or the errors) to the output.
+func (r *repl) eval(src string) {
+	l := lexer.New(src)
+	p := parser.New(l)
+
+	program := p.ParseProgram()
+	if errs := p.Errors(); len(errs) > 0 {
+		for _, msg := range errs {
+			fmt.Fprintf(r.out, "error: %s\n", msg)
+		}
+		return
+	}
+
+	obj, err := run(r.engine, program, r.env, l)
+	if err != nil {
+		fmt.Fprintf(r.out, "error: %s\n", err.Error())
+		return
+	}
+
+	if obj == nil || obj.Type() == object.NULL {
+		return
+	}
+
+	if obj.Type() == object.ERROR {
+		fmt.Fprintf(r.out, "error: %s\n", obj.String())
+		return
+	}
+
+	fmt.Fprintln(r.out, obj.Inspect())
+}
+
+// command executes a meta-command, returns true to quit the session.
+func (r *repl) command(line string) bool {
+	fields := strings.Fields(line)
+
+	switch fields[0] {
+	case ":quit", ":q":
+		return true
+
+	case ":help":
+		fmt.Fprint(r.out, `:save <filename>  saves the canvas (the format is given by the extension)
+:reset            discards all the bindings and clears the canvas
+:history          lists the previous inputs, recall them with !<n> (!! for the last one)
+:quit             exits the session
+`)
+
+	case ":save":
+		if len(fields) != 2 {
+			fmt.Fprintln(r.out, "error: usage :save <filename>")
+			return false
+		}
+
+		snapshot := builtins.Builtins["snapshot"]
+		if obj := snapshot.Fn(r.env, &object.String{Value: fields[1]}); obj.Type() == object.ERROR {
+			fmt.Fprintf(r.out, "error: %s\n", obj.String())
+		}
+
+	case ":reset":
+		if err := r.reset(); err != nil {
+			fmt.Fprintf(r.out, "error: %s\n", err.Error())
+		}
+
+	case ":history":
+		for i, el := range r.history {
+			fmt.Fprintf(r.out, "%4d  %s\n", i+1, strings.Replace(el, "\n", "\n      ", -1))
+		}
+
+	default:
+		fmt.Fprintf(r.out, "error: unknown command '%s', type :help for the list of commands\n", fields[0])
+	}
+
+	return false
+}
+
+// recall returns the history entry referenced by `!n` or `!!`.
+func (r *repl) recall(line string) (string, error) {
+	if len(r.history) == 0 {
+		return "", fmt.Errorf("history is empty")
+	}
+
+	if line == "!!" {
+		return r.history[len(r.history)-1], nil
+	}
+
+	n, err := strconv.Atoi(strings.TrimPrefix(line, "!"))
+	if err != nil || n < 1 || n > len(r.history) {
+		return "", fmt.Errorf("history entry '%s' not found", line)
+	}
+
+	return r.history[n-1], nil
+}
+
+func (r *repl) addHistory(src string) {
+	r.history = append(r.history, src)
+	if len(r.history) > historyLimit {
+		r.history = r.history[len(r.history)-historyLimit:]
+	}
+
+	if r.historyPath == "" {
+		return
+	}
+
+	fp, err := os.OpenFile(r.historyPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return
+	}
+	defer fp.Close()
+
+	// multi-line entries are stored on a single line
+	fmt.Fprintln(fp, strconv.Quote(src))
+}
+
+// loadHistory reads the history file, trimming it to
+// the last entries once it has grown past the limit.
+func (r *repl) loadHistory() {
+	if r.historyPath == "" {
+		return
+	}
+
+	fp, err := os.Open(r.historyPath)
+	if err != nil {
+		return
+	}
+
+	scanner := bufio.NewScanner(fp)
+	for scanner.Scan() {
+		if src, err := strconv.Unquote(scanner.Text()); err == nil {
+			r.history = append(r.history, src)
+		}
+	}
+	fp.Close()
+
+	if len(r.history) > historyLimit {
+		r.history = r.history[len(r.history)-historyLimit:]
+		r.saveHistory()
+	}
+}
+
+// saveHistory rewrites the history file with the entries in memory.
+func (r *repl) saveHistory() {
+	var sb strings.Builder
+	for _, el := range r.history {
+		sb.WriteString(strconv.Quote(el))
+		sb.WriteString("\n")
+	}
+
+	ioutil.WriteFile(r.historyPath, []byte(sb.String()), 0600)
+}
+
+// openBlocks returns the number of the parentheses,
+// braces and brackets not yet closed in the source.
+func openBlocks(src string) int {
+	l := lexer.New(src)
+
+	depth := 0
+	for tok := l.NextToken(); tok.Type != token.EOF; tok = l.NextToken() {
+		switch tok.Type {
+		case token.LPAREN, token.LBRACE, token.LBRACKET:
+			depth++
+		case token.RPAREN, token.RBRACE, token.RBRACKET:
+			depth--
+		}
+	}
+
+	return depth
+}
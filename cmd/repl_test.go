@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestOpenBlocks(t *testing.T) {
+	tests := []struct {
+		src  string
+		want int
+	}{
+		{`x := 1`, 0},
+		{`f := fn(x) {`, 1},
+		{`if (x > 0) { while (true) {`, 2},
+		{`a := [1, 2,`, 1},
+		{`f := fn(x) { return x }`, 0},
+		{`s := "{"`, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			if got := openBlocks(tt.src); got != tt.want {
+				t.Errorf("got [%v] want [%v]", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRepl(t *testing.T) {
+	dir, err := ioutil.TempDir("", "g2d-repl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	input := strings.Join([]string{
+		`sq := fn(x) {`,
+		`    return x * x`,
+		`}`,
+		`sq(3)`,
+		`!!`,
+		`!1`,
+		`sq(2)`,
+		`nope`,
+		`:save out.png`,
+		`:reset`,
+		`sq`,
+		`:history`,
+		`:quit`,
+		`sq(4)`,
+	}, "\n")
+
+	var out bytes.Buffer
+	r := &repl{in: strings.NewReader(input), out: &out, directory: dir}
+	if err := r.run(); err != nil {
+		t.Fatal(err)
+	}
+
+	got := out.String()
+	for _, want := range []string{
+		"9\n>> sq(3)\n9\n",
+		"4\n",
+		"error: identifier `nope` not found\n    * line: 1\tnope",
+		"error: identifier `sq` not found",
+		"   3  sq(3)\n",
+		"   1  sq := fn(x) {\n          return x * x\n      }\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output does not contain %q\n%s", want, got)
+		}
+	}
+
+	if strings.Contains(got, "16") {
+		t.Errorf("input evaluated after :quit\n%s", got)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "out.png")); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestReplHistoryFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "g2d-repl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// a file grown past the limit by the previous sessions
+	var sb strings.Builder
+	for i := 0; i < historyLimit+20; i++ {
+		fmt.Fprintf(&sb, "%q\n", fmt.Sprintf("x := %d", i))
+	}
+	path := filepath.Join(dir, historyFile)
+	if err := ioutil.WriteFile(path, []byte(sb.String()), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	r := &repl{in: strings.NewReader("x\n:quit\n"), out: &out, directory: dir, historyPath: path}
+	if err := r.run(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != historyLimit+1 {
+		t.Fatalf("wrong number of history entries. expected=%d, got=%d", historyLimit+1, len(lines))
+	}
+	if lines[0] != `"x := 20"` || lines[len(lines)-1] != `"x"` {
+		t.Errorf("wrong history entries: first=%s, last=%s", lines[0], lines[len(lines)-1])
+	}
+
+	if n := len(r.history); n != historyLimit {
+		t.Errorf("wrong number of entries in memory. expected=%d, got=%d", historyLimit, n)
+	}
+}
@@ -0,0 +1,300 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/lucasepe/g2d/gg"
+	"github.com/lucasepe/g2d/object"
+	"github.com/spf13/cobra"
+)
+
+const (
+	optAddr = "addr"
+)
+
+// serveCmd represents the serve command
+var serveCmd = &cobra.Command{
+	DisableSuggestions:    true,
+	DisableFlagsInUseLine: true,
+	Args:                  cobra.ExactArgs(1),
+	Use:                   "serve <script PATH>",
+	Short:                 "Preview a g2d script in the browser, refreshing it on every change",
+	Example:               serveCmdExample(),
+	Run: func(cmd *cobra.Command, args []string) {
+		addr, err := cmd.Flags().GetString(optAddr)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %s\n", err.Error())
+			os.Exit(1)
+		}
+
+		directory, err := cmd.Flags().GetString(optDirectory)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %s\n", err.Error())
+			os.Exit(1)
+		}
+
+		driver, err := cmd.Flags().GetString(optDriver)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %s\n", err.Error())
+			os.Exit(1)
+		}
+
+		if driver == "pdf" {
+			fmt.Fprintf(os.Stderr, "error: serve supports only the img and svg drivers\n")
+			os.Exit(1)
+		}
+
+		interval, err := cmd.Flags().GetDuration(optInterval)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %s\n", err.Error())
+			os.Exit(1)
+		}
+
+		engine, err := cmd.Flags().GetString(optEngine)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %s\n", err.Error())
+			os.Exit(1)
+		}
+
+		seed, err := seedFlag(cmd)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %s\n", err.Error())
+			os.Exit(1)
+		}
+
+		if isRemote(args[0]) {
+			fmt.Fprintf(os.Stderr, "error: serve works only with local scripts\n")
+			os.Exit(1)
+		}
+
+		prefix, err := lastPathSegment(args[0])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %s\n", err.Error())
+			os.Exit(1)
+		}
+
+		pv := newPreview(filepath.Base(args[0]))
+
+		w := &watcher{
+			script:    args[0],
+			directory: directory,
+			prefix:    prefix,
+			driver:    driver,
+			engine:    engine,
+			seed:      seed,
+			out:       os.Stdout,
+			errOut:    os.Stderr,
+			onEval:    pv.update,
+		}
+		go w.run(interval, nil)
+
+		fmt.Fprintf(os.Stdout, "serving %s at %s, press Ctrl+C to stop\n", args[0], previewURL(addr))
+		if err := http.ListenAndServe(addr, pv); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %s\n", err.Error())
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	serveCmd.Flags().String(optAddr, "localhost:8080", "address of the HTTP server")
+	serveCmd.Flags().StringP(optDirectory, "d", "", "snapshots destination folder (note that must exist)")
+	serveCmd.Flags().String(optDriver, "img", "graphic backend: img (raster images) or svg (vector documents)")
+	serveCmd.Flags().String(optEngine, "vm", "execution engine: vm (bytecode virtual machine) or eval (tree-walking evaluator)")
+	serveCmd.Flags().Int64(optSeed, 0, "seed of the random numbers generator (default a new one, kept for all the runs)")
+	serveCmd.Flags().Duration(optInterval, defaultInterval, "polling interval")
+
+	rootCmd.AddCommand(serveCmd)
+}
+
+func serveCmdExample() string {
+	tpl := `  {{APP}} serve /path/to/my_script.g2d
+  {{APP}} serve --addr :9000 --driver svg /path/to/my_script.g2d`
+
+	return strings.Replace(tpl, "{{APP}}", appName(), -1)
+}
+
+func previewURL(addr string) string {
+	if strings.HasPrefix(addr, ":") {
+		addr = "localhost" + addr
+	}
+	return "http://" + addr + "/"
+}
+
+// preview serves the last render of a script in a web page that
+// reloads itself, through server-sent events, on every evaluation.
+type preview struct {
+	title string
+	mux   *http.ServeMux
+
+	mu          sync.Mutex
+	version     int
+	data        []byte
+	contentType string
+	err         string
+	clients     map[chan int]struct{}
+}
+
+func newPreview(title string) *preview {
+	pv := &preview{
+		title:   title,
+		mux:     http.NewServeMux(),
+		clients: make(map[chan int]struct{}),
+	}
+
+	pv.mux.HandleFunc("/", pv.handlePage)
+	pv.mux.HandleFunc("/render", pv.handleRender)
+	pv.mux.HandleFunc("/events", pv.handleEvents)
+
+	return pv
+}
+
+func (pv *preview) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	pv.mux.ServeHTTP(w, r)
+}
+
+// update encodes the final state of the canvas and notifies the clients;
+// on errors the last successful render is kept.
+func (pv *preview) update(env *object.Environment, err error) {
+	var buf bytes.Buffer
+	var contentType string
+
+	if err == nil {
+		contentType, err = encodePreview(&buf, env.GraphicContext())
+	}
+
+	pv.mu.Lock()
+	defer pv.mu.Unlock()
+
+	pv.version = pv.version + 1
+	if err != nil {
+		pv.err = err.Error()
+	} else {
+		pv.err = ""
+		pv.data = buf.Bytes()
+		pv.contentType = contentType
+	}
+
+	for ch := range pv.clients {
+		select {
+		case ch <- pv.version:
+		default:
+			// the client is still busy with a previous notification
+		}
+	}
+}
+
+// encodePreview encodes the canvas using the default format
+// of the graphic context and returns the content type.
+func encodePreview(buf *bytes.Buffer, dc gg.GraphicContext) (string, error) {
+	enc, ok := dc.(gg.Encoder)
+	if !ok {
+		return "", fmt.Errorf("graphic context of type %T can't be encoded", dc)
+	}
+
+	format := enc.Formats()[0]
+	if err := enc.Encode(buf, format); err != nil {
+		return "", err
+	}
+
+	return mime.TypeByExtension("." + format), nil
+}
+
+var pageTemplate = template.Must(template.New("page").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { margin: 0; background: #333; font-family: monospace; }
+img { display: block; margin: 1em auto; max-width: 95vw; max-height: 90vh; background: #fff; }
+pre { margin: 1em; padding: 1em; color: #fff; background: #b00; white-space: pre-wrap; }
+</style>
+</head>
+<body>
+{{if .Error}}<pre>{{.Error}}</pre>{{end}}
+{{if .HasImage}}<img src="/render?v={{.Version}}" alt="{{.Title}}">{{end}}
+<script>
+new EventSource("/events").onmessage = function() { location.reload(); };
+</script>
+</body>
+</html>
+`))
+
+func (pv *preview) handlePage(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+
+	pv.mu.Lock()
+	data := struct {
+		Title    string
+		Error    string
+		HasImage bool
+		Version  int
+	}{pv.title, pv.err, len(pv.data) > 0, pv.version}
+	pv.mu.Unlock()
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	pageTemplate.Execute(w, data)
+}
+
+func (pv *preview) handleRender(w http.ResponseWriter, r *http.Request) {
+	pv.mu.Lock()
+	data, contentType := pv.data, pv.contentType
+	pv.mu.Unlock()
+
+	if len(data) == 0 {
+		http.Error(w, "nothing rendered yet", http.StatusServiceUnavailable)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Cache-Control", "no-store")
+	w.Write(data)
+}
+
+func (pv *preview) handleEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	ch := make(chan int, 1)
+	pv.mu.Lock()
+	pv.clients[ch] = struct{}{}
+	pv.mu.Unlock()
+
+	defer func() {
+		pv.mu.Lock()
+		delete(pv.clients, ch)
+		pv.mu.Unlock()
+	}()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case v := <-ch:
+			fmt.Fprintf(w, "data: %d\n\n", v)
+			flusher.Flush()
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPreview(t *testing.T) {
+	pv := newPreview("test.g2d")
+	ts := httptest.NewServer(pv)
+	defer ts.Close()
+
+	res, err := http.Get(ts.URL + "/render")
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("got status [%v] want [%v]", res.StatusCode, http.StatusServiceUnavailable)
+	}
+
+	events, err := http.Get(ts.URL + "/events")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer events.Body.Close()
+	stream := bufio.NewReader(events.Body)
+
+	// wait for the client to be registered
+	for i := 0; i < 100; i++ {
+		pv.mu.Lock()
+		n := len(pv.clients)
+		pv.mu.Unlock()
+		if n > 0 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	for _, driver := range []string{"img", "svg"} {
+		env, err := doEval([]byte(`circle(10, 10, 5); fill()`), "", "", "test", driver, "vm", 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pv.update(env, nil)
+
+		line, err := stream.ReadString('\n')
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasPrefix(line, "data: ") {
+			t.Errorf("unexpected event [%v]", line)
+		}
+		// each event ends with a blank line
+		if _, err := stream.ReadString('\n'); err != nil {
+			t.Fatal(err)
+		}
+
+		res, err := http.Get(ts.URL + "/render")
+		if err != nil {
+			t.Fatal(err)
+		}
+		res.Body.Close()
+
+		want := map[string]string{"img": "image/png", "svg": "image/svg+xml"}[driver]
+		if got := res.Header.Get("Content-Type"); got != want {
+			t.Errorf("got content type [%v] want [%v]", got, want)
+		}
+	}
+
+	// on errors the last render is kept and the error is shown
+	pv.update(nil, errors.New("identifier `nope` not found"))
+
+	res, err = http.Get(ts.URL + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+
+	page, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"identifier `nope` not found", `<img src="/render?v=3"`, `new EventSource("/events")`} {
+		if !strings.Contains(string(page), want) {
+			t.Errorf("page does not contain %q\n%s", want, page)
+		}
+	}
+}
@@ -0,0 +1,227 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/lucasepe/g2d/data"
+	"github.com/lucasepe/g2d/object"
+	"github.com/spf13/cobra"
+)
+
+const defaultInterval = 500 * time.Millisecond
+
+// watchCmd represents the watch command
+var watchCmd = &cobra.Command{
+	DisableSuggestions:    true,
+	DisableFlagsInUseLine: true,
+	Args:                  cobra.ExactArgs(1),
+	Use:                   "watch <script PATH>",
+	Short:                 "Evaluate a g2d script each time it changes",
+	Example:               watchCmdExample(),
+	Run:                   runWatch,
+}
+
+func init() {
+	watchCmd.Flags().StringP(optDirectory, "d", "", "snapshots destination folder (note that must exist)")
+	watchCmd.Flags().String(optDriver, "img", "graphic backend: img (raster images), svg or pdf (vector documents)")
+	watchCmd.Flags().String(optEngine, "vm", "execution engine: vm (bytecode virtual machine) or eval (tree-walking evaluator)")
+	watchCmd.Flags().Int64(optSeed, 0, "seed of the random numbers generator (default a new one, kept for all the runs)")
+	watchCmd.Flags().Duration(optInterval, defaultInterval, "polling interval")
+
+	rootCmd.AddCommand(watchCmd)
+}
+
+func watchCmdExample() string {
+	tpl := `  {{APP}} watch /path/to/my_script.g2d
+  {{APP}} watch --interval 2s -d /tmp/out /path/to/my_script.g2d`
+
+	return strings.Replace(tpl, "{{APP}}", appName(), -1)
+}
+
+// runWatch evaluates the script and keeps polling it, and all the
+// files it loads, evaluating it again on every change.
+func runWatch(cmd *cobra.Command, args []string) {
+	directory, err := cmd.Flags().GetString(optDirectory)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %s\n", err.Error())
+		os.Exit(1)
+	}
+
+	driver, err := cmd.Flags().GetString(optDriver)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %s\n", err.Error())
+		os.Exit(1)
+	}
+
+	interval, err := cmd.Flags().GetDuration(optInterval)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %s\n", err.Error())
+		os.Exit(1)
+	}
+
+	engine, err := cmd.Flags().GetString(optEngine)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %s\n", err.Error())
+		os.Exit(1)
+	}
+
+	seed, err := seedFlag(cmd)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %s\n", err.Error())
+		os.Exit(1)
+	}
+
+	if isRemote(args[0]) {
+		fmt.Fprintf(os.Stderr, "error: watch mode works only with local scripts\n")
+		os.Exit(1)
+	}
+
+	prefix, err := lastPathSegment(args[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %s\n", err.Error())
+		os.Exit(1)
+	}
+
+	w := &watcher{
+		script:    args[0],
+		directory: directory,
+		prefix:    prefix,
+		driver:    driver,
+		engine:    engine,
+		seed:      seed,
+		out:       os.Stdout,
+		errOut:    os.Stderr,
+	}
+
+	fmt.Fprintf(w.out, "watching %s, press Ctrl+C to stop\n", w.script)
+	w.run(interval, nil)
+}
+
+// stamp identifies a version of a file.
+type stamp struct {
+	exists  bool
+	size    int64
+	modTime time.Time
+}
+
+func newStamp(filename string) stamp {
+	fi, err := os.Stat(filename)
+	if err != nil {
+		return stamp{}
+	}
+
+	return stamp{exists: true, size: fi.Size(), modTime: fi.ModTime()}
+}
+
+// watcher evaluates a script each time it, or one of the
+// files loaded during the last evaluation, changes.
+type watcher struct {
+	script    string
+	directory string
+	prefix    string
+	driver    string
+	engine    string
+	seed      int64
+	out       io.Writer
+	errOut    io.Writer
+
+	// onEval, if not nil, is called after each evaluation
+	// with the resulting environment (nil if none)
+	onEval func(env *object.Environment, err error)
+
+	stamps map[string]stamp
+
+	// deps holds the files loaded during the last evaluation,
+	// still watched when the next one fails without an environment
+	deps []string
+}
+
+// run evaluates the script, then polls the files at the specified
+// interval until the stop channel is closed (nil runs forever).
+func (w *watcher) run(interval time.Duration, stop <-chan struct{}) {
+	w.render()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			if w.changed() {
+				w.render()
+			}
+		}
+	}
+}
+
+// render evaluates the script with a fresh environment and takes
+// note of the files to watch; errors are printed, never fatal.
+func (w *watcher) render() {
+	files := []string{w.script}
+	defer func() { w.track(append(files, w.deps...)) }()
+
+	src, err := data.Fetch(w.script, limit)
+	if err != nil {
+		fmt.Fprintf(w.errOut, "error: %s\n", err.Error())
+		if w.onEval != nil {
+			w.onEval(nil, err)
+		}
+		return
+	}
+
+	env, err := w.eval(src)
+	if env != nil {
+		w.deps = env.Dependencies()
+	}
+	if w.onEval != nil {
+		w.onEval(env, err)
+	}
+	if err != nil {
+		fmt.Fprintf(w.errOut, "error: %s\n", err.Error())
+		return
+	}
+
+	fmt.Fprintf(w.out, "[%s] %s evaluated (seed %d)\n", time.Now().Format("15:04:05"), w.script, w.seed)
+}
+
+// eval evaluates the source of the script; a panic of the evaluator
+// is returned as an error, so that it doesn't stop the watcher.
+func (w *watcher) eval(src []byte) (env *object.Environment, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			env, err = nil, fmt.Errorf("RuntimeError: %v", r)
+		}
+	}()
+
+	return doEval(src, w.script, w.directory, w.prefix, w.driver, w.engine, w.seed)
+}
+
+// track records the current stamps of the local files.
+func (w *watcher) track(files []string) {
+	w.stamps = make(map[string]stamp, len(files))
+	for _, el := range files {
+		if !isRemote(el) {
+			w.stamps[el] = newStamp(el)
+		}
+	}
+}
+
+// changed returns true if any of the tracked files changed.
+func (w *watcher) changed() bool {
+	for k, v := range w.stamps {
+		if newStamp(k) != v {
+			return true
+		}
+	}
+	return false
+}
+
+func isRemote(uri string) bool {
+	return strings.HasPrefix(uri, "http")
+}
@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lucasepe/g2d/object"
+)
+
+func TestWatcher(t *testing.T) {
+	dir, err := ioutil.TempDir("", "g2d-watch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	script := filepath.Join(dir, "main.g2d")
+	lib := filepath.Join(dir, "lib.g2d")
+
+	write := func(filename, src string, mod time.Time) {
+		if err := ioutil.WriteFile(filename, []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(filename, mod, mod); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	now := time.Now()
+	write(lib, `side := 10`, now)
+	write(script, `import "lib.g2d"; snapshot("out.png")`, now)
+
+	var out, errOut bytes.Buffer
+	w := &watcher{script: script, directory: dir, prefix: "main", out: &out, errOut: &errOut}
+
+	w.render()
+	if errOut.Len() > 0 {
+		t.Fatalf("unexpected error: %s", errOut.String())
+	}
+	if _, err := os.Stat(filepath.Join(dir, "out.png")); err != nil {
+		t.Fatal(err)
+	}
+	if w.changed() {
+		t.Fatal("nothing changed yet")
+	}
+
+	// a change of an imported module must be detected
+	write(lib, `side := )`, now.Add(time.Second))
+	if !w.changed() {
+		t.Fatal("module change not detected")
+	}
+
+	// errors are reported, the files are still tracked
+	w.render()
+	if !strings.Contains(errOut.String(), "ImportError") {
+		t.Fatalf("expected an import error, got: %q", errOut.String())
+	}
+	if w.changed() {
+		t.Fatal("nothing changed after the last evaluation")
+	}
+
+	write(lib, `side := 20`, now.Add(2*time.Second))
+	if !w.changed() {
+		t.Fatal("module fix not detected")
+	}
+}
+
+func TestWatcherPanic(t *testing.T) {
+	dir, err := ioutil.TempDir("", "g2d-watch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	script := filepath.Join(dir, "main.g2d")
+	lib := filepath.Join(dir, "lib.g2d")
+
+	write := func(filename, src string, mod time.Time) {
+		if err := ioutil.WriteFile(filename, []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(filename, mod, mod); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	now := time.Now()
+	write(lib, `side := 10`, now)
+	write(script, `import "lib.g2d"; size(lib.side); snapshot("out.png")`, now)
+
+	var out, errOut bytes.Buffer
+	var evalErr error
+	w := &watcher{script: script, directory: dir, prefix: "main", out: &out, errOut: &errOut}
+	w.onEval = func(env *object.Environment, err error) { evalErr = err }
+
+	w.render()
+	if evalErr != nil || errOut.Len() > 0 {
+		t.Fatalf("unexpected error: %s", errOut.String())
+	}
+
+	// a panic of the evaluator is reported like an error
+	write(script, `import "lib.g2d"; size(4611686018427387904, 4)`, now.Add(time.Second))
+	w.render()
+	if evalErr == nil || !strings.Contains(errOut.String(), "error: RuntimeError: ") {
+		t.Fatalf("expected a runtime error, got: %q", errOut.String())
+	}
+
+	// the files loaded by the last evaluation are still watched
+	if w.changed() {
+		t.Fatal("nothing changed after the last evaluation")
+	}
+	write(lib, `side := 20`, now.Add(2*time.Second))
+	if !w.changed() {
+		t.Fatal("module change not detected after a panic")
+	}
+
+	// and the next evaluation works
+	write(script, `import "lib.g2d"; size(lib.side); snapshot("out.png")`, now.Add(3*time.Second))
+	errOut.Reset()
+	w.render()
+	if evalErr != nil || errOut.Len() > 0 {
+		t.Fatalf("unexpected error: %s", errOut.String())
+	}
+}
@@ -0,0 +1,262 @@
+package code
+
+// Package code defines the bytecode instructions executed by the virtual
+// machine: each instruction is an opcode byte followed by its operands
+// encoded as big endian unsigned integers.
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+)
+
+// Instructions is a sequence of encoded instructions
+type Instructions []byte
+
+// String returns the disassembled instructions, one per line
+func (ins Instructions) String() string {
+	var out bytes.Buffer
+
+	i := 0
+	for i < len(ins) {
+		def, err := Lookup(ins[i])
+		if err != nil {
+			fmt.Fprintf(&out, "ERROR: %s\n", err)
+			i++
+			continue
+		}
+
+		operands, read := ReadOperands(def, ins[i+1:])
+		fmt.Fprintf(&out, "%04d %s\n", i, fmtInstruction(def, operands))
+
+		i += 1 + read
+	}
+
+	return out.String()
+}
+
+func fmtInstruction(def *Definition, operands []int) string {
+	if len(operands) != len(def.OperandWidths) {
+		return fmt.Sprintf("ERROR: operand len %d does not match defined %d",
+			len(operands), len(def.OperandWidths))
+	}
+
+	var out bytes.Buffer
+	out.WriteString(def.Name)
+	for _, el := range operands {
+		fmt.Fprintf(&out, " %d", el)
+	}
+	return out.String()
+}
+
+// Opcode is the first byte of an instruction
+type Opcode byte
+
+const (
+	// OpConstant pushes the constant at the operand index
+	OpConstant Opcode = iota
+	// OpPop discards the value on top of the stack
+	OpPop
+	// OpTrue pushes true
+	OpTrue
+	// OpFalse pushes false
+	OpFalse
+	// OpNull pushes null
+	OpNull
+
+	// OpInfix applies the infix operator (the operand is the index of
+	// the operator in the names) to the two values on top of the stack
+	OpInfix
+	// OpPrefix applies the prefix operator (the operand is the index
+	// of the operator in the names) to the value on top of the stack
+	OpPrefix
+
+	// OpJump jumps to the operand offset
+	OpJump
+	// OpJumpNotTruthy pops the value on top of the stack
+	// and jumps to the operand offset if it is not truthy
+	OpJumpNotTruthy
+
+	// OpGetName pushes the value bound to the name (the operand
+	// is the index of the name in the names) in the environment
+	OpGetName
+	// OpBindName binds the value on top of the stack to
+	// the name in the environment, cloning immutable values
+	OpBindName
+	// OpAssignName binds the value on top of the stack to the name in the environment
+	OpAssignName
+
+	// OpGetVar pushes the value of the variable at the operand index,
+	// looking for it in the local scopes first, then in the environment
+	OpGetVar
+	// OpBindLocal stores the value on top of the stack in the
+	// local slot at the operand index, cloning immutable values
+	OpBindLocal
+	// OpAssignLocal stores the value on top of the stack in the local slot at the operand index
+	OpAssignLocal
+
+	// OpArray pushes an array made of the operand number of values on top of the stack
+	OpArray
+	// OpHash pushes an hash made of the operand number of key/value pairs on top of the stack
+	OpHash
+	// OpIndex pushes the item of the value at the index on top of the stack
+	OpIndex
+	// OpSetIndex sets the item of a value at an index, both on top of the stack
+	OpSetIndex
+
+	// OpClosure pushes a closure of the compiled function at the operand index
+	OpClosure
+	// OpCall calls the function below the operand number of arguments
+	OpCall
+	// OpReturnValue returns the value on top of the stack to the caller
+	OpReturnValue
+
+	// OpLoop marks the stack pointer at the beginning of a loop
+	OpLoop
+	// OpUnwind restores the stack pointer marked by the innermost loop
+	OpUnwind
+	// OpLoopEnd discards the innermost loop mark and the operand
+	// number of values below the one on top of the stack
+	OpLoopEnd
+	// OpIter replaces the value on top of the stack with an iterator of its items
+	OpIter
+	// OpIterNext pushes the next item of the iterator below the value on top
+	// of the stack, when there are no more items it jumps to the operand offset
+	OpIterNext
+	// OpCase pops the value of a case and pushes true if it selects the
+	// value of the switch (below the top of the stack)
+	OpCase
+
+	// OpImport pushes the module of the import statement at the operand index
+	OpImport
+	// OpRaise stops the execution with the error message (the operand is
+	// the index of the message in the names)
+	OpRaise
+)
+
+// Definition holds the name and the width (in bytes) of the operands of an opcode
+type Definition struct {
+	Name          string
+	OperandWidths []int
+}
+
+var definitions = map[Opcode]*Definition{
+	OpConstant:      {"OpConstant", []int{2}},
+	OpPop:           {"OpPop", []int{}},
+	OpTrue:          {"OpTrue", []int{}},
+	OpFalse:         {"OpFalse", []int{}},
+	OpNull:          {"OpNull", []int{}},
+	OpInfix:         {"OpInfix", []int{2}},
+	OpPrefix:        {"OpPrefix", []int{2}},
+	OpJump:          {"OpJump", []int{4}},
+	OpJumpNotTruthy: {"OpJumpNotTruthy", []int{4}},
+	OpGetName:       {"OpGetName", []int{2}},
+	OpBindName:      {"OpBindName", []int{2}},
+	OpAssignName:    {"OpAssignName", []int{2}},
+	OpGetVar:        {"OpGetVar", []int{2}},
+	OpBindLocal:     {"OpBindLocal", []int{2}},
+	OpAssignLocal:   {"OpAssignLocal", []int{2}},
+	OpArray:         {"OpArray", []int{2}},
+	OpHash:          {"OpHash", []int{2}},
+	OpIndex:         {"OpIndex", []int{}},
+	OpSetIndex:      {"OpSetIndex", []int{}},
+	OpClosure:       {"OpClosure", []int{2}},
+	OpCall:          {"OpCall", []int{1}},
+	OpReturnValue:   {"OpReturnValue", []int{}},
+	OpLoop:          {"OpLoop", []int{}},
+	OpUnwind:        {"OpUnwind", []int{}},
+	OpLoopEnd:       {"OpLoopEnd", []int{1}},
+	OpIter:          {"OpIter", []int{}},
+	OpIterNext:      {"OpIterNext", []int{4}},
+	OpCase:          {"OpCase", []int{}},
+	OpImport:        {"OpImport", []int{2}},
+	OpRaise:         {"OpRaise", []int{2}},
+}
+
+// Lookup returns the definition of the opcode
+func Lookup(op byte) (*Definition, error) {
+	def, ok := definitions[Opcode(op)]
+	if !ok {
+		return nil, fmt.Errorf("opcode %d undefined", op)
+	}
+
+	return def, nil
+}
+
+// MaxOperand returns the maximum value of an operand of the specified width
+func MaxOperand(width int) int {
+	switch width {
+	case 1:
+		return 0xFF
+	case 2:
+		return 0xFFFF
+	}
+	return 0x7FFFFFFF
+}
+
+// Make encodes the instruction made of the opcode and the operands
+func Make(op Opcode, operands ...int) []byte {
+	def, ok := definitions[op]
+	if !ok {
+		return []byte{}
+	}
+
+	instructionLen := 1
+	for _, w := range def.OperandWidths {
+		instructionLen += w
+	}
+
+	instruction := make([]byte, instructionLen)
+	instruction[0] = byte(op)
+
+	offset := 1
+	for i, o := range operands {
+		width := def.OperandWidths[i]
+		switch width {
+		case 1:
+			instruction[offset] = byte(o)
+		case 2:
+			binary.BigEndian.PutUint16(instruction[offset:], uint16(o))
+		case 4:
+			binary.BigEndian.PutUint32(instruction[offset:], uint32(o))
+		}
+		offset += width
+	}
+
+	return instruction
+}
+
+// ReadOperands decodes the operands of the instruction,
+// returns them and the number of bytes read
+func ReadOperands(def *Definition, ins Instructions) ([]int, int) {
+	operands := make([]int, len(def.OperandWidths))
+	offset := 0
+
+	for i, width := range def.OperandWidths {
+		switch width {
+		case 1:
+			operands[i] = int(ReadUint8(ins[offset:]))
+		case 2:
+			operands[i] = int(ReadUint16(ins[offset:]))
+		case 4:
+			operands[i] = int(ReadUint32(ins[offset:]))
+		}
+
+		offset += width
+	}
+
+	return operands, offset
+}
+
+// ReadUint8 decodes a 1 byte operand
+func ReadUint8(ins Instructions) uint8 { return uint8(ins[0]) }
+
+// ReadUint16 decodes a 2 bytes operand
+func ReadUint16(ins Instructions) uint16 {
+	return binary.BigEndian.Uint16(ins)
+}
+
+// ReadUint32 decodes a 4 bytes operand
+func ReadUint32(ins Instructions) uint32 {
+	return binary.BigEndian.Uint32(ins)
+}
@@ -0,0 +1,623 @@
+package compiler
+
+// Package compiler translates the parsed AST (abstract syntax tree) into the
+// bytecode executed by the virtual machine (see the vm package).
+//
+// The top level names of a program are bound in the environment, exactly
+// like the evaluator does, while the names bound by a function (parameters,
+// `:=`, `=`, `for` variables and imports) are stored in the slots of the
+// function call scope, so that calls don't need new environments.
+
+import (
+	"fmt"
+
+	"github.com/lucasepe/g2d/ast"
+	"github.com/lucasepe/g2d/code"
+	"github.com/lucasepe/g2d/eval"
+	"github.com/lucasepe/g2d/object"
+	"github.com/lucasepe/g2d/token"
+)
+
+// loop holds the jump targets of a loop being compiled
+type loop struct {
+	start  int
+	breaks []int
+}
+
+// scope holds the state of the function being compiled
+type scope struct {
+	fn     *object.CompiledFunction
+	parent *scope
+
+	// locals maps the names bound by the function to their slots,
+	// it's nil for the top level program
+	locals map[string]int
+	params map[string]bool
+	names  map[string]int
+	loops  []*loop
+}
+
+// Compiler compiles a program into the bytecode of its main function
+type Compiler struct {
+	scope *scope
+}
+
+// Compile compiles the program, returns its main function
+func Compile(program *ast.Program) (*object.CompiledFunction, error) {
+	c := &Compiler{}
+	c.enterScope(nil, nil)
+
+	if err := c.compileStatements(program.Statements); err != nil {
+		return nil, err
+	}
+	c.emit(code.OpReturnValue)
+
+	return c.leaveScope(), nil
+}
+
+func (c *Compiler) enterScope(params []*ast.Identifier, body *ast.BlockStatement) {
+	s := &scope{
+		fn: &object.CompiledFunction{
+			Parameters: params,
+			Body:       body,
+			Tokens:     make(map[int]token.Token),
+		},
+		parent: c.scope,
+		names:  make(map[string]int),
+	}
+
+	if body != nil {
+		s.locals = make(map[string]int)
+		s.params = make(map[string]bool)
+
+		declare := func(name string) int {
+			if object.IsReserved(name) {
+				// binding them fails at run time as in the evaluator
+				return -1
+			}
+			if idx, ok := s.locals[name]; ok {
+				return idx
+			}
+			s.locals[name] = len(s.locals)
+			return s.locals[name]
+		}
+
+		for _, p := range params {
+			s.fn.ParameterSlots = append(s.fn.ParameterSlots, declare(p.Value))
+			s.params[p.Value] = true
+		}
+		declarations(body, func(name string) { declare(name) })
+
+		s.fn.NumLocals = len(s.locals)
+	}
+
+	c.scope = s
+}
+
+func (c *Compiler) leaveScope() *object.CompiledFunction {
+	fn := c.scope.fn
+	c.scope = c.scope.parent
+	return fn
+}
+
+func (c *Compiler) emit(op code.Opcode, operands ...int) int {
+	pos := len(c.scope.fn.Instructions)
+	c.scope.fn.Instructions = append(c.scope.fn.Instructions, code.Make(op, operands...)...)
+	return pos
+}
+
+// emitAt emits an instruction that can fail, the token locates the error
+func (c *Compiler) emitAt(tok token.Token, op code.Opcode, operands ...int) int {
+	pos := c.emit(op, operands...)
+	c.scope.fn.Tokens[pos] = tok
+	return pos
+}
+
+// changeOperand replaces the operand of the instruction at the specified position
+func (c *Compiler) changeOperand(pos int, operand int) {
+	op := code.Opcode(c.scope.fn.Instructions[pos])
+	copy(c.scope.fn.Instructions[pos:], code.Make(op, operand))
+}
+
+func (c *Compiler) addConstant(obj object.Object) (int, error) {
+	c.scope.fn.Constants = append(c.scope.fn.Constants, obj)
+	return index(len(c.scope.fn.Constants)-1, "constants")
+}
+
+func (c *Compiler) addName(name string) (int, error) {
+	if idx, ok := c.scope.names[name]; ok {
+		return idx, nil
+	}
+
+	c.scope.fn.Names = append(c.scope.fn.Names, name)
+	c.scope.names[name] = len(c.scope.fn.Names) - 1
+	return index(c.scope.names[name], "names")
+}
+
+func index(idx int, what string) (int, error) {
+	if idx > code.MaxOperand(2) {
+		return 0, fmt.Errorf("too many %s in a single function", what)
+	}
+	return idx, nil
+}
+
+func (c *Compiler) compileStatements(stmts []ast.Statement) error {
+	if len(stmts) == 0 {
+		c.emit(code.OpNull)
+		return nil
+	}
+
+	for i, el := range stmts {
+		if err := c.compile(el); err != nil {
+			return err
+		}
+
+		// the value of the last statement is the value of the block
+		if i < len(stmts)-1 {
+			c.emit(code.OpPop)
+		}
+	}
+
+	return nil
+}
+
+func (c *Compiler) compile(node ast.Node) error {
+	switch node := node.(type) {
+
+	// Statements
+	case *ast.ExpressionStatement:
+		if node.Expression == nil {
+			c.emit(code.OpNull)
+			return nil
+		}
+		return c.compile(node.Expression)
+
+	case *ast.BlockStatement:
+		return c.compileStatements(node.Statements)
+
+	case *ast.ReturnStatement:
+		if node.ReturnValue == nil {
+			c.emit(code.OpNull)
+		} else if err := c.compile(node.ReturnValue); err != nil {
+			return err
+		}
+		c.emit(code.OpReturnValue)
+
+	case *ast.BreakStatement:
+		return c.compileLoopJump(node.Token, true)
+
+	case *ast.ContinueStatement:
+		return c.compileLoopJump(node.Token, false)
+
+	case *ast.ImportStatement:
+		c.scope.fn.Imports = append(c.scope.fn.Imports, node)
+		idx, err := index(len(c.scope.fn.Imports)-1, "imports")
+		if err != nil {
+			return err
+		}
+		c.emitAt(node.Token, code.OpImport, idx)
+
+		if err := c.store(node.Token, eval.ImportName(node), false); err != nil {
+			return err
+		}
+		c.emit(code.OpNull)
+
+	// Expressions
+	case *ast.IntegerLiteral:
+		return c.compileConstant(&object.Integer{Value: node.Value})
+	case *ast.FloatLiteral:
+		return c.compileConstant(&object.Float{Value: node.Value})
+	case *ast.StringLiteral:
+		return c.compileConstant(&object.String{Value: node.Value})
+	case *ast.Boolean:
+		if node.Value {
+			c.emit(code.OpTrue)
+		} else {
+			c.emit(code.OpFalse)
+		}
+	case *ast.Null:
+		c.emit(code.OpNull)
+
+	case *ast.PrefixExpression:
+		if err := c.compile(node.Right); err != nil {
+			return err
+		}
+		idx, err := c.addName(node.Operator)
+		if err != nil {
+			return err
+		}
+		c.emitAt(node.Token, code.OpPrefix, idx)
+
+	case *ast.InfixExpression:
+		if err := c.compile(node.Left); err != nil {
+			return err
+		}
+		if err := c.compile(node.Right); err != nil {
+			return err
+		}
+		idx, err := c.addName(node.Operator)
+		if err != nil {
+			return err
+		}
+		c.emitAt(node.Token, code.OpInfix, idx)
+
+	case *ast.IfExpression:
+		return c.compileIfExpression(node)
+	case *ast.WhileExpression:
+		return c.compileWhileExpression(node)
+	case *ast.ForInExpression:
+		return c.compileForInExpression(node)
+	case *ast.Identifier:
+		return c.load(node.Token, node.Value)
+
+	case *ast.FunctionLiteral:
+		c.enterScope(node.Parameters, node.Body)
+		if err := c.compileStatements(node.Body.Statements); err != nil {
+			return err
+		}
+		c.emit(code.OpReturnValue)
+		fn := c.leaveScope()
+
+		idx, err := c.addConstant(fn)
+		if err != nil {
+			return err
+		}
+		c.emit(code.OpClosure, idx)
+
+	case *ast.CallExpression:
+		if len(node.Arguments) > code.MaxOperand(1) {
+			return fmt.Errorf("too many arguments in function call")
+		}
+
+		if err := c.compile(node.Function); err != nil {
+			return err
+		}
+		for _, el := range node.Arguments {
+			if err := c.compile(el); err != nil {
+				return err
+			}
+		}
+		c.emitAt(node.Token, code.OpCall, len(node.Arguments))
+
+	case *ast.ArrayLiteral:
+		if len(node.Elements) > code.MaxOperand(2) {
+			return fmt.Errorf("too many elements in array literal")
+		}
+
+		for _, el := range node.Elements {
+			if err := c.compile(el); err != nil {
+				return err
+			}
+		}
+		c.emit(code.OpArray, len(node.Elements))
+
+	case *ast.HashLiteral:
+		if len(node.Keys) > code.MaxOperand(2) {
+			return fmt.Errorf("too many pairs in hash literal")
+		}
+
+		for i, el := range node.Keys {
+			if err := c.compile(el); err != nil {
+				return err
+			}
+			if err := c.compile(node.Values[i]); err != nil {
+				return err
+			}
+		}
+		c.emitAt(node.Token, code.OpHash, len(node.Keys))
+
+	case *ast.BindExpression:
+		if err := c.compile(node.Value); err != nil {
+			return err
+		}
+
+		ident, ok := node.Left.(*ast.Identifier)
+		if !ok {
+			return c.compileRaise(node.Token, "expected identifier on left got=%T", node.Left)
+		}
+
+		if err := c.store(node.Token, ident.Value, true); err != nil {
+			return err
+		}
+		c.emit(code.OpNull)
+
+	case *ast.AssignmentExpression:
+		return c.compileAssignmentExpression(node)
+
+	case *ast.IndexExpression:
+		if err := c.compile(node.Left); err != nil {
+			return err
+		}
+		if err := c.compile(node.Index); err != nil {
+			return err
+		}
+		c.emitAt(node.Token, code.OpIndex)
+
+	case *ast.SwitchExpression:
+		return c.compileSwitchExpression(node)
+
+	default:
+		c.emit(code.OpNull)
+	}
+
+	return nil
+}
+
+func (c *Compiler) compileConstant(obj object.Object) error {
+	idx, err := c.addConstant(obj)
+	if err != nil {
+		return err
+	}
+	c.emit(code.OpConstant, idx)
+	return nil
+}
+
+// compileRaise emits an instruction that stops the execution with the error
+func (c *Compiler) compileRaise(tok token.Token, format string, a ...interface{}) error {
+	idx, err := c.addName(fmt.Sprintf(format, a...))
+	if err != nil {
+		return err
+	}
+	c.emitAt(tok, code.OpRaise, idx)
+	return nil
+}
+
+func (c *Compiler) compileIfExpression(ie *ast.IfExpression) error {
+	if err := c.compile(ie.Condition); err != nil {
+		return err
+	}
+	jumpNotTruthy := c.emit(code.OpJumpNotTruthy, 0)
+
+	if err := c.compile(ie.Consequence); err != nil {
+		return err
+	}
+	jump := c.emit(code.OpJump, 0)
+
+	c.changeOperand(jumpNotTruthy, len(c.scope.fn.Instructions))
+	if ie.Alternative != nil {
+		if err := c.compile(ie.Alternative); err != nil {
+			return err
+		}
+	} else {
+		c.emit(code.OpNull)
+	}
+	c.changeOperand(jump, len(c.scope.fn.Instructions))
+
+	return nil
+}
+
+// The value of a loop (the value of the last iteration, null if broken)
+// is kept on the stack while the body runs; `break` and `continue` restore
+// the stack pointer marked at the beginning of the loop, then replace it.
+
+func (c *Compiler) compileWhileExpression(we *ast.WhileExpression) error {
+	c.emit(code.OpNull)
+	c.emit(code.OpLoop)
+
+	lp := &loop{start: len(c.scope.fn.Instructions)}
+	if err := c.compile(we.Condition); err != nil {
+		return err
+	}
+	exit := c.emit(code.OpJumpNotTruthy, 0)
+
+	c.emit(code.OpPop)
+	if err := c.compileLoopBody(lp, we.Consequence); err != nil {
+		return err
+	}
+	c.emit(code.OpJump, lp.start)
+
+	c.changeOperand(exit, len(c.scope.fn.Instructions))
+	c.endLoop(lp, 0)
+
+	return nil
+}
+
+func (c *Compiler) compileForInExpression(fe *ast.ForInExpression) error {
+	if err := c.compile(fe.Iterable); err != nil {
+		return err
+	}
+	c.emitAt(fe.Token, code.OpIter)
+	c.emit(code.OpNull)
+	c.emit(code.OpLoop)
+
+	lp := &loop{start: len(c.scope.fn.Instructions)}
+	exit := c.emit(code.OpIterNext, 0)
+	if err := c.store(fe.Variable.Token, fe.Variable.Value, false); err != nil {
+		return err
+	}
+
+	c.emit(code.OpPop)
+	if err := c.compileLoopBody(lp, fe.Body); err != nil {
+		return err
+	}
+	c.emit(code.OpJump, lp.start)
+
+	c.changeOperand(exit, len(c.scope.fn.Instructions))
+	// discards the iterator below the value of the loop
+	c.endLoop(lp, 1)
+
+	return nil
+}
+
+func (c *Compiler) compileLoopBody(lp *loop, body *ast.BlockStatement) error {
+	c.scope.loops = append(c.scope.loops, lp)
+	err := c.compile(body)
+	c.scope.loops = c.scope.loops[:len(c.scope.loops)-1]
+	return err
+}
+
+func (c *Compiler) endLoop(lp *loop, extra int) {
+	for _, el := range lp.breaks {
+		c.changeOperand(el, len(c.scope.fn.Instructions))
+	}
+	c.emit(code.OpLoopEnd, extra)
+}
+
+func (c *Compiler) compileLoopJump(tok token.Token, isBreak bool) error {
+	if len(c.scope.loops) == 0 {
+		return c.compileRaise(tok, "SyntaxError: `%s` outside of a loop", tok.Literal)
+	}
+	lp := c.scope.loops[len(c.scope.loops)-1]
+
+	c.emit(code.OpUnwind)
+	c.emit(code.OpPop)
+	c.emit(code.OpNull)
+
+	if isBreak {
+		lp.breaks = append(lp.breaks, c.emit(code.OpJump, 0))
+	} else {
+		c.emit(code.OpJump, lp.start)
+	}
+
+	return nil
+}
+
+func (c *Compiler) compileAssignmentExpression(ae *ast.AssignmentExpression) error {
+	// the left expression must exist
+	if err := c.compile(ae.Left); err != nil {
+		return err
+	}
+	c.emit(code.OpPop)
+
+	if err := c.compile(ae.Value); err != nil {
+		return err
+	}
+
+	switch left := ae.Left.(type) {
+	case *ast.Identifier:
+		if err := c.store(ae.Token, left.Value, false); err != nil {
+			return err
+		}
+		c.emit(code.OpNull)
+
+	case *ast.IndexExpression:
+		if err := c.compile(left.Left); err != nil {
+			return err
+		}
+		if err := c.compile(left.Index); err != nil {
+			return err
+		}
+		c.emitAt(ae.Token, code.OpSetIndex)
+
+	default:
+		return c.compileRaise(ae.Token, "expected identifier or index expression got=%T", ae.Left)
+	}
+
+	return nil
+}
+
+func (c *Compiler) compileSwitchExpression(se *ast.SwitchExpression) error {
+	if err := c.compile(se.Value); err != nil {
+		return err
+	}
+
+	var jumps []int
+	for _, opt := range se.Choices {
+		// the default case is handled later
+		if opt.Default {
+			continue
+		}
+
+		for _, val := range opt.Expr {
+			if err := c.compile(val); err != nil {
+				return err
+			}
+			c.emit(code.OpCase)
+			next := c.emit(code.OpJumpNotTruthy, 0)
+
+			c.emit(code.OpPop)
+			if err := c.compile(opt.Block); err != nil {
+				return err
+			}
+			jumps = append(jumps, c.emit(code.OpJump, 0))
+
+			c.changeOperand(next, len(c.scope.fn.Instructions))
+		}
+	}
+
+	c.emit(code.OpPop)
+
+	var def *ast.CaseExpression
+	for _, opt := range se.Choices {
+		if opt.Default {
+			def = opt
+			break
+		}
+	}
+
+	if def != nil {
+		if err := c.compile(def.Block); err != nil {
+			return err
+		}
+	} else {
+		c.emit(code.OpNull)
+	}
+
+	for _, el := range jumps {
+		c.changeOperand(el, len(c.scope.fn.Instructions))
+	}
+
+	return nil
+}
+
+// load emits the instruction that pushes the value bound to the name
+func (c *Compiler) load(tok token.Token, name string) error {
+	var slots []object.Slot
+
+	depth := 0
+	for s := c.scope; s != nil && s.locals != nil; s = s.parent {
+		if idx, ok := s.locals[name]; ok {
+			slots = append(slots, object.Slot{Depth: depth, Index: idx})
+
+			// parameters are always bound
+			if s.params[name] {
+				break
+			}
+		}
+		depth++
+	}
+
+	if len(slots) == 0 {
+		idx, err := c.addName(name)
+		if err != nil {
+			return err
+		}
+		c.emitAt(tok, code.OpGetName, idx)
+		return nil
+	}
+
+	c.scope.fn.Vars = append(c.scope.fn.Vars, object.Variable{Name: name, Slots: slots})
+	idx, err := index(len(c.scope.fn.Vars)-1, "variables")
+	if err != nil {
+		return err
+	}
+	c.emitAt(tok, code.OpGetVar, idx)
+
+	return nil
+}
+
+// store emits the instruction that binds the value on top of
+// the stack to the name, bind clones the immutable values
+func (c *Compiler) store(tok token.Token, name string, bind bool) error {
+	if idx, ok := c.scope.locals[name]; ok {
+		if bind {
+			c.emit(code.OpBindLocal, idx)
+		} else {
+			c.emit(code.OpAssignLocal, idx)
+		}
+		return nil
+	}
+
+	idx, err := c.addName(name)
+	if err != nil {
+		return err
+	}
+
+	if bind {
+		c.emitAt(tok, code.OpBindName, idx)
+	} else {
+		c.emitAt(tok, code.OpAssignName, idx)
+	}
+
+	return nil
+}
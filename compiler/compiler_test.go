@@ -0,0 +1,128 @@
+package compiler
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lucasepe/g2d/lexer"
+	"github.com/lucasepe/g2d/object"
+	"github.com/lucasepe/g2d/parser"
+)
+
+func compile(t *testing.T, input string) *object.CompiledFunction {
+	t.Helper()
+
+	p := parser.New(lexer.New(input))
+	program := p.ParseProgram()
+	if len(p.Errors()) > 0 {
+		t.Fatalf("parser errors: %v", p.Errors())
+	}
+
+	main, err := Compile(program)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return main
+}
+
+// function returns the compiled function literal of the constants
+func function(t *testing.T, fn *object.CompiledFunction) *object.CompiledFunction {
+	t.Helper()
+
+	for _, el := range fn.Constants {
+		if res, ok := el.(*object.CompiledFunction); ok {
+			return res
+		}
+	}
+	t.Fatalf("no function in the constants: %v", fn.Constants)
+	return nil
+}
+
+func TestLoopJumps(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{
+			// break unwinds the stack and jumps past the end of the loop
+			"while (true) { break }",
+			`0000 OpNull
+0001 OpLoop
+0002 OpTrue
+0003 OpJumpNotTruthy 22
+0008 OpPop
+0009 OpUnwind
+0010 OpPop
+0011 OpNull
+0012 OpJump 22
+0017 OpJump 2
+0022 OpLoopEnd 0
+0024 OpReturnValue
+`,
+		},
+		{
+			// continue unwinds the stack and jumps to the next item,
+			// the end of the loop discards the iterator
+			"for i in [1] { continue }",
+			`0000 OpConstant 0
+0003 OpArray 1
+0006 OpIter
+0007 OpNull
+0008 OpLoop
+0009 OpIterNext 31
+0014 OpAssignName 0
+0017 OpPop
+0018 OpUnwind
+0019 OpPop
+0020 OpNull
+0021 OpJump 9
+0026 OpJump 9
+0031 OpLoopEnd 1
+0033 OpReturnValue
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, compile(t, tt.input).Instructions.String(), tt.input)
+	}
+}
+
+func TestLoopJumpOutsideLoop(t *testing.T) {
+	inner := function(t, function(t, compile(t, "fn() { for i in [1] { fn() { break } } }")))
+
+	assert.Equal(t, "0000 OpRaise 0\n0003 OpReturnValue\n", inner.Instructions.String())
+	assert.Equal(t, []string{"SyntaxError: `break` outside of a loop"}, inner.Names)
+}
+
+func TestClosureVariables(t *testing.T) {
+	// a name assigned by a top level function is one of its locals
+	fn := function(t, compile(t, "x := 1; fn() { x = x + 1; x }"))
+	assert.Equal(t, 1, fn.NumLocals)
+	for _, el := range fn.Vars {
+		assert.Equal(t, []object.Slot{{Depth: 0, Index: 0}}, el.Slots)
+	}
+
+	// a name only read is looked up in the environment
+	fn = function(t, compile(t, "x := 1; fn() { x }"))
+	assert.Equal(t, 0, fn.NumLocals)
+	assert.Equal(t, "0000 OpGetName 0\n0003 OpReturnValue\n", fn.Instructions.String())
+
+	// a closure reads the slot of the enclosing function when its
+	// own is not bound yet, but assigns its own
+	outer := function(t, compile(t, "fn(x) { fn() { x = x + 1; x } }"))
+	inner := function(t, outer)
+	assert.Equal(t, []int{0}, outer.ParameterSlots)
+	assert.Len(t, inner.Vars, 3)
+	for _, el := range inner.Vars {
+		assert.Equal(t, "x", el.Name)
+		assert.Equal(t, []object.Slot{{Depth: 0, Index: 0}, {Depth: 1, Index: 0}}, el.Slots)
+	}
+	assert.Contains(t, inner.Instructions.String(), "OpAssignLocal 0\n")
+
+	// the slots stop at the parameters, that are always bound
+	inner = function(t, function(t, compile(t, "fn(x) { x := 2; fn(x) { fn() { x } } }")))
+	inner = function(t, inner)
+	assert.Equal(t, []object.Slot{{Depth: 1, Index: 0}}, inner.Vars[0].Slots)
+}
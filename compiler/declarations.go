@@ -0,0 +1,94 @@
+package compiler
+
+import (
+	"github.com/lucasepe/g2d/ast"
+	"github.com/lucasepe/g2d/eval"
+)
+
+// declarations calls fn with each name bound in the node, nested
+// function literals excluded since they have their own scope
+func declarations(node ast.Node, fn func(name string)) {
+	switch node := node.(type) {
+	case *ast.BlockStatement:
+		if node == nil {
+			return
+		}
+		for _, el := range node.Statements {
+			declarations(el, fn)
+		}
+
+	case *ast.ExpressionStatement:
+		declarations(node.Expression, fn)
+
+	case *ast.ReturnStatement:
+		declarations(node.ReturnValue, fn)
+
+	case *ast.ImportStatement:
+		fn(eval.ImportName(node))
+
+	case *ast.PrefixExpression:
+		declarations(node.Right, fn)
+
+	case *ast.InfixExpression:
+		declarations(node.Left, fn)
+		declarations(node.Right, fn)
+
+	case *ast.IfExpression:
+		declarations(node.Condition, fn)
+		declarations(node.Consequence, fn)
+		declarations(node.Alternative, fn)
+
+	case *ast.WhileExpression:
+		declarations(node.Condition, fn)
+		declarations(node.Consequence, fn)
+
+	case *ast.ForInExpression:
+		fn(node.Variable.Value)
+		declarations(node.Iterable, fn)
+		declarations(node.Body, fn)
+
+	case *ast.CallExpression:
+		declarations(node.Function, fn)
+		for _, el := range node.Arguments {
+			declarations(el, fn)
+		}
+
+	case *ast.ArrayLiteral:
+		for _, el := range node.Elements {
+			declarations(el, fn)
+		}
+
+	case *ast.HashLiteral:
+		for i, el := range node.Keys {
+			declarations(el, fn)
+			declarations(node.Values[i], fn)
+		}
+
+	case *ast.BindExpression:
+		if ident, ok := node.Left.(*ast.Identifier); ok {
+			fn(ident.Value)
+		}
+		declarations(node.Value, fn)
+
+	case *ast.AssignmentExpression:
+		if ident, ok := node.Left.(*ast.Identifier); ok {
+			fn(ident.Value)
+		} else {
+			declarations(node.Left, fn)
+		}
+		declarations(node.Value, fn)
+
+	case *ast.IndexExpression:
+		declarations(node.Left, fn)
+		declarations(node.Index, fn)
+
+	case *ast.SwitchExpression:
+		declarations(node.Value, fn)
+		for _, opt := range node.Choices {
+			for _, el := range opt.Expr {
+				declarations(el, fn)
+			}
+			declarations(opt.Block, fn)
+		}
+	}
+}
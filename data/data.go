@@ -4,7 +4,9 @@ import (
 	"io"
 	"io/ioutil"
 	"net/http"
+	"net/url"
 	"os"
+	"path/filepath"
 	"strings"
 )
 
@@ -20,6 +22,29 @@ func Fetch(uri string, limit int64) ([]byte, error) {
 	return FetchFromFile(uri, limit)
 }
 
+// Resolve returns the URI of 'ref' relative to the 'base' URI.
+// Both can be remote (http) or local; if 'ref' is absolute
+// or 'base' is empty, 'ref' is returned as is.
+func Resolve(base, ref string) (string, error) {
+	if base == "" || strings.HasPrefix(ref, "http") || filepath.IsAbs(ref) {
+		return ref, nil
+	}
+
+	if strings.HasPrefix(base, "http") {
+		b, err := url.Parse(base)
+		if err != nil {
+			return "", err
+		}
+		r, err := url.Parse(filepath.ToSlash(ref))
+		if err != nil {
+			return "", err
+		}
+		return b.ResolveReference(r).String(), nil
+	}
+
+	return filepath.Join(filepath.Dir(base), ref), nil
+}
+
 // FetchFromURI fetch data (with limit) from an HTTP URL.
 // if 'limit' is greater then zero, fetch stops
 // with EOF after 'limit' bytes.
@@ -37,6 +37,35 @@ func TestFetchFromFile(t *testing.T) {
 	}
 }
 
+func TestResolve(t *testing.T) {
+	tests := []struct {
+		base string
+		ref  string
+		want string
+	}{
+		{"", "lib.g2d", "lib.g2d"},
+		{"main.g2d", "lib.g2d", "lib.g2d"},
+		{"scripts/main.g2d", "lib/grid.g2d", "scripts/lib/grid.g2d"},
+		{"scripts/main.g2d", "../lib.g2d", "lib.g2d"},
+		{"scripts/main.g2d", "/usr/share/g2d/lib.g2d", "/usr/share/g2d/lib.g2d"},
+		{"http://example.com/g2d/main.g2d", "lib/grid.g2d", "http://example.com/g2d/lib/grid.g2d"},
+		{"http://example.com/g2d/main.g2d", "../lib.g2d", "http://example.com/lib.g2d"},
+		{"scripts/main.g2d", "http://example.com/lib.g2d", "http://example.com/lib.g2d"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			got, err := Resolve(tt.base, tt.ref)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got [%v] want [%v]", got, tt.want)
+			}
+		})
+	}
+}
+
 // remove tabs and newlines and spaces
 func flatten(s string) string {
 	return strings.Replace((strings.Replace(s, "\n", "", -1)), "\t", "", -1)
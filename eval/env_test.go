@@ -38,6 +38,17 @@ func (dc *MockGraphicContext) LineTo(x, y float64) {}
 // MoveTo(x1, y1)
 func (dc *MockGraphicContext) QuadraticTo(x1, y1, x2, y2 float64) {}
 
+// AppendPath adds the subpaths of the specified path to the current path
+func (dc *MockGraphicContext) AppendPath(p *gg.Path) {}
+
+// CurrentPath returns a copy of the current path
+func (dc *MockGraphicContext) CurrentPath() *gg.Path { return gg.NewPath() }
+
+// CubicTo adds a cubic bezier curve to the current path starting at
+// the current point. If there is no current point, it first performs
+// MoveTo(x1, y1)
+func (dc *MockGraphicContext) CubicTo(x1, y1, x2, y2, x3, y3 float64) {}
+
 // ClosePath adds a line segment from the current point to the beginning
 // of the current subpath. If there is no current point, this is a no-op.
 func (dc *MockGraphicContext) ClosePath() {}
@@ -57,6 +68,9 @@ func (dc *MockGraphicContext) SetFillColor(r, g, b, a int) {}
 // SetFillRule sets the current fill rule
 func (dc *MockGraphicContext) SetFillRule(fillRule gg.FillRule) {}
 
+// FillRule returns the current fill rule
+func (dc *MockGraphicContext) FillRule() gg.FillRule { return gg.FillRuleWinding }
+
 // ArcTo adds a circular arc to the current sub-path, using
 // the given control points and radius.
 func (dc *MockGraphicContext) ArcTo(x1, y1, x2, y2, radius float64) {}
@@ -79,6 +93,9 @@ func (dc *MockGraphicContext) SetLineCap(lineCap gg.LineCap) {}
 // SetLineJoin sets the current line join
 func (dc *MockGraphicContext) SetLineJoin(lineJoin gg.LineJoin) {}
 
+// SetMiterLimit sets the current miter limit
+func (dc *MockGraphicContext) SetMiterLimit(limit float64) {}
+
 // SetLineDash sets the current dash
 func (dc *MockGraphicContext) SetLineDash(dashes ...float64) {}
 
@@ -185,3 +202,6 @@ func (dc *MockGraphicContext) DrawStringAnchored(s string, x, y, ax, ay float64)
 // MeasureString returns the rendered width and height of the specified text
 // given the current font face.
 func (dc *MockGraphicContext) MeasureString(s string) (w, h float64) { return 0, 0 }
+
+// FontMetrics returns the ascent and descent of the current font face.
+func (dc *MockGraphicContext) FontMetrics() (ascent, descent float64) { return 0, 0 }
@@ -7,14 +7,21 @@ package eval
 import (
 	"fmt"
 	"math"
+	"path"
+	"strings"
 
 	"github.com/lucasepe/g2d/ast"
 	"github.com/lucasepe/g2d/builtins"
+	"github.com/lucasepe/g2d/data"
 	"github.com/lucasepe/g2d/lexer"
 	"github.com/lucasepe/g2d/object"
+	"github.com/lucasepe/g2d/parser"
 	"github.com/lucasepe/g2d/token"
 )
 
+// max size (in bytes) of an imported module
+const importLimit = 512 * 1000
+
 var (
 	// TRUE is a cached Boolean object holding the `true` value
 	TRUE = &object.Boolean{Value: true}
@@ -58,7 +65,7 @@ func newError(format string, a ...interface{}) *object.Error {
 // NB. Eval(node, env) is recursive
 func BeginEval(program ast.Node, env *object.Environment, lexer *lexer.Lexer) object.Object {
 	// global lexer
-	lex = lexer
+	SetLexer(lexer)
 	// run the evaluator
 	return Eval(program, env)
 }
@@ -85,6 +92,15 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 		}
 		return &object.Return{Value: val}
 
+	case *ast.BreakStatement:
+		return &object.Break{}
+
+	case *ast.ContinueStatement:
+		return &object.Continue{}
+
+	case *ast.ImportStatement:
+		return evalImportStatement(node, env, evalProgram)
+
 	// Expressions
 	case *ast.IntegerLiteral:
 		return &object.Integer{Value: node.Value}
@@ -121,6 +137,8 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 		return evalIfExpression(node, env)
 	case *ast.WhileExpression:
 		return evalWhileExpression(node, env)
+	case *ast.ForInExpression:
+		return evalForInExpression(node, env)
 	case *ast.Identifier:
 		return evalIdentifier(node, env)
 
@@ -148,6 +166,9 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 		}
 		return &object.Array{Elements: elements}
 
+	case *ast.HashLiteral:
+		return evalHashLiteral(node, env)
+
 	case *ast.BindExpression:
 		value := Eval(node.Value, env)
 		if isError(value) {
@@ -199,23 +220,12 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 			return obj
 		}
 
-		array, ok := obj.(*object.Array)
-		if !ok {
-			return newError(node.Token, "object type %T does not support item assignment", obj)
-		}
-
 		index := Eval(ie.Index, env)
 		if isError(index) {
 			return index
 		}
 
-		idx, ok := index.(*object.Integer)
-		if !ok {
-			return newError(node.Token, "cannot index array with %#v", index)
-		}
-
-		array.Elements[idx.Value] = value
-		return NULL
+		return evalIndexAssignment(node.Token, obj, index, value)
 
 	case *ast.IndexExpression:
 		left := Eval(node.Left, env)
@@ -246,6 +256,8 @@ func evalProgram(program *ast.Program, env *object.Environment) object.Object {
 			return result.Value
 		case *object.Error:
 			return result
+		case *object.Break, *object.Continue:
+			return object.NewError("SyntaxError: `%s` outside of a loop", result.Inspect())
 		}
 	}
 
@@ -274,7 +286,8 @@ func evalBlockStatement(block *ast.BlockStatement, env *object.Environment) obje
 
 		if result != nil {
 			rt := result.Type()
-			if rt == object.RETURN || rt == object.ERROR {
+			if rt == object.RETURN || rt == object.ERROR ||
+				rt == object.BREAK || rt == object.CONTINUE {
 				return result
 			}
 		}
@@ -533,14 +546,21 @@ func evalWhileExpression(we *ast.WhileExpression, env *object.Environment) objec
 			return condition
 		}
 
-		if isTruthy(condition) {
-			result = Eval(we.Consequence, env)
-			if isError(result) {
-				return result
-			}
-		} else {
+		if !isTruthy(condition) {
+			break
+		}
+
+		result = Eval(we.Consequence, env)
+		if isError(result) || isReturn(result) {
+			return result
+		}
+		if isBreak(result) {
+			result = NULL
 			break
 		}
+		if isContinue(result) {
+			result = NULL
+		}
 	}
 
 	if result != nil {
@@ -549,19 +569,95 @@ func evalWhileExpression(we *ast.WhileExpression, env *object.Environment) objec
 	return NULL
 }
 
+func evalForInExpression(fe *ast.ForInExpression, env *object.Environment) object.Object {
+	iterable := Eval(fe.Iterable, env)
+	if isError(iterable) {
+		return iterable
+	}
+
+	items, err := iterate(fe.Token, iterable)
+	if err != nil {
+		return err
+	}
+
+	var result object.Object = NULL
+	for _, item := range items {
+		if _, ok := env.Set(fe.Variable.Value, item); !ok {
+			return newError(fe.Variable.Token, "reserved keyword `%s`", fe.Variable.Value)
+		}
+
+		result = Eval(fe.Body, env)
+		if isError(result) || isReturn(result) {
+			return result
+		}
+		if isBreak(result) {
+			return NULL
+		}
+		if isContinue(result) {
+			result = NULL
+		}
+	}
+
+	if result == nil {
+		return NULL
+	}
+	return result
+}
+
+// iterate returns the items of the iterable object: the elements
+// of an array, the keys of an hash or the characters of a string
+func iterate(tok token.Token, iterable object.Object) ([]object.Object, *object.Error) {
+	var items []object.Object
+	switch obj := iterable.(type) {
+	case *object.Array:
+		// iterate over a copy, so the body can change the array
+		items = obj.Copy().Elements
+	case *object.Hash:
+		items = obj.Keys()
+	case *object.String:
+		for _, ch := range obj.Value {
+			items = append(items, &object.String{Value: string(ch)})
+		}
+	default:
+		return nil, newError(tok, "object of type '%s' is not iterable", iterable.Type())
+	}
+
+	return items, nil
+}
+
 func isTruthy(obj object.Object) bool {
-	switch obj {
-	case NULL:
-		return false
-	case TRUE:
-		return true
-	case FALSE:
+	switch obj := obj.(type) {
+	case *object.Null:
 		return false
+	case *object.Boolean:
+		// builtins may return booleans other than TRUE and FALSE
+		return obj.Value
 	default:
 		return true
 	}
 }
 
+func isReturn(obj object.Object) bool {
+	if obj != nil {
+		return obj.Type() == object.RETURN
+	}
+	return false
+}
+
+func isBreak(obj object.Object) bool {
+	if obj != nil {
+		return obj.Type() == object.BREAK
+	}
+	return false
+}
+
+func isContinue(obj object.Object) bool {
+	if obj != nil {
+		return obj.Type() == object.CONTINUE
+	}
+	return false
+}
+
 func isError(obj object.Object) bool {
 	if obj != nil {
 		return obj.Type() == object.ERROR
@@ -605,6 +701,9 @@ func applyFunction(tok token.Token, env *object.Environment, fn object.Object, a
 		}
 
 		evaluated := Eval(fn.Body, fnEnv)
+		if isBreak(evaluated) || isContinue(evaluated) {
+			return newError(tok, "SyntaxError: `%s` outside of a loop", evaluated.Inspect())
+		}
 		return unwrapReturnValue(evaluated)
 
 	case *object.Builtin:
@@ -659,11 +758,39 @@ func evalIndexExpression(tok token.Token, left, index object.Object) object.Obje
 		return evalStringIndexExpression(left, index)
 	case left.Type() == object.ARRAY && index.Type() == object.INTEGER:
 		return evalArrayIndexExpression(left, index)
+	case left.Type() == object.HASH:
+		return evalHashIndexExpression(tok, left, index)
+	case left.Type() == object.MODULE && index.Type() == object.STRING:
+		return evalModuleIndexExpression(tok, left, index)
+	case left.Type() == object.PATH && index.Type() == object.STRING:
+		return evalMethodExpression(tok, left, index)
 	default:
 		return newError(tok, "index operator not supported: %s", left.Type())
 	}
 }
 
+func evalIndexAssignment(tok token.Token, obj, index, value object.Object) object.Object {
+	if hash, ok := obj.(*object.Hash); ok {
+		if err := hash.Set(index, value); err != nil {
+			return newError(tok, "%s", err.Error())
+		}
+		return NULL
+	}
+
+	array, ok := obj.(*object.Array)
+	if !ok {
+		return newError(tok, "object type %T does not support item assignment", obj)
+	}
+
+	idx, ok := index.(*object.Integer)
+	if !ok {
+		return newError(tok, "cannot index array with %#v", index)
+	}
+
+	array.Elements[idx.Value] = value
+	return NULL
+}
+
 func evalArrayIndexExpression(array, index object.Object) object.Object {
 	arrayObject := array.(*object.Array)
 	idx := index.(*object.Integer).Value
@@ -676,6 +803,66 @@ func evalArrayIndexExpression(array, index object.Object) object.Object {
 	return arrayObject.Elements[idx]
 }
 
+func evalHashIndexExpression(tok token.Token, hash, index object.Object) object.Object {
+	hashObject := hash.(*object.Hash)
+
+	val, err := hashObject.Get(index)
+	if err != nil {
+		return newError(tok, "%s", err.Error())
+	}
+
+	if val == nil {
+		return NULL
+	}
+
+	return val
+}
+
+func evalModuleIndexExpression(tok token.Token, module, index object.Object) object.Object {
+	moduleObject := module.(*object.Module)
+	name := index.(*object.String).Value
+
+	val, ok := moduleObject.Get(name)
+	if !ok {
+		return newError(tok, "module `%s` has no member `%s`", moduleObject.Name, name)
+	}
+
+	return val
+}
+
+func evalMethodExpression(tok token.Token, obj, index object.Object) object.Object {
+	name := index.(*object.String).Value
+
+	method, ok := builtins.Method(obj, name)
+	if !ok {
+		return newError(tok, "%s has no method `%s`", obj.Type(), name)
+	}
+
+	return method
+}
+
+func evalHashLiteral(node *ast.HashLiteral, env *object.Environment) object.Object {
+	hash := object.NewHash()
+
+	for i, keyNode := range node.Keys {
+		key := Eval(keyNode, env)
+		if isError(key) {
+			return key
+		}
+
+		value := Eval(node.Values[i], env)
+		if isError(value) {
+			return value
+		}
+
+		if err := hash.Set(key, value); err != nil {
+			return newError(node.Token, "%s", err.Error())
+		}
+	}
+
+	return hash
+}
+
 func evalStringIndexExpression(str, index object.Object) object.Object {
 	stringObject := str.(*object.String)
 	idx := index.(*object.Integer).Value
@@ -706,13 +893,7 @@ func evalSwitchStatement(se *ast.SwitchExpression, env *object.Environment) obje
 			// Get the value of the case
 			out := Eval(val, env)
 
-			// Is is a boolean and true?
-			if (out.Type() == object.BOOLEAN) && (out.Inspect() == "true") {
-				return evalBlockStatement(opt.Block, env)
-			}
-
-			// Is it a literal match?
-			if (obj.Type() == out.Type()) && (obj.Inspect() == out.Inspect()) {
+			if caseMatches(obj, out) {
 				// Evaluate the block and return the value
 				return evalBlockStatement(opt.Block, env)
 			}
@@ -732,3 +913,121 @@ func evalSwitchStatement(se *ast.SwitchExpression, env *object.Environment) obje
 
 	return nil
 }
+
+// caseMatches returns true if the value of the case is true
+// or it is a literal match of the value of the switch
+func caseMatches(obj, out object.Object) bool {
+	// Is is a boolean and true?
+	if (out.Type() == object.BOOLEAN) && (out.Inspect() == "true") {
+		return true
+	}
+
+	// Is it a literal match?
+	return (obj.Type() == out.Type()) && (obj.Inspect() == out.Inspect())
+}
+
+// evalImportStatement binds the module of the import statement, the
+// program of the module is evaluated (only once) by the run function
+func evalImportStatement(is *ast.ImportStatement, env *object.Environment, run func(*ast.Program, *object.Environment) object.Object) object.Object {
+	obj := importModule(is, env, run)
+	if isError(obj) {
+		return obj
+	}
+
+	module := obj.(*object.Module)
+	if _, ok := env.Set(module.Name, module); !ok {
+		return newError(is.Token, "reserved keyword `%s`", module.Name)
+	}
+
+	return NULL
+}
+
+// importName returns the name bound by the import statement: the
+// alias, if any, otherwise the default name of the module
+func importName(is *ast.ImportStatement) string {
+	if is.Name != nil {
+		return is.Name.Value
+	}
+	return moduleName(is.Path.Value)
+}
+
+// importModule returns the module of the import statement, named
+// as the import statement binds it, evaluating it if not cached
+func importModule(is *ast.ImportStatement, env *object.Environment, run func(*ast.Program, *object.Environment) object.Object) object.Object {
+	uri, err := data.Resolve(env.ScriptPath(), is.Path.Value)
+	if err != nil {
+		return newError(is.Token, "ImportError: %s", err.Error())
+	}
+
+	name := importName(is)
+	if !isIdentifier(name) {
+		return newError(is.Token, "ImportError: `%s` is not a valid module name, use `import \"%s\" as name`", name, is.Path.Value)
+	}
+
+	module, ok := env.Module(uri)
+	if !ok {
+		obj := evalModule(is.Token, uri, env, run)
+		if isError(obj) {
+			return obj
+		}
+		module = obj.(*object.Module)
+	}
+
+	// the same module can be bound with different names
+	return &object.Module{Name: name, Path: module.Path, Env: module.Env}
+}
+
+// evalModule fetches, parses and evaluates the module at the specified URI
+// in a new top level environment, caching the result
+func evalModule(tok token.Token, uri string, env *object.Environment, run func(*ast.Program, *object.Environment) object.Object) object.Object {
+	if err := env.BeginImport(uri); err != nil {
+		return newError(tok, "ImportError: %s", err.Error())
+	}
+
+	var module *object.Module
+	defer func() { env.EndImport(uri, module) }()
+
+	env.AddDependency(uri)
+
+	src, err := data.Fetch(uri, importLimit)
+	if err != nil {
+		return newError(tok, "ImportError: %s", err.Error())
+	}
+
+	l := lexer.New(string(src))
+	p := parser.New(l)
+	program := p.ParseProgram()
+	if errs := p.Errors(); len(errs) > 0 {
+		return newError(tok, "ImportError: cannot parse `%s`\n%s", uri, strings.Join(errs, "\n"))
+	}
+
+	// error locations must refer to the module source
+	prev := lex
+	lex = l
+	modEnv := env.NewModuleEnvironment(uri)
+	res := run(program, modEnv)
+	lex = prev
+
+	if isError(res) {
+		return newError(tok, "ImportError: cannot import `%s`\n%s", uri, res.String())
+	}
+
+	module = &object.Module{Name: moduleName(uri), Path: uri, Env: modEnv}
+	return module
+}
+
+// moduleName returns the default module name: the last
+// element of the path without the filename extension
+func moduleName(uri string) string {
+	name := path.Base(strings.Replace(uri, "\\", "/", -1))
+	if idx := strings.Index(name, "?"); idx >= 0 {
+		name = name[:idx]
+	}
+	return strings.TrimSuffix(name, path.Ext(name))
+}
+
+// isIdentifier returns true if the string is a valid (not reserved) identifier
+func isIdentifier(s string) bool {
+	tok := lexer.New(s).NextToken()
+	return tok.Type == token.IDENT && tok.Literal == s
+}
@@ -1,19 +1,46 @@
-package eval
+package eval_test
 
 import (
 	"errors"
 	"io/ioutil"
 	"math"
+	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 
+	"github.com/lucasepe/g2d/ast"
+	"github.com/lucasepe/g2d/eval"
 	"github.com/lucasepe/g2d/lexer"
 	"github.com/lucasepe/g2d/object"
 	"github.com/lucasepe/g2d/parser"
+	"github.com/lucasepe/g2d/vm"
 )
 
+// engine is the engine under test: the evaluator or the virtual machine
+var engine string
+
+// TestMain runs all the tests against both the engines
+func TestMain(m *testing.M) {
+	code := 0
+	for _, engine = range []string{"eval", "vm"} {
+		if res := m.Run(); res != 0 {
+			code = res
+		}
+	}
+	os.Exit(code)
+}
+
+// run runs the program with the engine under test
+func run(program *ast.Program, env *object.Environment) object.Object {
+	if engine == "vm" {
+		return vm.Run(program, env)
+	}
+	return eval.Eval(program, env)
+}
+
 func assertEvaluated(t *testing.T, expected interface{}, actual object.Object) {
 	t.Helper()
 
@@ -95,9 +122,9 @@ func testEval(input string) object.Object {
 	l := lexer.New(input)
 	p := parser.New(l)
 	program := p.ParseProgram()
-	env := object.NewEnvironment(&MockGraphicContext{})
+	env := object.NewEnvironment(&eval.MockGraphicContext{})
 
-	return Eval(program, env)
+	return run(program, env)
 }
 
 func testIntegerObject(t *testing.T, obj object.Object, expected int64) bool {
@@ -265,8 +292,57 @@ func TestWhileExpressions(t *testing.T) {
 	}
 }
 
+func TestForInExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"for i in [] { }", nil},
+		{"n := 0; for i in range(5) { n = n + i }; n", 10},
+		{"n := 0; for i in range(2, 5) { n = n + i }; n", 9},
+		{"n := 0; for i in range(0, 10, 2) { n = n + i }; n", 20},
+		{"n := 0; for i in range(10, 0, -3) { n = n + i }; n", 22},
+		{"n := 0; for x in [1, 2, 3] { n = n + x }; n", 6},
+		{`n := 0; for k in {"a": 1, "b": 2} { n = n + len(k) }; n`, 2},
+		{`s := ""; for ch in "abc" { s = ch + s }; len(s)`, 3},
+		{"n := 0; for i in range(10) { if (i == 3) { break }; n = n + i }; n", 3},
+		{"n := 0; for i in range(10) { if (i % 2 == 0) { continue }; n = n + i }; n", 25},
+		{"n := 0; for i in range(3) { for j in range(3) { if (j == 1) { break }; n = n + 1 } }; n", 3},
+		{"n := 0; while (true) { n = n + 1; if (n == 5) { break } }; n", 5},
+		{"n := 0; i := 0; while (i < 10) { i = i + 1; if (i % 2 == 0) { continue }; n = n + 1 }; n", 5},
+		{"f := fn() { for i in range(10) { if (i == 7) { return i } } }; f()", 7},
+		{"for i in 5 { }", errors.New("object of type 'int' is not iterable")},
+		{"break", errors.New("SyntaxError: `break` outside of a loop")},
+		{"f := fn() { continue }; f()", errors.New("SyntaxError: `continue` outside of a loop")},
+		{"range(1, 2, 0)", errors.New("ValueError: range() argument #3 `step` must not be zero")},
+		{"range(1000000000)", errors.New("ValueError: range() too many elements, at most 10000000 are allowed")},
+		{"range(0, 1, 0.000000001)", errors.New("ValueError: range() too many elements, at most 10000000 are allowed")},
+		{"len(range(-5, 1000000000, -1))", 0},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case error:
+			errObj, ok := evaluated.(*object.Error)
+			if !ok {
+				t.Errorf("object is not Error. got=%T (%+v)", evaluated, evaluated)
+				continue
+			}
+			if errObj.Message != expected.Error() {
+				t.Errorf("wrong error message. expected=%q, got=%q", expected, errObj.Message)
+			}
+		default:
+			testNullObject(t, evaluated)
+		}
+	}
+}
+
 func testNullObject(t *testing.T, obj object.Object) bool {
-	if obj != NULL {
+	if obj != eval.NULL {
 		t.Errorf("object is not NULL. got=%T (%+v)", obj, obj)
 		return false
 	}
@@ -432,24 +508,37 @@ func TestFunctionObject(t *testing.T) {
 	input := "fn(x) { x + 2; };"
 
 	evaluated := testEval(input)
-	fn, ok := evaluated.(*object.Function)
-	if !ok {
+
+	// the virtual machine returns the compiled function bound to its scope
+	var params []*ast.Identifier
+	var body *ast.BlockStatement
+	switch fn := evaluated.(type) {
+	case *object.Function:
+		if engine != "eval" {
+			t.Fatalf("object is not Closure. got=%T (%+v)", evaluated, evaluated)
+		}
+		params, body = fn.Parameters, fn.Body
+	case *object.Closure:
+		if engine != "vm" {
+			t.Fatalf("object is not Function. got=%T (%+v)", evaluated, evaluated)
+		}
+		params, body = fn.Fn.Parameters, fn.Fn.Body
+	default:
 		t.Fatalf("object is not Function. got=%T (%+v)", evaluated, evaluated)
 	}
 
-	if len(fn.Parameters) != 1 {
-		t.Fatalf("function has wrong parameters. Parameters=%+v",
-			fn.Parameters)
+	if len(params) != 1 {
+		t.Fatalf("function has wrong parameters. Parameters=%+v", params)
 	}
 
-	if fn.Parameters[0].String() != "x" {
-		t.Fatalf("parameter is not 'x'. got=%q", fn.Parameters[0])
+	if params[0].String() != "x" {
+		t.Fatalf("parameter is not 'x'. got=%q", params[0])
 	}
 
 	expectedBody := "(x + 2)"
 
-	if fn.Body.String() != expectedBody {
-		t.Fatalf("body is not %q. got=%q", expectedBody, fn.Body.String())
+	if body.String() != expectedBody {
+		t.Fatalf("body is not %q. got=%q", expectedBody, body.String())
 	}
 }
 
@@ -686,17 +775,42 @@ func TestArrayIndexExpressions(t *testing.T) {
 }
 
 func TestExamples(t *testing.T) {
-	matches, err := filepath.Glob("./_examples/*.g2d")
+	matches, err := filepath.Glob("../_examples/*.g2d")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(matches) == 0 {
+		t.Fatal("no examples found")
+	}
+
+	// the snapshots are saved in a temporary folder
+	dir, err := ioutil.TempDir("", "g2d-examples")
 	if err != nil {
-		t.Error(err)
+		t.Fatal(err)
 	}
+	defer os.RemoveAll(dir)
 
 	for _, match := range matches {
 		b, err := ioutil.ReadFile(match)
 		if err != nil {
-			t.Error(err)
+			t.Fatal(err)
+		}
+
+		l := lexer.New(string(b))
+		p := parser.New(l)
+		program := p.ParseProgram()
+		if len(p.Errors()) > 0 {
+			t.Errorf("%s: %v", match, p.Errors())
+			continue
+		}
+
+		env := object.NewEnvironment(&eval.MockGraphicContext{},
+			object.WithScriptPath(match),
+			object.WithOutputDir(dir),
+			object.WithSeed(1))
+		if errObj, ok := run(program, env).(*object.Error); ok {
+			t.Errorf("%s: %s", match, errObj.Message)
 		}
-		testEval(string(b))
 	}
 }
 
@@ -723,3 +837,302 @@ func TestDivisionByZero(t *testing.T) {
 		}
 	}
 }
+
+func TestHashLiterals(t *testing.T) {
+	input := `two := "two";
+	{
+		"one": 10 - 9,
+		two: 1 + 1,
+		"thr" + "ee": 6 / 2,
+		4: 4,
+		true: 5,
+		false: 6
+	}`
+
+	evaluated := testEval(input)
+	result, ok := evaluated.(*object.Hash)
+	if !ok {
+		t.Fatalf("Eval didn't return Hash. got=%T (%+v)", evaluated, evaluated)
+	}
+
+	expected := map[object.HashKey]int64{
+		(&object.String{Value: "one"}).HashKey():   1,
+		(&object.String{Value: "two"}).HashKey():   2,
+		(&object.String{Value: "three"}).HashKey(): 3,
+		(&object.Integer{Value: 4}).HashKey():      4,
+		eval.TRUE.HashKey():                        5,
+		eval.FALSE.HashKey():                       6,
+	}
+
+	if len(result.Pairs) != len(expected) {
+		t.Fatalf("Hash has wrong num of pairs. got=%d", len(result.Pairs))
+	}
+
+	for expectedKey, expectedValue := range expected {
+		pair, ok := result.Pairs[expectedKey]
+		if !ok {
+			t.Errorf("no pair for given key in Pairs")
+		}
+
+		testIntegerObject(t, pair.Value, expectedValue)
+	}
+}
+
+func TestHashIndexExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`{"foo": 5}["foo"]`, 5},
+		{`{"foo": 5}["bar"]`, nil},
+		{`key := "foo"; {"foo": 5}[key]`, 5},
+		{`{}["foo"]`, nil},
+		{`{5: 5}[5]`, 5},
+		{`{true: 5}[true]`, 5},
+		{`{false: 5}[false]`, 5},
+		{`shape := {"width": 3, "height": 4}; shape.width * shape.height`, 12},
+		{`shape := {"size": {"w": 7}}; shape.size.w`, 7},
+		{`shape := {}; shape["w"] = 2; shape.h = 3; shape.w + shape.h`, 5},
+		{`shape := {"w": 1}; shape.w = 9; shape["w"]`, 9},
+		{`{"foo": 5}[fn(x) { x }]`, errors.New("unusable as hash key: fn")},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case error:
+			errObj, ok := evaluated.(*object.Error)
+			if !ok {
+				t.Errorf("object is not Error. got=%T (%+v)", evaluated, evaluated)
+				continue
+			}
+			if errObj.Message != expected.Error() {
+				t.Errorf("wrong error message. expected=%q, got=%q", expected, errObj.Message)
+			}
+		default:
+			testNullObject(t, evaluated)
+		}
+	}
+}
+
+func TestHashBuiltins(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`len({"a": 1, "b": 2})`, 2},
+		{`str(keys({"a": 1, "b": 2, "c": 3}))`, "[a, b, c]"},
+		{`str(values({"a": 1, "b": 2, "c": 3}))`, "[1, 2, 3]"},
+		{`has({"a": 1}, "a")`, true},
+		{`has({"a": 1}, "b")`, false},
+		{`h := {"a": 1, "b": 2}; delete(h, "a"); str(keys(h))`, "[b]"},
+		{`delete({"a": 1}, "b")`, false},
+		{`h := {"a": 1}; if (has(h, "b")) { 1 } else { 2 }`, 2},
+		{`str({"a": 1, "b": [1, 2]})`, "{a: 1, b: [1, 2]}"},
+		{`keys([1])`, errors.New("TypeError: keys() expected argument #1 to be `hash` got `array`")},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case bool:
+			testBooleanObject(t, evaluated, expected)
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case string:
+			testStringObject(t, evaluated, expected)
+		case error:
+			errObj, ok := evaluated.(*object.Error)
+			if !ok {
+				t.Errorf("object is not Error. got=%T (%+v)", evaluated, evaluated)
+				continue
+			}
+			if errObj.Message != expected.Error() {
+				t.Errorf("wrong error message. expected=%q, got=%q", expected, errObj.Message)
+			}
+		}
+	}
+}
+
+func TestPaths(t *testing.T) {
+	square := `p := path(); p.moveTo(0, 0); p.lineTo(10, 0); p.lineTo(10, 10); p.lineTo(0, 10); p.closePath();`
+
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`type(path())`, "path"},
+		{`length`, errors.New("identifier `length` not found")},
+		{square + `str(bounds(p))`, "[0, 0, 10, 10]"},
+		{square + `str(bounds(p.translate(5, -5).scale(2, 1)))`, "[10, -5, 20, 10]"},
+		{square + `contains(p, 5, 5)`, true},
+		{square + `contains(p, 15, 5)`, false},
+		{square + `contains(p.rotate(0.5, 5, 5), 5, 5)`, true},
+		{square + `p.arc(5, 5, 2, 0, 6.3); contains(p, 5, 5, "evenodd")`, false},
+		{square + `p.foo`, errors.New("path has no method `foo`")},
+		{`bounds(1)`, errors.New("TypeError: bounds() expected argument #1 to be `path` got `int`")},
+		{`str(bounds(union([[0, 0], [2, 0], [2, 2], [0, 2]], [[1, 1], [3, 1], [3, 3], [1, 3]])))`, "[0, 0, 3, 3]"},
+		{square + `contains(difference(p, [[2, 2], [8, 2], [8, 8], [2, 8]]), 5, 5)`, false},
+		{square + `contains(difference(p, [[2, 2], [8, 2], [8, 8], [2, 8]]), 1, 1)`, true},
+		{square + `int(pathLength(p))`, 40},
+		{square + `str(pointAtLength(15, p))`, "[10, 5]"},
+		{square + `str(pointAtLength(100, p))`, "[0, 0]"},
+		{square + `int(degrees(tangentAtLength(25, p)))`, 180},
+		{`pointAtLength(1)`, nil},
+		{`tangentAtLength(1, 2)`, errors.New("TypeError: tangentAtLength() expected argument #2 to be `path` got `int`")},
+		{`xor([], 1)`, errors.New("TypeError: xor() argument #2 expected to be `path` or `array` got `int`")},
+		{square + `fill(p)`, nil},
+		{square + `stroke(p)`, nil},
+		{square + `fillAndStroke(p, 1)`, nil},
+		{`fill(true)`, nil},
+		{`stroke(1, 2)`, nil},
+		{`fillAndStroke("p")`, nil},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case bool:
+			testBooleanObject(t, evaluated, expected)
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case string:
+			testStringObject(t, evaluated, expected)
+		case nil:
+			testNullObject(t, evaluated)
+		case error:
+			errObj, ok := evaluated.(*object.Error)
+			if !ok {
+				t.Errorf("object is not Error. got=%T (%+v)", evaluated, evaluated)
+				continue
+			}
+			if errObj.Message != expected.Error() {
+				t.Errorf("wrong error message. expected=%q, got=%q", expected, errObj.Message)
+			}
+		}
+	}
+}
+
+func TestText(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`type(loadFont("bold"))`, "font"},
+		{`str(loadFont("mono"))`, "<font mono>"},
+		{`textFont(loadFont("italic"), 24)`, nil},
+		{`textFont("bold-italic")`, nil},
+		{`loadFont("fancy")`, errors.New("IOError: loadFont() - open fancy: no such file or directory (bundled fonts are: bold, bold-italic, italic, mono, mono-bold, regular)")},
+		{`textFont("fancy")`, errors.New("IOError: textFont() - open fancy: no such file or directory (bundled fonts are: bold, bold-italic, italic, mono, mono-bold, regular)")},
+		{`textFont("../testdata/image/dots.gif")`, errors.New("DecodeError: textFont() - freetype: invalid TrueType format: bad TTF version")},
+		{`int(textBox("a b c", 0, 0, 10))`, 0},
+		{`len(textBounds("a\nb"))`, 4},
+		{`textBox("a", 0, 0, 10, "justify")`, errors.New("ValueError: textBox() argument #5 must be `left`, `center` or `right`")},
+		{`textBox("a", 0, "y", 10)`, errors.New("TypeError: textBox() argument #3 expected to be `int` or `float` got `str`")},
+		{`textOnPath("abc", 10, "center")`, nil},
+		{`textOnPath("abc", 0, 1)`, errors.New("TypeError: textOnPath() expected argument #3 to be `str` got `int`")},
+		{`textFont(1)`, errors.New("TypeError: textFont() expected argument #1 to be `font` or `str` got `int`")},
+		{`textFont("mono", "x")`, errors.New("TypeError: textFont() argument #2 expected to be `int` or `float` got `str`")},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case string:
+			testStringObject(t, evaluated, expected)
+		case nil:
+			testNullObject(t, evaluated)
+		case error:
+			errObj, ok := evaluated.(*object.Error)
+			if !ok {
+				t.Errorf("object is not Error. got=%T (%+v)", evaluated, evaluated)
+				continue
+			}
+			if errObj.Message != expected.Error() {
+				t.Errorf("wrong error message. expected=%q, got=%q", expected, errObj.Message)
+			}
+		}
+	}
+}
+
+func TestImports(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`import "geom.g2d"; geom.square(3)`, 9},
+		{`import "geom.g2d"; geom.SIDE`, 10},
+		{`import "geom.g2d" as g; g.square(4)`, 16},
+		{`import "nested.g2d"; nested.cube(2)`, 8},
+		{`import "counter.g2d"; import "counter.g2d" as c; counter.inc(); c.inc()`, 2},
+		{`f := fn() { import "geom.g2d"; return geom.square(5) }; f()`, 25},
+		{`import "geom.g2d"; geom.nope`, errors.New("module `geom` has no member `nope`")},
+		{`import "missing.g2d"`, errors.New("ImportError: open ../testdata/import/missing.g2d: no such file or directory")},
+		{`import "cycle_a.g2d"`, errors.New("ImportError: import cycle detected: ../testdata/import/cycle_a.g2d -> ../testdata/import/cycle_b.g2d -> ../testdata/import/cycle_a.g2d")},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := parser.New(l)
+		program := p.ParseProgram()
+		env := object.NewEnvironment(&eval.MockGraphicContext{},
+			object.WithScriptPath("../testdata/import/main.g2d"))
+		evaluated := run(program, env)
+
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case error:
+			errObj, ok := evaluated.(*object.Error)
+			if !ok {
+				t.Errorf("no error object returned. got=%T(%+v)", evaluated, evaluated)
+				continue
+			}
+			if !strings.Contains(errObj.Message, expected.Error()) {
+				t.Errorf("wrong error message. expected=%q, got=%q", expected.Error(), errObj.Message)
+			}
+		}
+	}
+}
+
+func TestImages(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`type(imageGet("dots.gif"))`, "image"},
+		{`imageGet("missing.png")`, errors.New("IOError: imageGet() - open ../testdata/image/missing.png: no such file or directory")},
+		{`imageGet("../import/geom.g2d")`, errors.New("DecodeError: imageGet() - image: unknown format")},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := parser.New(l)
+		program := p.ParseProgram()
+		env := object.NewEnvironment(&eval.MockGraphicContext{},
+			object.WithScriptPath("../testdata/image/main.g2d"))
+		evaluated := run(program, env)
+
+		switch expected := tt.expected.(type) {
+		case string:
+			testStringObject(t, evaluated, expected)
+		case error:
+			errObj, ok := evaluated.(*object.Error)
+			if !ok {
+				t.Errorf("no error object returned. got=%T(%+v)", evaluated, evaluated)
+				continue
+			}
+			if errObj.Message != expected.Error() {
+				t.Errorf("wrong error message. expected=%q, got=%q", expected.Error(), errObj.Message)
+			}
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package eval
+
+// The functions below expose the semantics of the evaluator (operators,
+// indexing, iteration, imports and error locations) to the bytecode
+// virtual machine, so that both engines behave exactly the same.
+
+import (
+	"github.com/lucasepe/g2d/ast"
+	"github.com/lucasepe/g2d/lexer"
+	"github.com/lucasepe/g2d/object"
+	"github.com/lucasepe/g2d/token"
+)
+
+// SetLexer sets the lexer used for error location and returns the previous one
+func SetLexer(l *lexer.Lexer) *lexer.Lexer {
+	prev := lex
+	lex = l
+	return prev
+}
+
+// NewError returns an error object located at the token position
+func NewError(tok token.Token, format string, a ...interface{}) *object.Error {
+	return newError(tok, format, a...)
+}
+
+// NativeBool returns the cached Boolean object of the input value
+func NativeBool(input bool) *object.Boolean {
+	return fromNativeBoolean(input)
+}
+
+// Prefix applies the prefix operator to the right operand
+func Prefix(tok token.Token, operator string, right object.Object) object.Object {
+	return evalPrefixExpression(tok, operator, right)
+}
+
+// Infix applies the infix operator to the left and right operands
+func Infix(tok token.Token, operator string, left, right object.Object) object.Object {
+	return evalInfixExpression(tok, operator, left, right)
+}
+
+// Index returns the item of the left object at the specified index
+func Index(tok token.Token, left, index object.Object) object.Object {
+	return evalIndexExpression(tok, left, index)
+}
+
+// SetIndex sets the item of the left object at the specified index
+func SetIndex(tok token.Token, left, index, value object.Object) object.Object {
+	return evalIndexAssignment(tok, left, index, value)
+}
+
+// IsTruthy returns true if the object is neither null nor false
+func IsTruthy(obj object.Object) bool {
+	return isTruthy(obj)
+}
+
+// Iterate returns the items of the iterable object of a for loop
+func Iterate(tok token.Token, iterable object.Object) ([]object.Object, *object.Error) {
+	return iterate(tok, iterable)
+}
+
+// CaseMatches returns true if the value of the case selects the
+// block of the case for the value of the switch
+func CaseMatches(value, out object.Object) bool {
+	return caseMatches(value, out)
+}
+
+// ImportName returns the name bound by the import statement
+func ImportName(is *ast.ImportStatement) string {
+	return importName(is)
+}
+
+// ImportModule returns the module of the import statement, evaluating
+// its program (only once) with the run function.
+func ImportModule(is *ast.ImportStatement, env *object.Environment, run func(*ast.Program, *object.Environment) object.Object) object.Object {
+	return importModule(is, env, run)
+}
@@ -0,0 +1,310 @@
+// Package geom implements boolean operations (union, intersection,
+// difference and exclusive or) on polygons made of several contours.
+//
+// The edges of both polygons are split at all their intersections, then
+// only the fragments separating the inside from the outside of the result
+// are kept and linked together to form the resulting contours.
+package geom
+
+import (
+	"math"
+	"sort"
+)
+
+// Point is a point of a contour
+type Point struct {
+	X, Y float64
+}
+
+// Contour is a closed polygonal chain: the last point
+// is implicitly connected to the first one
+type Contour []Point
+
+// Polygon is a set of contours, the inside is given by the even-odd rule.
+// The contours of the polygons returned by the boolean operations do not
+// cross each other and wind the same way around the inside.
+type Polygon []Contour
+
+// Union returns the region inside a or b
+func Union(a, b Polygon) Polygon {
+	return clip(a, b, func(inA, inB bool) bool { return inA || inB })
+}
+
+// Intersection returns the region inside both a and b
+func Intersection(a, b Polygon) Polygon {
+	return clip(a, b, func(inA, inB bool) bool { return inA && inB })
+}
+
+// Difference returns the region inside a but not inside b
+func Difference(a, b Polygon) Polygon {
+	return clip(a, b, func(inA, inB bool) bool { return inA && !inB })
+}
+
+// Xor returns the region inside either a or b, but not inside both
+func Xor(a, b Polygon) Polygon {
+	return clip(a, b, func(inA, inB bool) bool { return inA != inB })
+}
+
+// Contains reports whether the point is inside the polygon
+func (p Polygon) Contains(x, y float64) bool {
+	inside := false
+	for _, c := range p {
+		n := len(c)
+		for i := 0; i < n; i++ {
+			a, b := c[i], c[(i+1)%n]
+			if (a.Y <= y) == (b.Y <= y) {
+				continue
+			}
+			if a.X+(y-a.Y)*(b.X-a.X)/(b.Y-a.Y) > x {
+				inside = !inside
+			}
+		}
+	}
+	return inside
+}
+
+type segment struct {
+	a, b Point
+}
+
+// split is a point where a segment must be split, t is its position
+type split struct {
+	t  float64
+	pt Point
+}
+
+func clip(a, b Polygon, op func(inA, inB bool) bool) Polygon {
+	segments := append(edges(a), edges(b)...)
+	if len(segments) == 0 {
+		return nil
+	}
+
+	// the inside of the fragments is probed at a distance eps
+	ext := extent(segments)
+	tol := 1e-9 * (1 + ext)
+	eps := 1e-6 * ext
+	segments = snap(segments, tol)
+
+	splits := make([][]split, len(segments))
+	for i := range segments {
+		for j := i + 1; j < len(segments); j++ {
+			intersect(segments, splits, i, j, tol)
+		}
+	}
+
+	// the fragments on the boundary of the result, the inside on their left
+	seen := map[segment]bool{}
+	var fragments []segment
+	for i, s := range segments {
+		points := []Point{s.a}
+		sort.Slice(splits[i], func(x, y int) bool { return splits[i][x].t < splits[i][y].t })
+		for _, el := range splits[i] {
+			points = append(points, el.pt)
+		}
+		points = append(points, s.b)
+
+		for k := 1; k < len(points); k++ {
+			f := segment{points[k-1], points[k]}
+			if f.a == f.b {
+				continue
+			}
+
+			l := math.Hypot(f.b.X-f.a.X, f.b.Y-f.a.Y)
+			dx, dy := (f.b.X-f.a.X)/l, (f.b.Y-f.a.Y)/l
+			mx, my := (f.a.X+f.b.X)/2, (f.a.Y+f.b.Y)/2
+			lx, ly := mx-dy*eps, my+dx*eps
+			rx, ry := mx+dy*eps, my-dx*eps
+
+			left := op(a.Contains(lx, ly), b.Contains(lx, ly))
+			right := op(a.Contains(rx, ry), b.Contains(rx, ry))
+			if left == right {
+				continue
+			}
+			if right {
+				f = segment{f.b, f.a}
+			}
+
+			// coincident edges of the two polygons give the same fragment
+			if seen[f] {
+				continue
+			}
+			seen[f] = true
+			fragments = append(fragments, f)
+		}
+	}
+
+	return link(fragments)
+}
+
+// edges returns the edges of all the contours of the polygon
+func edges(p Polygon) []segment {
+	var res []segment
+	for _, c := range p {
+		n := len(c)
+		for i := 0; i < n; i++ {
+			s := segment{c[i], c[(i+1)%n]}
+			if s.a != s.b {
+				res = append(res, s)
+			}
+		}
+	}
+	return res
+}
+
+// extent returns the size of the bounding box of the segments
+func extent(segments []segment) float64 {
+	x0, y0 := segments[0].a.X, segments[0].a.Y
+	x1, y1 := x0, y0
+	for _, s := range segments {
+		for _, p := range []Point{s.a, s.b} {
+			x0, y0 = math.Min(x0, p.X), math.Min(y0, p.Y)
+			x1, y1 = math.Max(x1, p.X), math.Max(y1, p.Y)
+		}
+	}
+	return math.Max(x1-x0, y1-y0)
+}
+
+// snap replaces the end points closer than the tolerance with the same
+// point, removing the segments that collapse
+func snap(segments []segment, tol float64) []segment {
+	var points []Point
+	canonical := func(p Point) Point {
+		for _, el := range points {
+			if near(p, el, tol) {
+				return el
+			}
+		}
+		points = append(points, p)
+		return p
+	}
+
+	res := segments[:0]
+	for _, s := range segments {
+		s.a, s.b = canonical(s.a), canonical(s.b)
+		if s.a != s.b {
+			res = append(res, s)
+		}
+	}
+	return res
+}
+
+// intersect records where the segments i and j must be split: the point
+// where they cross and the end points of each one lying on the other.
+// The same point is recorded on both segments, so that the fragments
+// can be linked by exact comparison.
+func intersect(segments []segment, splits [][]split, i, j int, tol float64) {
+	p, q := segments[i], segments[j]
+	if math.Max(p.a.X, p.b.X)+tol < math.Min(q.a.X, q.b.X) ||
+		math.Max(q.a.X, q.b.X)+tol < math.Min(p.a.X, p.b.X) ||
+		math.Max(p.a.Y, p.b.Y)+tol < math.Min(q.a.Y, q.b.Y) ||
+		math.Max(q.a.Y, q.b.Y)+tol < math.Min(p.a.Y, p.b.Y) {
+		return
+	}
+
+	touching := false
+	for _, el := range []struct {
+		k  int
+		s  segment
+		pt Point
+	}{{i, p, q.a}, {i, p, q.b}, {j, q, p.a}, {j, q, p.b}} {
+		if t, ok := onSegment(el.s, el.pt, tol); ok {
+			splits[el.k] = append(splits[el.k], split{t, el.pt})
+			touching = true
+		} else if near(el.pt, el.s.a, tol) || near(el.pt, el.s.b, tol) {
+			touching = true
+		}
+	}
+	if touching {
+		return
+	}
+
+	rx, ry := p.b.X-p.a.X, p.b.Y-p.a.Y
+	sx, sy := q.b.X-q.a.X, q.b.Y-q.a.Y
+	den := rx*sy - ry*sx
+	if den == 0 {
+		return
+	}
+
+	qx, qy := q.a.X-p.a.X, q.a.Y-p.a.Y
+	t := (qx*sy - qy*sx) / den
+	u := (qx*ry - qy*rx) / den
+	if t <= 0 || t >= 1 || u <= 0 || u >= 1 {
+		return
+	}
+
+	pt := Point{p.a.X + t*rx, p.a.Y + t*ry}
+	splits[i] = append(splits[i], split{t, pt})
+	splits[j] = append(splits[j], split{u, pt})
+}
+
+// onSegment reports whether the point lies inside the segment (end points
+// excluded) and its position along the segment
+func onSegment(s segment, p Point, tol float64) (float64, bool) {
+	dx, dy := s.b.X-s.a.X, s.b.Y-s.a.Y
+	l := math.Hypot(dx, dy)
+	if near(p, s.a, tol) || near(p, s.b, tol) {
+		return 0, false
+	}
+
+	// the distance from the line and the position along it
+	if math.Abs((p.X-s.a.X)*dy-(p.Y-s.a.Y)*dx)/l > tol {
+		return 0, false
+	}
+	t := ((p.X-s.a.X)*dx + (p.Y-s.a.Y)*dy) / (l * l)
+	return t, t > 0 && t < 1
+}
+
+func near(p, q Point, tol float64) bool {
+	return math.Abs(p.X-q.X) <= tol && math.Abs(p.Y-q.Y) <= tol
+}
+
+// link chains the fragments sharing the end points into contours
+func link(fragments []segment) Polygon {
+	next := map[Point][]int{}
+	for i, f := range fragments {
+		next[f.a] = append(next[f.a], i)
+	}
+
+	used := make([]bool, len(fragments))
+	var res Polygon
+	for i := range fragments {
+		if used[i] {
+			continue
+		}
+
+		var c Contour
+		for k := i; k >= 0 && !used[k]; {
+			used[k] = true
+			c = append(c, fragments[k].a)
+
+			end := fragments[k].b
+			k = -1
+			for _, el := range next[end] {
+				if !used[el] {
+					k = el
+					break
+				}
+			}
+		}
+
+		if c = simplify(c); len(c) >= 3 {
+			res = append(res, c)
+		}
+	}
+
+	return res
+}
+
+// simplify removes the points lying between two collinear edges
+func simplify(c Contour) Contour {
+	res := Contour{}
+	n := len(c)
+	for i, p := range c {
+		a, b := c[(i+n-1)%n], c[(i+1)%n]
+		if (p.X-a.X)*(b.Y-p.Y)-(p.Y-a.Y)*(b.X-p.X) == 0 {
+			continue
+		}
+		res = append(res, p)
+	}
+	return res
+}
@@ -0,0 +1,84 @@
+package geom
+
+import (
+	"math"
+	"testing"
+)
+
+func rect(x, y, w, h float64) Polygon {
+	return Polygon{{{x, y}, {x + w, y}, {x + w, y + h}, {x, y + h}}}
+}
+
+// area returns the area of the polygon, its contours
+// winding the same way around the inside
+func area(p Polygon) float64 {
+	res := 0.0
+	for _, c := range p {
+		n := len(c)
+		for i := 0; i < n; i++ {
+			a, b := c[i], c[(i+1)%n]
+			res += a.X*b.Y - b.X*a.Y
+		}
+	}
+	return math.Abs(res / 2)
+}
+
+func TestOperations(t *testing.T) {
+	tests := []struct {
+		name     string
+		a, b     Polygon
+		op       func(a, b Polygon) Polygon
+		area     float64
+		contours int
+	}{
+		{"union", rect(0, 0, 2, 2), rect(1, 1, 2, 2), Union, 7, 1},
+		{"intersection", rect(0, 0, 2, 2), rect(1, 1, 2, 2), Intersection, 1, 1},
+		{"difference", rect(0, 0, 2, 2), rect(1, 1, 2, 2), Difference, 3, 1},
+		{"xor", rect(0, 0, 2, 2), rect(1, 1, 2, 2), Xor, 6, 1},
+		{"disjoint union", rect(0, 0, 1, 1), rect(5, 5, 1, 1), Union, 2, 2},
+		{"disjoint intersection", rect(0, 0, 1, 1), rect(5, 5, 1, 1), Intersection, 0, 0},
+		{"shared edge", rect(0, 0, 1, 1), rect(1, 0, 1, 1), Union, 2, 1},
+		{"hole", rect(0, 0, 4, 4), rect(1, 1, 2, 2), Difference, 12, 2},
+		{"same", rect(0, 0, 1, 1), rect(0, 0, 1, 1), Xor, 0, 0},
+
+		// the inside of the edges is probed closer than the gap
+		{"narrow gap", rect(0, 0, 1000, 1), rect(0, 1.01, 1000, 1), Union, 2000, 2},
+		{"tiny", rect(0, 0, 2e-6, 2e-6), rect(1e-6, 1e-6, 2e-6, 2e-6), Union, 7e-12, 1},
+	}
+
+	for _, tt := range tests {
+		res := tt.op(tt.a, tt.b)
+		if len(res) != tt.contours {
+			t.Errorf("%s: expected %d contours, got %d: %v", tt.name, tt.contours, len(res), res)
+		}
+		if got := area(res); math.Abs(got-tt.area) > 1e-9*math.Max(tt.area, 1e-9) {
+			t.Errorf("%s: expected area %f, got %f", tt.name, tt.area, got)
+		}
+	}
+}
+
+func TestCircles(t *testing.T) {
+	circle := func(cx, cy, r float64) Polygon {
+		c := Contour{}
+		for i := 0; i < 360; i++ {
+			a := float64(i) * math.Pi / 180
+			c = append(c, Point{cx + r*math.Cos(a), cy + r*math.Sin(a)})
+		}
+		return Polygon{c}
+	}
+
+	a, b := circle(0, 0, 1), circle(1, 0, 1)
+	union, inter := area(Union(a, b)), area(Intersection(a, b))
+	if math.Abs(union+inter-area(a)-area(b)) > 1e-9 {
+		t.Errorf("union (%f) plus intersection (%f) must be the sum of the areas", union, inter)
+	}
+
+	// the lens of two unit circles at distance 1
+	if lens := 2*math.Pi/3 - math.Sqrt(3)/2; math.Abs(inter-lens) > 1e-3 {
+		t.Errorf("expected intersection area %f, got %f", lens, inter)
+	}
+
+	if !Difference(a, b).Contains(-0.5, 0) || Difference(a, b).Contains(0.5, 0) {
+		t.Errorf("wrong difference")
+	}
+}
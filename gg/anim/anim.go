@@ -0,0 +1,65 @@
+package anim
+
+import (
+	"fmt"
+	"image"
+	"image/draw"
+	"io"
+)
+
+// Animation collects the frames of an animation played
+// at a constant frame rate.
+type Animation struct {
+	fps    float64
+	frames []*image.RGBA
+}
+
+// NewAnimation creates an animation with the specified frames per second.
+func NewAnimation(fps float64) *Animation {
+	return &Animation{fps: fps}
+}
+
+// FPS returns the animation frames per second.
+func (a *Animation) FPS() float64 { return a.fps }
+
+// Len returns the number of frames.
+func (a *Animation) Len() int { return len(a.frames) }
+
+// AddFrame adds a copy of the specified image as a new frame.
+// All the frames must have the same size.
+func (a *Animation) AddFrame(im image.Image) error {
+	b := im.Bounds()
+	if len(a.frames) > 0 {
+		if s := a.frames[0].Bounds().Size(); s != b.Size() {
+			return fmt.Errorf("frame size %dx%d differs from the animation size %dx%d",
+				b.Dx(), b.Dy(), s.X, s.Y)
+		}
+	}
+
+	res := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(res, res.Bounds(), im, b.Min, draw.Src)
+	a.frames = append(a.frames, res)
+	return nil
+}
+
+// Formats returns the supported animation formats.
+func (a *Animation) Formats() []string {
+	return []string{"gif", "png", "apng"}
+}
+
+// Encode writes the animation to w using the specified format.
+// Both "png" and "apng" produce an animated PNG.
+func (a *Animation) Encode(w io.Writer, format string) error {
+	if len(a.frames) == 0 {
+		return fmt.Errorf("animation without frames")
+	}
+
+	switch format {
+	case "gif":
+		return a.encodeGIF(w)
+	case "png", "apng":
+		return a.encodeAPNG(w)
+	default:
+		return fmt.Errorf("unsupported animation format '%s'", format)
+	}
+}
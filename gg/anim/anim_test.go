@@ -0,0 +1,223 @@
+package anim
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/png"
+	"strings"
+	"testing"
+)
+
+var frameColors = []color.RGBA{
+	{255, 0, 0, 255},
+	{0, 255, 0, 255},
+	{0, 0, 255, 255},
+}
+
+// newAnimation returns an animation whose frames are filled with
+// the frame colors, with a transparent top left pixel
+func newAnimation(t *testing.T, fps float64) *Animation {
+	t.Helper()
+
+	res := NewAnimation(fps)
+	for _, c := range frameColors {
+		im := image.NewRGBA(image.Rect(0, 0, 6, 4))
+		for y := 0; y < 4; y++ {
+			for x := 0; x < 6; x++ {
+				im.SetRGBA(x, y, c)
+			}
+		}
+		im.SetRGBA(0, 0, color.RGBA{})
+
+		if err := res.AddFrame(im); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return res
+}
+
+func encode(t *testing.T, a *Animation, format string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if err := a.Encode(&buf, format); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func sameColor(a, b color.Color) bool {
+	r0, g0, b0, a0 := a.RGBA()
+	r1, g1, b1, a1 := b.RGBA()
+	return r0 == r1 && g0 == g1 && b0 == b1 && a0 == a1
+}
+
+func TestAddFrame(t *testing.T) {
+	a := NewAnimation(10)
+	if err := a.AddFrame(image.NewRGBA(image.Rect(0, 0, 4, 4))); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.AddFrame(image.NewRGBA(image.Rect(0, 0, 5, 4))); err == nil {
+		t.Errorf("expected an error adding a frame with a different size")
+	}
+	if a.Len() != 1 {
+		t.Errorf("wrong number of frames. expected=1, got=%d", a.Len())
+	}
+
+	var buf bytes.Buffer
+	if err := NewAnimation(10).Encode(&buf, "gif"); err == nil {
+		t.Errorf("expected an error encoding an animation without frames")
+	}
+	if err := a.Encode(&buf, "webp"); err == nil {
+		t.Errorf("expected an error encoding an unsupported format")
+	}
+}
+
+func TestGIF(t *testing.T) {
+	tests := []struct {
+		fps   float64
+		delay int
+	}{
+		{10, 10},
+		{24, 4},
+		{0.5, 200},
+		{100, 2},
+	}
+
+	for _, tt := range tests {
+		res, err := gif.DecodeAll(bytes.NewReader(encode(t, newAnimation(t, tt.fps), "gif")))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(res.Image) != len(frameColors) {
+			t.Fatalf("wrong number of frames. expected=%d, got=%d", len(frameColors), len(res.Image))
+		}
+		if res.LoopCount != 0 {
+			t.Errorf("the animation must loop forever. got loop count=%d", res.LoopCount)
+		}
+
+		for i, el := range res.Image {
+			if res.Delay[i] != tt.delay {
+				t.Errorf("fps %g: wrong delay of frame #%d. expected=%d, got=%d", tt.fps, i, tt.delay, res.Delay[i])
+			}
+			if !sameColor(el.At(3, 2), frameColors[i]) {
+				t.Errorf("wrong color of frame #%d. expected=%v, got=%v", i, frameColors[i], el.At(3, 2))
+			}
+			if _, _, _, a := el.At(0, 0).RGBA(); a != 0 {
+				t.Errorf("the transparent pixel of frame #%d is opaque", i)
+			}
+		}
+	}
+}
+
+type chunk struct {
+	name string
+	data []byte
+}
+
+// chunks splits the PNG file in chunks, checking their CRC
+func chunks(t *testing.T, data []byte) []chunk {
+	t.Helper()
+
+	if !bytes.HasPrefix(data, []byte(pngHeader)) {
+		t.Fatalf("missing PNG signature")
+	}
+	data = data[len(pngHeader):]
+
+	var res []chunk
+	for len(data) > 0 {
+		if len(data) < 12 {
+			t.Fatalf("truncated chunk")
+		}
+		n := int(binary.BigEndian.Uint32(data))
+		if len(data) < 12+n {
+			t.Fatalf("truncated chunk")
+		}
+
+		c := chunk{string(data[4:8]), data[8 : 8+n]}
+		if crc := binary.BigEndian.Uint32(data[8+n:]); crc != crc32.ChecksumIEEE(data[4:8+n]) {
+			t.Errorf("wrong CRC of chunk %s", c.name)
+		}
+		res = append(res, c)
+		data = data[12+n:]
+	}
+	return res
+}
+
+func TestAPNG(t *testing.T) {
+	data := encode(t, newAnimation(t, 25), "apng")
+
+	// the default image is the first frame
+	im, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !sameColor(im.At(3, 2), frameColors[0]) {
+		t.Errorf("wrong color of the default image. expected=%v, got=%v", frameColors[0], im.At(3, 2))
+	}
+	if _, _, _, a := im.At(0, 0).RGBA(); a != 0 {
+		t.Errorf("the transparent pixel of the default image is opaque")
+	}
+
+	list := chunks(t, data)
+
+	var names []string
+	for _, el := range list {
+		names = append(names, el.name)
+	}
+	expected := "IHDR acTL fcTL IDAT fcTL fdAT fcTL fdAT IEND"
+	if got := strings.Join(names, " "); got != expected {
+		t.Fatalf("wrong chunks. expected=%q, got=%q", expected, got)
+	}
+
+	actl := list[1].data
+	if n := binary.BigEndian.Uint32(actl); n != uint32(len(frameColors)) {
+		t.Errorf("wrong number of frames. expected=%d, got=%d", len(frameColors), n)
+	}
+	if plays := binary.BigEndian.Uint32(actl[4:]); plays != 0 {
+		t.Errorf("the animation must loop forever. got plays=%d", plays)
+	}
+
+	seq := uint32(0)
+	frame := 0
+	for _, el := range list {
+		switch el.name {
+		case "fcTL":
+			if got := binary.BigEndian.Uint32(el.data); got != seq {
+				t.Errorf("wrong fcTL sequence number. expected=%d, got=%d", seq, got)
+			}
+			seq++
+
+			num, den := binary.BigEndian.Uint16(el.data[20:]), binary.BigEndian.Uint16(el.data[22:])
+			if num != 40 || den != 1000 {
+				t.Errorf("wrong delay. expected=40/1000, got=%d/%d", num, den)
+			}
+		case "fdAT":
+			if got := binary.BigEndian.Uint32(el.data); got != seq {
+				t.Errorf("wrong fdAT sequence number. expected=%d, got=%d", seq, got)
+			}
+			seq++
+
+			// the frame data decodes as a standalone PNG
+			frame++
+			var buf bytes.Buffer
+			buf.WriteString(pngHeader)
+			writeChunk(&buf, "IHDR", list[0].data)
+			writeChunk(&buf, "IDAT", el.data[4:])
+			writeChunk(&buf, "IEND", nil)
+
+			im, err := png.Decode(&buf)
+			if err != nil {
+				t.Fatalf("frame #%d: %s", frame, err)
+			}
+			if !sameColor(im.At(3, 2), frameColors[frame]) {
+				t.Errorf("wrong color of frame #%d. expected=%v, got=%v", frame, frameColors[frame], im.At(3, 2))
+			}
+		}
+	}
+}
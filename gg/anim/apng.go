@@ -0,0 +1,123 @@
+package anim
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"io"
+	"math"
+)
+
+const pngHeader = "\x89PNG\r\n\x1a\n"
+
+// encodeAPNG writes an animated PNG (https://wiki.mozilla.org/APNG_Specification),
+// all the frames are stored as 8-bit RGBA images.
+func (a *Animation) encodeAPNG(w io.Writer) error {
+	s := a.frames[0].Bounds().Size()
+
+	// delays are expressed as fractions of second
+	delay := math.Round(1000 / a.fps)
+	if delay > math.MaxUint16 {
+		delay = math.MaxUint16
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(pngHeader)
+
+	ihdr := make([]byte, 13)
+	binary.BigEndian.PutUint32(ihdr[0:], uint32(s.X))
+	binary.BigEndian.PutUint32(ihdr[4:], uint32(s.Y))
+	ihdr[8] = 8 // bit depth
+	ihdr[9] = 6 // color type: truecolor with alpha
+	writeChunk(&buf, "IHDR", ihdr)
+
+	actl := make([]byte, 8)
+	binary.BigEndian.PutUint32(actl[0:], uint32(len(a.frames)))
+	binary.BigEndian.PutUint32(actl[4:], 0) // loop forever
+	writeChunk(&buf, "acTL", actl)
+
+	seq := uint32(0)
+	for i, el := range a.frames {
+		fctl := make([]byte, 26)
+		binary.BigEndian.PutUint32(fctl[0:], seq)
+		binary.BigEndian.PutUint32(fctl[4:], uint32(s.X))
+		binary.BigEndian.PutUint32(fctl[8:], uint32(s.Y))
+		binary.BigEndian.PutUint16(fctl[20:], uint16(delay))
+		binary.BigEndian.PutUint16(fctl[22:], 1000)
+		fctl[24] = 1 // dispose to transparent black
+		fctl[25] = 0 // replace the frame area
+		writeChunk(&buf, "fcTL", fctl)
+		seq++
+
+		data, err := compressFrame(el)
+		if err != nil {
+			return err
+		}
+
+		if i == 0 {
+			writeChunk(&buf, "IDAT", data)
+			continue
+		}
+
+		fdat := make([]byte, 4, 4+len(data))
+		binary.BigEndian.PutUint32(fdat, seq)
+		writeChunk(&buf, "fdAT", append(fdat, data...))
+		seq++
+	}
+
+	writeChunk(&buf, "IEND", nil)
+
+	_, err := buf.WriteTo(w)
+	return err
+}
+
+// compressFrame returns the zlib compressed scanlines of the
+// image as non premultiplied RGBA, each one filtered with "Sub".
+func compressFrame(im *image.RGBA) ([]byte, error) {
+	b := im.Bounds()
+	stride := 4 * b.Dx()
+
+	var buf bytes.Buffer
+	zw := zlib.NewWriter(&buf)
+
+	row := make([]byte, 1+stride)
+	row[0] = 1 // filter type: Sub
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		var prev color.NRGBA
+		for x := b.Min.X; x < b.Max.X; x++ {
+			c := color.NRGBAModel.Convert(im.RGBAAt(x, y)).(color.NRGBA)
+			i := 1 + 4*(x-b.Min.X)
+			row[i+0] = c.R - prev.R
+			row[i+1] = c.G - prev.G
+			row[i+2] = c.B - prev.B
+			row[i+3] = c.A - prev.A
+			prev = c
+		}
+		if _, err := zw.Write(row); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func writeChunk(w *bytes.Buffer, name string, data []byte) {
+	var n [4]byte
+	binary.BigEndian.PutUint32(n[:], uint32(len(data)))
+	w.Write(n[:])
+
+	crc := crc32.NewIEEE()
+	crc.Write([]byte(name))
+	crc.Write(data)
+
+	w.WriteString(name)
+	w.Write(data)
+	binary.BigEndian.PutUint32(n[:], crc.Sum32())
+	w.Write(n[:])
+}
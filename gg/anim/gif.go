@@ -0,0 +1,170 @@
+package anim
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"image/gif"
+	"io"
+	"math"
+	"sort"
+)
+
+func (a *Animation) encodeGIF(w io.Writer) error {
+	// delays are expressed in hundredths of second; most
+	// viewers slow down the frames faster than 20ms
+	delay := int(math.Round(100 / a.fps))
+	if delay < 2 {
+		delay = 2
+	}
+
+	res := &gif.GIF{}
+	for _, el := range a.frames {
+		src := flatten(el)
+		dst := image.NewPaletted(src.Bounds(), quantize(src, 256))
+		draw.FloydSteinberg.Draw(dst, dst.Bounds(), src, image.Point{})
+
+		res.Image = append(res.Image, dst)
+		res.Delay = append(res.Delay, delay)
+		res.Disposal = append(res.Disposal, gif.DisposalBackground)
+	}
+
+	return gif.EncodeAll(w, res)
+}
+
+// flatten converts the image to fully opaque and fully transparent
+// pixels only, since GIF has no partial transparency.
+func flatten(im *image.RGBA) *image.RGBA {
+	res := image.NewRGBA(im.Bounds())
+	for i := 0; i < len(im.Pix); i += 4 {
+		c := color.RGBA{im.Pix[i], im.Pix[i+1], im.Pix[i+2], im.Pix[i+3]}
+		if c.A < 128 {
+			continue
+		}
+		nc := color.NRGBAModel.Convert(c).(color.NRGBA)
+		res.Pix[i], res.Pix[i+1], res.Pix[i+2], res.Pix[i+3] = nc.R, nc.G, nc.B, 255
+	}
+	return res
+}
+
+// quantize builds a palette of at most n colors using the median cut
+// algorithm; transparent pixels get their own palette entry.
+func quantize(im *image.RGBA, n int) color.Palette {
+	var pixels, distinct []color.RGBA
+	seen := map[color.RGBA]bool{}
+	transparent := false
+
+	// sample a bounded amount of pixels to keep big frames fast
+	step := 1 + len(im.Pix)/4/65536
+	for i := 0; i < len(im.Pix); i += 4 * step {
+		c := color.RGBA{im.Pix[i], im.Pix[i+1], im.Pix[i+2], im.Pix[i+3]}
+		if c.A == 0 {
+			transparent = true
+			continue
+		}
+		pixels = append(pixels, c)
+		if !seen[c] && len(distinct) <= n {
+			seen[c] = true
+			distinct = append(distinct, c)
+		}
+	}
+
+	var res color.Palette
+	if transparent {
+		res = append(res, color.RGBA{})
+		n--
+	}
+
+	// few colors: no need to approximate them
+	if len(distinct) <= n {
+		for _, c := range distinct {
+			res = append(res, c)
+		}
+		return res
+	}
+
+	boxes := []colorBox{pixels}
+	for len(boxes) < n {
+		idx, span := -1, 0
+		for i, el := range boxes {
+			if _, s := el.span(); s > span {
+				idx, span = i, s
+			}
+		}
+		if idx < 0 {
+			break
+		}
+
+		a, b := boxes[idx].split()
+		boxes[idx] = a
+		boxes = append(boxes, b)
+	}
+
+	for _, el := range boxes {
+		res = append(res, el.average())
+	}
+	return res
+}
+
+type colorBox []color.RGBA
+
+// span returns the channel with the widest range of values and the range.
+func (cb colorBox) span() (int, int) {
+	if len(cb) < 2 {
+		return 0, 0
+	}
+
+	lo := [3]int{255, 255, 255}
+	hi := [3]int{}
+	for _, c := range cb {
+		for i, v := range [3]uint8{c.R, c.G, c.B} {
+			if int(v) < lo[i] {
+				lo[i] = int(v)
+			}
+			if int(v) > hi[i] {
+				hi[i] = int(v)
+			}
+		}
+	}
+
+	ch := 0
+	for i := 1; i < 3; i++ {
+		if hi[i]-lo[i] > hi[ch]-lo[ch] {
+			ch = i
+		}
+	}
+	return ch, hi[ch] - lo[ch]
+}
+
+// split divides the box at the median of the widest channel.
+func (cb colorBox) split() (colorBox, colorBox) {
+	ch, _ := cb.span()
+	sort.Slice(cb, func(i, j int) bool {
+		return channel(cb[i], ch) < channel(cb[j], ch)
+	})
+
+	mid := len(cb) / 2
+	return cb[:mid], cb[mid:]
+}
+
+func (cb colorBox) average() color.RGBA {
+	var r, g, b int
+	for _, c := range cb {
+		r += int(c.R)
+		g += int(c.G)
+		b += int(c.B)
+	}
+	n := len(cb)
+	return color.RGBA{uint8(r / n), uint8(g / n), uint8(b / n), 255}
+}
+
+func channel(c color.RGBA, ch int) uint8 {
+	switch ch {
+	case 0:
+		return c.R
+	case 1:
+		return c.G
+	default:
+		return c.B
+	}
+}
@@ -0,0 +1,129 @@
+package gg
+
+import "math"
+
+// DrawEllipticalArc approximates an elliptical arc using quadratic Bézier
+// curves and adds it to the current path of the specified builder.
+// It is shared by all the backends that do not support arcs natively.
+func DrawEllipticalArc(dc PathBuilder, x, y, rx, ry, angle1, angle2 float64) {
+	const n = 16
+	for i := 0; i < n; i++ {
+		p1 := float64(i+0) / n
+		p2 := float64(i+1) / n
+		a1 := angle1 + (angle2-angle1)*p1
+		a2 := angle1 + (angle2-angle1)*p2
+		x0 := x + rx*math.Cos(a1)
+		y0 := y + ry*math.Sin(a1)
+		x1 := x + rx*math.Cos((a1+a2)/2)
+		y1 := y + ry*math.Sin((a1+a2)/2)
+		x2 := x + rx*math.Cos(a2)
+		y2 := y + ry*math.Sin(a2)
+		cx := 2*x1 - x0/2 - x2/2
+		cy := 2*y1 - y0/2 - y2/2
+		if i == 0 {
+			if _, _, ok := dc.CurrentPoint(); ok {
+				dc.LineTo(x0, y0)
+			} else {
+				dc.MoveTo(x0, y0)
+			}
+		}
+		dc.QuadraticTo(cx, cy, x2, y2)
+	}
+}
+
+// ArcTo adds a circular arc to the current sub-path of the specified builder,
+// using the given control points and radius.
+// The arc is automatically connected to the path's latest
+// point with a straight line, if necessary for the specified parameters.
+//
+// This method is commonly used for making rounded corners.
+// https://github.com/WebKit/webkit/blob/main/Source/WebCore/platform/graphics/cairo/PathCairo.cpp#L204
+func ArcTo(dc PathBuilder, x1, y1, x2, y2, radius float64) {
+	// Get current point
+	x0, y0, _ := dc.CurrentPoint()
+
+	// Draw only a straight line to p1 if any of the points are equal or the radius is zero
+	// or the points are collinear (triangle that the points form has area of zero value).
+	if (x1 == x0 && y1 == y0) || (x1 == x2 && y1 == y2) || (radius == 0) {
+		dc.LineTo(x1, y1)
+		return
+	}
+
+	p1p0 := point{x: x0 - x1, y: y0 - y1}
+	p1p2 := point{x: x2 - x1, y: y2 - y1}
+
+	p1p0Length := math.Hypot(p1p0.x, p1p0.y)
+	p1p2Length := math.Hypot(p1p2.x, p1p2.y)
+
+	cosPhi := (p1p0.x*p1p2.x + p1p0.y*p1p2.y) / (p1p0Length * p1p2Length)
+	// all points on a line logic
+	if cosPhi == -1 {
+		dc.LineTo(x1, y1)
+		return
+	}
+
+	if cosPhi == 1 {
+		// add infinite far away point
+		maxLength := 65535.0
+		factorMax := maxLength / p1p0Length
+		ep := point{
+			x: x0 + factorMax*p1p0.x,
+			y: y0 + factorMax*p1p0.y,
+		}
+		dc.LineTo(ep.x, ep.y)
+		return
+	}
+
+	tangent := radius / math.Tan(math.Acos(cosPhi)/2)
+	factorP1P0 := tangent / p1p0Length
+	tP1P0 := point{
+		x: x1 + factorP1P0*p1p0.x,
+		y: y1 + factorP1P0*p1p0.y,
+	}
+
+	orthP1P0 := point{x: p1p0.y, y: -p1p0.x}
+	orthP1P0Length := math.Hypot(orthP1P0.x, orthP1P0.y)
+	factorRa := radius / orthP1P0Length
+
+	// angle between orth_p1p0 and p1p2 to get the right vector orthographic to p1p0
+	cosAlpha := (orthP1P0.x*p1p2.x + orthP1P0.y*p1p2.y) / (orthP1P0Length * p1p2Length)
+	if cosAlpha < 0 {
+		orthP1P0 = point{x: -orthP1P0.x, y: -orthP1P0.y}
+	}
+
+	p := point{
+		x: tP1P0.x + factorRa*orthP1P0.x,
+		y: tP1P0.y + factorRa*orthP1P0.y,
+	}
+
+	// calculate angles for addArc
+	orthP1P0 = point{x: -orthP1P0.x, y: -orthP1P0.y}
+	sa := math.Acos(orthP1P0.x / orthP1P0Length)
+	if orthP1P0.y < 0 {
+		sa = 2*math.Pi - sa
+	}
+
+	factorP1P2 := tangent / p1p2Length
+	tP1P2 := point{
+		x: x1 + factorP1P2*p1p2.x,
+		y: y1 + factorP1P2*p1p2.y,
+	}
+
+	orthP1P2 := point{
+		x: tP1P2.x - p.x,
+		y: tP1P2.y - p.y,
+	}
+	orthP1P2Length := math.Hypot(orthP1P2.x, orthP1P2.y)
+
+	ea := math.Acos(orthP1P2.x / orthP1P2Length)
+	if orthP1P2.y <= 0 {
+		ea = 2*math.Pi - ea
+	}
+
+	dc.LineTo(tP1P0.x, tP1P0.y)
+	dc.DrawEllipticalArc(p.x, p.y, radius, radius, sa, ea)
+}
+
+type point struct {
+	x, y float64
+}
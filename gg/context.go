@@ -20,6 +20,7 @@ type LineJoin int
 const (
 	LineJoinRound LineJoin = iota
 	LineJoinBevel
+	LineJoinMiter
 )
 
 type FillRule int
@@ -52,6 +53,8 @@ type GraphicContext interface {
 	LineTo(x, y float64)
 	// QuadraticTo adds a quadratic Bézier curve to the current subpath
 	QuadraticTo(x1, y1, x2, y2 float64)
+	// CubicTo adds a cubic Bézier curve to the current subpath
+	CubicTo(x1, y1, x2, y2, x3, y3 float64)
 	// ArcTo adds a circular arc to the current sub-path, using
 	// the given control points and radius.
 	ArcTo(x1, y1, x2, y2, radius float64)
@@ -61,6 +64,11 @@ type GraphicContext interface {
 	ClosePath()
 	// CurrentPoint returns the current point of the current sub path
 	CurrentPoint() (float64, float64, bool)
+	// AppendPath adds the subpaths of the specified path to the current path
+	AppendPath(p *Path)
+	// CurrentPath returns a copy of the current path, in user space
+	// (untransformed by the current matrix)
+	CurrentPath() *Path
 
 	// SetStrokeColor sets the current stroke color
 	SetStrokeColor(r, g, b, a int)
@@ -68,6 +76,8 @@ type GraphicContext interface {
 	SetFillColor(r, g, b, a int)
 	// SetFillRule sets the current fill rule
 	SetFillRule(f FillRule)
+	// FillRule returns the current fill rule
+	FillRule() FillRule
 
 	// SetFillStyle sets current fill style
 	SetFillStyle(pattern Pattern)
@@ -84,6 +94,9 @@ type GraphicContext interface {
 	SetLineCap(cap LineCap)
 	// SetLineJoin sets the current line join
 	SetLineJoin(join LineJoin)
+	// SetMiterLimit sets the limit of the ratio of the miter length to the
+	// line width, beyond which the miter joins are drawn as bevel joins
+	SetMiterLimit(limit float64)
 	// SetLineDash sets the current dash
 	SetLineDash(dashes ...float64)
 	// SetLineDashOffset sets the initial offset into
@@ -110,8 +123,8 @@ type GraphicContext interface {
 	// returning a transformed position.
 	TransformPoint(x, y float64) (float64, float64)
 
-	// Push saves the current state of the context for later retrieval. These
-	// can be nested.
+	// Push saves the current state of the context, clipping region
+	// included, for later retrieval. These can be nested.
 	Push()
 	// Pop restores the last saved context state from the stack
 	Pop()
@@ -150,11 +163,19 @@ type GraphicContext interface {
 	// given the current font face.
 	MeasureString(s string) (w, h float64)
 
+	// FontMetrics returns how far the current font face rises above
+	// and descends below the baseline.
+	FontMetrics() (ascent, descent float64)
+
 	// Clip updates the clipping region by intersecting the current
 	// clipping region with the current path as it would be filled by dc.Fill().
 	// The path is cleared after this operation.
 	Clip()
 
+	// ClipPreserve updates the clipping region like Clip, but
+	// the path is preserved after this operation.
+	ClipPreserve()
+
 	// ResetClip clears the clipping region.
 	ResetClip()
 }
@@ -0,0 +1,20 @@
+package gg
+
+import "io"
+
+// Encoder is implemented by the graphic contexts able to serialize
+// their drawings (i.e. as PNG images, SVG or PDF documents).
+type Encoder interface {
+	// Encode writes the drawings to w using the specified format
+	// (the filename extension without the dot, i.e. "png").
+	Encode(w io.Writer, format string) error
+	// Formats returns the supported formats, the first one is the default.
+	Formats() []string
+}
+
+// Paginator is implemented by the graphic contexts producing multi-page
+// documents, where each snapshot adds a new page.
+type Paginator interface {
+	// ShowPage ends the current page and starts a new blank one.
+	ShowPage()
+}
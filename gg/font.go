@@ -0,0 +1,90 @@
+package gg
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/golang/freetype/truetype"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/gobolditalic"
+	"golang.org/x/image/font/gofont/goitalic"
+	"golang.org/x/image/font/gofont/gomono"
+	"golang.org/x/image/font/gofont/gomonobold"
+	"golang.org/x/image/font/gofont/goregular"
+)
+
+var (
+	fontsMu   sync.Mutex
+	fontsData = map[*truetype.Font][]byte{}
+	fonts     = map[string]*truetype.Font{}
+
+	// the Go fonts bundled in the executable, parsed on demand
+	bundled = map[string][]byte{
+		"regular":     goregular.TTF,
+		"bold":        gobold.TTF,
+		"italic":      goitalic.TTF,
+		"bold-italic": gobolditalic.TTF,
+		"mono":        gomono.TTF,
+		"mono-bold":   gomonobold.TTF,
+	}
+)
+
+// ParseFont parses the TrueType font data and remembers it, so that the
+// backends able to embed fonts (i.e. pdf) can retrieve the original data
+// using FontData. The name identifies the font, like the URI of its file:
+// parsing the same data again returns the same font, while new data
+// replaces the font, so that only the last version of each one is kept.
+func ParseFont(name string, ttf []byte) (*truetype.Font, error) {
+	fontsMu.Lock()
+	defer fontsMu.Unlock()
+
+	if f, ok := fonts[name]; ok {
+		if bytes.Equal(fontsData[f], ttf) {
+			return f, nil
+		}
+		delete(fontsData, f)
+		delete(fonts, name)
+	}
+
+	f, err := truetype.Parse(ttf)
+	if err != nil {
+		return nil, err
+	}
+
+	fonts[name] = f
+	fontsData[f] = ttf
+
+	return f, nil
+}
+
+// FontData returns the TrueType data of a font parsed with ParseFont.
+func FontData(f *truetype.Font) ([]byte, bool) {
+	fontsMu.Lock()
+	defer fontsMu.Unlock()
+
+	res, ok := fontsData[f]
+	return res, ok
+}
+
+// BundledFont returns the bundled Go font with the specified name
+// (see BundledFontNames), parsing it only once.
+func BundledFont(name string) (*truetype.Font, error) {
+	ttf, ok := bundled[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown font `%s`", name)
+	}
+
+	return ParseFont(name, ttf)
+}
+
+// BundledFontNames returns the sorted names of the bundled Go fonts.
+func BundledFontNames() []string {
+	res := make([]string, 0, len(bundled))
+	for k := range bundled {
+		res = append(res, k)
+	}
+	sort.Strings(res)
+	return res
+}
@@ -0,0 +1,64 @@
+package gg
+
+import (
+	"bytes"
+	"testing"
+
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goregular"
+)
+
+func TestParseFont(t *testing.T) {
+	// the data is read again on each load, i.e. by watch
+	a, err := ParseFont("fonts/a.ttf", append([]byte(nil), goregular.TTF...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := ParseFont("fonts/a.ttf", append([]byte(nil), goregular.TTF...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a != b {
+		t.Errorf("the same data has been parsed again")
+	}
+
+	// the font file changed
+	c, err := ParseFont("fonts/a.ttf", gobold.TTF)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c == a {
+		t.Errorf("the font has not been replaced")
+	}
+	if _, ok := FontData(a); ok {
+		t.Errorf("the data of the replaced font is still kept")
+	}
+	if data, ok := FontData(c); !ok || !bytes.Equal(data, gobold.TTF) {
+		t.Errorf("wrong data of the new font")
+	}
+
+	if _, err := ParseFont("fonts/b.ttf", []byte("not a font")); err == nil {
+		t.Errorf("expected an error parsing invalid data")
+	}
+	if _, err := ParseFont("fonts/a.ttf", []byte("not a font")); err == nil {
+		t.Errorf("expected an error parsing invalid data")
+	}
+}
+
+func TestBundledFont(t *testing.T) {
+	a, err := BundledFont("mono")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := BundledFont("mono")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a != b {
+		t.Errorf("the bundled font has been parsed again")
+	}
+
+	if _, err := BundledFont("fancy"); err == nil {
+		t.Errorf("expected an error loading an unknown font")
+	}
+}
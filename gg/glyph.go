@@ -0,0 +1,101 @@
+package gg
+
+import (
+	"github.com/golang/freetype/truetype"
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+// TextOutline adds to the path builder the outlines of the glyphs of
+// the specified text. The text baseline starts at x, y and the font size
+// is expressed in pixels. It returns the advance width of the text.
+//
+// It is used by the vector backends to render text as resolution
+// independent shapes.
+func TextOutline(pb PathBuilder, f *truetype.Font, size float64, s string, x, y float64) float64 {
+	scale := fixed.Int26_6(size * 64)
+	buf := &truetype.GlyphBuf{}
+
+	dot := x
+	prev, hasPrev := truetype.Index(0), false
+	for _, r := range s {
+		idx := f.Index(r)
+		if hasPrev {
+			dot += unfix(f.Kern(scale, prev, idx))
+		}
+		if err := buf.Load(f, scale, idx, font.HintingNone); err == nil {
+			start := 0
+			for _, end := range buf.Ends {
+				glyphContour(pb, buf.Points[start:end], dot, y)
+				start = end
+			}
+		}
+		dot += unfix(f.HMetric(scale, idx).AdvanceWidth)
+		prev, hasPrev = idx, true
+	}
+
+	return dot - x
+}
+
+// glyphContour converts a TrueType contour, made of on-curve and
+// off-curve points, into quadratic Bézier segments.
+// Based on drawContour in github.com/golang/freetype/truetype/face.go
+func glyphContour(pb PathBuilder, ps []truetype.Point, dx, dy float64) {
+	if len(ps) == 0 {
+		return
+	}
+
+	pt := func(p truetype.Point) (float64, float64) {
+		return dx + unfix(p.X), dy - unfix(p.Y)
+	}
+
+	mid := func(a, b truetype.Point) truetype.Point {
+		return truetype.Point{X: (a.X + b.X) / 2, Y: (a.Y + b.Y) / 2}
+	}
+
+	var start truetype.Point
+	if ps[0].Flags&0x01 != 0 {
+		start = ps[0]
+	} else {
+		last := ps[len(ps)-1]
+		if last.Flags&0x01 != 0 {
+			start = last
+		} else {
+			start = mid(ps[0], last)
+		}
+	}
+
+	pb.MoveTo(pt(start))
+	q0, on0 := start, true
+	for _, p := range ps {
+		q, on := p, p.Flags&0x01 != 0
+		if on {
+			if on0 {
+				pb.LineTo(pt(q))
+			} else {
+				x1, y1 := pt(q0)
+				x2, y2 := pt(q)
+				pb.QuadraticTo(x1, y1, x2, y2)
+			}
+		} else if !on0 {
+			x1, y1 := pt(q0)
+			x2, y2 := pt(mid(q0, q))
+			pb.QuadraticTo(x1, y1, x2, y2)
+		}
+		q0, on0 = q, on
+	}
+
+	// Close the curve.
+	if on0 {
+		pb.LineTo(pt(start))
+	} else {
+		x1, y1 := pt(q0)
+		x2, y2 := pt(start)
+		pb.QuadraticTo(x1, y1, x2, y2)
+	}
+	pb.ClosePath()
+}
+
+func unfix(x fixed.Int26_6) float64 {
+	return float64(x) / 64
+}
@@ -0,0 +1,244 @@
+package gg
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"sort"
+)
+
+// Transformer is implemented by the patterns defined in user space:
+// Transform returns the pattern as painted in device space
+// using the specified transformation matrix.
+type Transformer interface {
+	Transform(m Matrix) Pattern
+}
+
+// DevicePattern returns the pattern as painted in device space with
+// the current transformation matrix m.
+func DevicePattern(p Pattern, m Matrix) Pattern {
+	if t, ok := p.(Transformer); ok {
+		return t.Transform(m)
+	}
+	return p
+}
+
+// RenderPattern renders the pattern, in device space,
+// to an image with the specified size.
+func RenderPattern(p Pattern, w, h int) *image.NRGBA {
+	im := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			im.Set(x, y, p.ColorAt(x, y))
+		}
+	}
+	return im
+}
+
+// Stop is a color stop of a gradient
+type Stop struct {
+	Offset float64
+	Color  color.Color
+}
+
+// Stops is a list of color stops sorted by offset
+type Stops []Stop
+
+// Add adds a color stop, the offset ranges from 0.0 to 1.0
+func (s *Stops) Add(offset float64, c color.Color) {
+	offset = math.Max(0, math.Min(1, offset))
+	i := sort.Search(len(*s), func(i int) bool { return (*s)[i].Offset > offset })
+	*s = append(*s, Stop{})
+	copy((*s)[i+1:], (*s)[i:])
+	(*s)[i] = Stop{Offset: offset, Color: c}
+}
+
+// ColorAt returns the color at the offset t, interpolating the
+// colors of the surrounding stops
+func (s Stops) ColorAt(t float64) color.Color {
+	if len(s) == 0 {
+		return color.Transparent
+	}
+
+	if t <= s[0].Offset {
+		return s[0].Color
+	}
+
+	for i := 1; i < len(s); i++ {
+		if t < s[i].Offset {
+			s0, s1 := s[i-1], s[i]
+			return interpolateColor(s0.Color, s1.Color, (t-s0.Offset)/(s1.Offset-s0.Offset))
+		}
+	}
+
+	return s[len(s)-1].Color
+}
+
+func interpolateColor(c0, c1 color.Color, t float64) color.Color {
+	a := color.NRGBAModel.Convert(c0).(color.NRGBA)
+	b := color.NRGBAModel.Convert(c1).(color.NRGBA)
+	lerp := func(x, y uint8) uint8 {
+		return uint8(math.Round(float64(x) + t*(float64(y)-float64(x))))
+	}
+	return color.NRGBA{lerp(a.R, b.R), lerp(a.G, b.G), lerp(a.B, b.B), lerp(a.A, b.A)}
+}
+
+// gradient holds the color stops and the transformation matrix,
+// from user space to device space, shared by all the gradients
+type gradient struct {
+	Stops  Stops
+	Matrix Matrix
+
+	inverse Matrix
+}
+
+func newGradient() gradient {
+	return gradient{Matrix: Identity(), inverse: Identity()}
+}
+
+// AddColorStop adds a color stop, the offset ranges from 0.0 to 1.0
+func (g *gradient) AddColorStop(offset float64, c color.Color) {
+	g.Stops.Add(offset, c)
+}
+
+func (g gradient) transform(m Matrix) gradient {
+	g.Matrix = g.Matrix.Multiply(m)
+	g.inverse = g.Matrix.Invert()
+	return g
+}
+
+// userPoint returns the center of the pixel in user space
+func (g *gradient) userPoint(x, y int) (float64, float64) {
+	return g.inverse.TransformPoint(float64(x)+0.5, float64(y)+0.5)
+}
+
+// LinearGradient is a gradient along the line from (X0, Y0) to (X1, Y1)
+type LinearGradient struct {
+	gradient
+	X0, Y0, X1, Y1 float64
+}
+
+// NewLinearGradient returns a gradient along the line
+// from the start point (x0, y0) to the end point (x1, y1)
+func NewLinearGradient(x0, y0, x1, y1 float64) *LinearGradient {
+	return &LinearGradient{gradient: newGradient(), X0: x0, Y0: y0, X1: x1, Y1: y1}
+}
+
+// ColorAt satisfies the Pattern interface.
+func (g *LinearGradient) ColorAt(x, y int) color.Color {
+	px, py := g.userPoint(x, y)
+
+	dx, dy := g.X1-g.X0, g.Y1-g.Y0
+	d := dx*dx + dy*dy
+	if d == 0 {
+		return color.Transparent
+	}
+
+	return g.Stops.ColorAt(((px-g.X0)*dx + (py-g.Y0)*dy) / d)
+}
+
+// Transform satisfies the Transformer interface.
+func (g *LinearGradient) Transform(m Matrix) Pattern {
+	res := *g
+	res.gradient = g.transform(m)
+	return &res
+}
+
+// RadialGradient is a gradient between the start circle, with center
+// (X0, Y0) and radius R0, and the end circle with center (X1, Y1) and
+// radius R1. The circles are interpolated in a cone, where the color
+// of each circle is the color of the stop at the same offset.
+type RadialGradient struct {
+	gradient
+	X0, Y0, R0, X1, Y1, R1 float64
+}
+
+// NewRadialGradient returns a gradient between the start circle
+// (x0, y0, r0) and the end circle (x1, y1, r1)
+func NewRadialGradient(x0, y0, r0, x1, y1, r1 float64) *RadialGradient {
+	return &RadialGradient{gradient: newGradient(), X0: x0, Y0: y0, R0: r0, X1: x1, Y1: y1, R1: r1}
+}
+
+// ColorAt satisfies the Pattern interface.
+func (g *RadialGradient) ColorAt(x, y int) color.Color {
+	px, py := g.userPoint(x, y)
+
+	// find the largest t such that the point lies on the
+	// circle interpolated at t, with a non negative radius
+	cdx, cdy, dr := g.X1-g.X0, g.Y1-g.Y0, g.R1-g.R0
+	px, py = px-g.X0, py-g.Y0
+
+	a := cdx*cdx + cdy*cdy - dr*dr
+	b := px*cdx + py*cdy + g.R0*dr
+	c := px*px + py*py - g.R0*g.R0
+
+	valid := func(t float64) bool { return g.R0+t*dr >= 0 }
+
+	if a == 0 {
+		if b == 0 {
+			return color.Transparent
+		}
+		if t := 0.5 * c / b; valid(t) {
+			return g.Stops.ColorAt(t)
+		}
+		return color.Transparent
+	}
+
+	discr := b*b - a*c
+	if discr < 0 {
+		return color.Transparent
+	}
+
+	sq := math.Sqrt(discr)
+	t1, t2 := (b+sq)/a, (b-sq)/a
+	if t1 < t2 {
+		t1, t2 = t2, t1
+	}
+	if valid(t1) {
+		return g.Stops.ColorAt(t1)
+	}
+	if valid(t2) {
+		return g.Stops.ColorAt(t2)
+	}
+	return color.Transparent
+}
+
+// Transform satisfies the Transformer interface.
+func (g *RadialGradient) Transform(m Matrix) Pattern {
+	res := *g
+	res.gradient = g.transform(m)
+	return &res
+}
+
+// ConicGradient is a gradient around the center (X, Y), starting
+// at the angle Angle (in radians) and going clockwise
+type ConicGradient struct {
+	gradient
+	X, Y, Angle float64
+}
+
+// NewConicGradient returns a gradient around the center (x, y)
+// starting at the specified angle (in radians)
+func NewConicGradient(x, y, angle float64) *ConicGradient {
+	return &ConicGradient{gradient: newGradient(), X: x, Y: y, Angle: angle}
+}
+
+// ColorAt satisfies the Pattern interface.
+func (g *ConicGradient) ColorAt(x, y int) color.Color {
+	px, py := g.userPoint(x, y)
+
+	a := math.Atan2(py-g.Y, px-g.X) - g.Angle
+	a = math.Mod(a, 2*math.Pi)
+	if a < 0 {
+		a += 2 * math.Pi
+	}
+
+	return g.Stops.ColorAt(a / (2 * math.Pi))
+}
+
+// Transform satisfies the Transformer interface.
+func (g *ConicGradient) Transform(m Matrix) Pattern {
+	res := *g
+	res.gradient = g.transform(m)
+	return &res
+}
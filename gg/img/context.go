@@ -4,6 +4,9 @@ import (
 	"fmt"
 	"image"
 	"image/color"
+	"image/jpeg"
+	"image/png"
+	"io"
 	"math"
 	"os"
 
@@ -38,6 +41,7 @@ type Context struct {
 	lineWidth  float64
 	lineCap    gg.LineCap
 	lineJoin   gg.LineJoin
+	miterLimit float64
 	fillRule   gg.FillRule
 	font       *truetype.Font
 	fontSize   float64
@@ -59,6 +63,7 @@ func NewContextForRGBA(im *image.RGBA) *Context {
 		strokeColor:   color.Black,
 		strokePattern: gg.NewSolidPattern(color.Black),
 		lineWidth:     1,
+		miterLimit:    10,
 		fillRule:      gg.FillRuleWinding,
 		fontSize:      14,
 		matrix:        gg.Identity(),
@@ -70,6 +75,23 @@ func NewContextForRGBA(im *image.RGBA) *Context {
 // Image returns the image that has been drawn by this context.
 func (dc *Context) Image() image.Image { return dc.im }
 
+// Formats returns the supported image formats.
+func (dc *Context) Formats() []string {
+	return []string{"png", "jpg", "jpeg"}
+}
+
+// Encode writes the image to w using the specified format.
+func (dc *Context) Encode(w io.Writer, format string) error {
+	switch format {
+	case "png":
+		return png.Encode(w, dc.im)
+	case "jpg", "jpeg":
+		return jpeg.Encode(w, dc.im, &jpeg.Options{Quality: 95})
+	default:
+		return fmt.Errorf("unsupported image format '%s'", format)
+	}
+}
+
 // Width returns the width of the image in pixels.
 func (dc *Context) Width() float64 {
 	res := dc.im.Bounds().Size().X
@@ -136,6 +158,29 @@ func (dc *Context) QuadraticTo(x1, y1, x2, y2 float64) {
 	dc.current = p2
 }
 
+// CubicTo adds a cubic bezier curve to the current path starting at the
+// current point. If there is no current point, it first performs
+// MoveTo(x1, y1). The stroker cannot handle cubic segments, so the stroke
+// path gets the flattened curve.
+func (dc *Context) CubicTo(x1, y1, x2, y2, x3, y3 float64) {
+	if !dc.hasCurrent {
+		dc.MoveTo(x1, y1)
+	}
+	x0, y0 := dc.current.X, dc.current.Y
+	x1, y1 = dc.TransformPoint(x1, y1)
+	x2, y2 = dc.TransformPoint(x2, y2)
+	x3, y3 = dc.TransformPoint(x3, y3)
+	points := CubicBezier(x0, y0, x1, y1, x2, y2, x3, y3)
+	for _, p := range points[1:] {
+		dc.strokePath.Add1(p.Fixed())
+	}
+	p1 := Point{x1, y1}
+	p2 := Point{x2, y2}
+	p3 := Point{x3, y3}
+	dc.fillPath.Add3(p1.Fixed(), p2.Fixed(), p3.Fixed())
+	dc.current = p3
+}
+
 // ClosePath adds a line segment from the current point to the beginning
 // of the current subpath. If there is no current point, this is a no-op.
 func (dc *Context) ClosePath() {
@@ -174,97 +219,41 @@ func (dc *Context) SetFillRule(fillRule gg.FillRule) {
 	dc.fillRule = fillRule
 }
 
+// FillRule returns the current fill rule
+func (dc *Context) FillRule() gg.FillRule { return dc.fillRule }
+
+// AppendPath adds the subpaths of the specified path, transformed by the
+// current matrix, to the current path
+func (dc *Context) AppendPath(p *gg.Path) {
+	gg.AppendPath(dc, p)
+}
+
+// CurrentPath returns a copy of the current path in user space,
+// with the curves flattened
+func (dc *Context) CurrentPath() *gg.Path {
+	inverse := dc.matrix.Invert()
+	res := gg.NewPath()
+	for _, line := range flattenPath(dc.strokePath) {
+		for i, p := range line {
+			x, y := inverse.TransformPoint(p.X, p.Y)
+			if i == 0 {
+				res.MoveTo(x, y)
+			} else {
+				res.LineTo(x, y)
+			}
+		}
+	}
+	return res
+}
+
 // ArcTo adds a circular arc to the current sub-path, using
 // the given control points and radius.
 // The arc is automatically connected to the path's latest
 // point with a straight line, if necessary for the specified parameters.
 //
 // This method is commonly used for making rounded corners.
-// https://github.com/WebKit/webkit/blob/main/Source/WebCore/platform/graphics/cairo/PathCairo.cpp#L204
 func (dc *Context) ArcTo(x1, y1, x2, y2, radius float64) {
-	// Get current point
-	x0, y0, _ := dc.CurrentPoint()
-
-	// Draw only a straight line to p1 if any of the points are equal or the radius is zero
-	// or the points are collinear (triangle that the points form has area of zero value).
-	if (x1 == x0 && y1 == y0) || (x1 == x2 && y1 == y2) || (radius == 0) {
-		dc.LineTo(x1, y1)
-		return
-	}
-
-	p1p0 := Point{X: x0 - x1, Y: y0 - y1}
-	p1p2 := Point{X: x2 - x1, Y: y2 - y1}
-
-	p1p0Length := math.Hypot(p1p0.X, p1p0.Y)
-	p1p2Length := math.Hypot(p1p2.X, p1p2.Y)
-
-	cosPhi := (p1p0.X*p1p2.X + p1p0.Y*p1p2.Y) / (p1p0Length * p1p2Length)
-	// all points on a line logic
-	if cosPhi == -1 {
-		dc.LineTo(x1, y1)
-		return
-	}
-
-	if cosPhi == 1 {
-		// add infinite far away point
-		maxLength := 65535.0
-		factorMax := maxLength / p1p0Length
-		ep := Point{
-			X: x0 + factorMax*p1p0.X,
-			Y: y0 + factorMax*p1p0.Y,
-		}
-		dc.LineTo(ep.X, ep.Y)
-		return
-	}
-
-	tangent := radius / math.Tan(math.Acos(cosPhi)/2)
-	factorP1P0 := tangent / p1p0Length
-	tP1P0 := Point{
-		X: x1 + factorP1P0*p1p0.X,
-		Y: y1 + factorP1P0*p1p0.Y,
-	}
-
-	orthP1P0 := Point{X: p1p0.Y, Y: -p1p0.X}
-	orthP1P0Length := math.Hypot(orthP1P0.X, orthP1P0.Y)
-	factorRa := radius / orthP1P0Length
-
-	// angle between orth_p1p0 and p1p2 to get the right vector orthographic to p1p0
-	cosAlpha := (orthP1P0.X*p1p2.X + orthP1P0.Y*p1p2.Y) / (orthP1P0Length * p1p2Length)
-	if cosAlpha < 0 {
-		orthP1P0 = Point{X: -orthP1P0.X, Y: -orthP1P0.Y}
-	}
-
-	p := Point{
-		X: tP1P0.X + factorRa*orthP1P0.X,
-		Y: tP1P0.Y + factorRa*orthP1P0.Y,
-	}
-
-	// calculate angles for addArc
-	orthP1P0 = Point{X: -orthP1P0.X, Y: -orthP1P0.Y}
-	sa := math.Acos(orthP1P0.X / orthP1P0Length)
-	if orthP1P0.Y < 0 {
-		sa = 2*math.Pi - sa
-	}
-
-	factorP1P2 := tangent / p1p2Length
-	tP1P2 := Point{
-		X: x1 + factorP1P2*p1p2.X,
-		Y: y1 + factorP1P2*p1p2.Y,
-	}
-
-	orthP1P2 := Point{
-		X: tP1P2.X - p.X,
-		Y: tP1P2.Y - p.Y,
-	}
-	orthP1P2Length := math.Hypot(orthP1P2.X, orthP1P2.Y)
-
-	ea := math.Acos(orthP1P2.X / orthP1P2Length)
-	if orthP1P2.Y <= 0 {
-		ea = 2*math.Pi - ea
-	}
-
-	dc.LineTo(tP1P0.X, tP1P0.Y)
-	dc.DrawEllipticalArc(p.X, p.Y, radius, radius, sa, ea)
+	gg.ArcTo(dc, x1, y1, x2, y2, radius)
 }
 
 // SetFillStyle sets current fill style
@@ -293,6 +282,9 @@ func (dc *Context) SetLineCap(lineCap gg.LineCap) { dc.lineCap = lineCap }
 // SetLineJoin sets the current line join
 func (dc *Context) SetLineJoin(lineJoin gg.LineJoin) { dc.lineJoin = lineJoin }
 
+// SetMiterLimit sets the current miter limit
+func (dc *Context) SetMiterLimit(limit float64) { dc.miterLimit = limit }
+
 // SetLineDash sets the current dash
 func (dc *Context) SetLineDash(dashes ...float64) {
 	dc.dashes = dashes
@@ -334,7 +326,6 @@ func (dc *Context) Pop() {
 	s := dc.stack
 	x, s := s[len(s)-1], s[:len(s)-1]
 	*dc = *x
-	dc.mask = before.mask
 	dc.strokePath = before.strokePath
 	dc.fillPath = before.fillPath
 	dc.start = before.start
@@ -363,7 +354,7 @@ func (dc *Context) Stroke() {
 		}
 	}
 	if painter == nil {
-		painter = newPatternPainter(dc.im, dc.mask, dc.strokePattern)
+		painter = newPatternPainter(dc.im, dc.mask, gg.DevicePattern(dc.strokePattern, dc.matrix))
 	}
 	dc.stroke(painter)
 
@@ -384,7 +375,7 @@ func (dc *Context) FillPreserve() {
 		}
 	}
 	if painter == nil {
-		painter = newPatternPainter(dc.im, dc.mask, dc.fillPattern)
+		painter = newPatternPainter(dc.im, dc.mask, gg.DevicePattern(dc.fillPattern, dc.matrix))
 	}
 	dc.fill(painter)
 }
@@ -502,29 +493,7 @@ func (dc *Context) DrawLine(x1, y1, x2, y2 float64) {
 
 // DrawEllipticalArc draws an elliptical arc
 func (dc *Context) DrawEllipticalArc(x, y, rx, ry, angle1, angle2 float64) {
-	const n = 16
-	for i := 0; i < n; i++ {
-		p1 := float64(i+0) / n
-		p2 := float64(i+1) / n
-		a1 := angle1 + (angle2-angle1)*p1
-		a2 := angle1 + (angle2-angle1)*p2
-		x0 := x + rx*math.Cos(a1)
-		y0 := y + ry*math.Sin(a1)
-		x1 := x + rx*math.Cos((a1+a2)/2)
-		y1 := y + ry*math.Sin((a1+a2)/2)
-		x2 := x + rx*math.Cos(a2)
-		y2 := y + ry*math.Sin(a2)
-		cx := 2*x1 - x0/2 - x2/2
-		cy := 2*y1 - y0/2 - y2/2
-		if i == 0 {
-			if dc.hasCurrent {
-				dc.LineTo(x0, y0)
-			} else {
-				dc.MoveTo(x0, y0)
-			}
-		}
-		dc.QuadraticTo(cx, cy, x2, y2)
-	}
+	gg.DrawEllipticalArc(dc, x, y, rx, ry, angle1, angle2)
 }
 
 // DrawImageAnchored draws the specified image at the specified anchor point.
@@ -581,6 +550,19 @@ func (dc *Context) MeasureString(s string) (w, h float64) {
 	return float64(a >> 6), dc.fontSize
 }
 
+// FontMetrics returns how far the current font face rises above
+// and descends below the baseline.
+func (dc *Context) FontMetrics() (ascent, descent float64) {
+	ff, err := dc.currentFontFace()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning img.Context FontMetrics error: %s", err.Error())
+		return 0, 0
+	}
+
+	m := ff.Metrics()
+	return float64(m.Ascent) / 64, float64(m.Descent) / 64
+}
+
 // Path Drawing
 
 func (dc *Context) capper() raster.Capper {
@@ -601,6 +583,8 @@ func (dc *Context) joiner() raster.Joiner {
 		return raster.BevelJoiner
 	case gg.LineJoinRound:
 		return raster.RoundJoiner
+	case gg.LineJoinMiter:
+		return miterJoiner(dc.miterLimit)
 	}
 	return nil
 }
@@ -0,0 +1,297 @@
+package img
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/lucasepe/g2d/gg"
+)
+
+var (
+	red   = color.RGBA{255, 0, 0, 255}
+	green = color.RGBA{0, 255, 0, 255}
+	blue  = color.RGBA{0, 0, 255, 255}
+	clear = color.RGBA{}
+)
+
+func newContext(w, h int) *Context {
+	return NewContextForRGBA(image.NewRGBA(image.Rect(0, 0, w, h)))
+}
+
+func rect(dc *Context, x, y, w, h float64) {
+	dc.MoveTo(x, y)
+	dc.LineTo(x+w, y)
+	dc.LineTo(x+w, y+h)
+	dc.LineTo(x, y+h)
+	dc.ClosePath()
+}
+
+// checkPixel checks the color of the pixel, allowing for rounding errors
+func checkPixel(t *testing.T, dc *Context, x, y int, expected color.RGBA) {
+	t.Helper()
+
+	got := dc.im.RGBAAt(x, y)
+	near := func(a, b uint8) bool { d := int(a) - int(b); return d > -3 && d < 3 }
+	if !near(got.R, expected.R) || !near(got.G, expected.G) || !near(got.B, expected.B) || !near(got.A, expected.A) {
+		t.Errorf("wrong color at (%d, %d). expected=%v, got=%v", x, y, expected, got)
+	}
+}
+
+func TestGradients(t *testing.T) {
+	linear := gg.NewLinearGradient(10, 0, 90, 0)
+	linear.AddColorStop(0, red)
+	linear.AddColorStop(1, blue)
+
+	// the gradient is in user space, moved with the shape
+	dc := newContext(200, 20)
+	dc.Translate(100, 0)
+	dc.SetFillStyle(linear)
+	rect(dc, 0, 0, 100, 20)
+	dc.Fill()
+
+	checkPixel(t, dc, 50, 10, clear)
+	checkPixel(t, dc, 101, 10, red)
+	checkPixel(t, dc, 110, 10, red)
+	checkPixel(t, dc, 189, 10, blue)
+	checkPixel(t, dc, 198, 10, blue)
+	checkPixel(t, dc, 149, 10, color.RGBA{130, 0, 125, 255})
+
+	// and strokes follow the same rules
+	dc = newContext(100, 20)
+	dc.SetStrokeStyle(linear)
+	dc.SetStrokeWeight(10)
+	dc.MoveTo(0, 10)
+	dc.LineTo(100, 10)
+	dc.Stroke()
+
+	checkPixel(t, dc, 2, 10, red)
+	checkPixel(t, dc, 97, 10, blue)
+
+	// inside the start circle the color is the first stop
+	radial := gg.NewRadialGradient(50.5, 50.5, 10, 50.5, 50.5, 40)
+	radial.AddColorStop(0, red)
+	radial.AddColorStop(0.5, green)
+	radial.AddColorStop(1, blue)
+
+	dc = newContext(100, 100)
+	dc.SetFillStyle(radial)
+	rect(dc, 0, 0, 100, 100)
+	dc.Fill()
+
+	checkPixel(t, dc, 50, 50, red)
+	checkPixel(t, dc, 55, 50, red)
+	checkPixel(t, dc, 75, 50, green)
+	checkPixel(t, dc, 50, 90, blue)
+	checkPixel(t, dc, 1, 1, blue)
+
+	// the conic gradient starts at the angle and goes clockwise
+	conic := gg.NewConicGradient(50, 50, 0)
+	conic.AddColorStop(0, red)
+	conic.AddColorStop(1, blue)
+
+	dc = newContext(100, 100)
+	dc.SetFillStyle(conic)
+	rect(dc, 0, 0, 100, 100)
+	dc.Fill()
+
+	checkPixel(t, dc, 90, 51, red)
+	checkPixel(t, dc, 90, 48, blue)
+	checkPixel(t, dc, 10, 50, color.RGBA{127, 0, 127, 255})
+}
+
+func TestSurfacePattern(t *testing.T) {
+	// a red and green tile, with a blue bottom row
+	tile := image.NewRGBA(image.Rect(0, 0, 2, 2))
+	tile.SetRGBA(0, 0, red)
+	tile.SetRGBA(1, 0, green)
+	tile.SetRGBA(0, 1, blue)
+	tile.SetRGBA(1, 1, blue)
+
+	tests := []struct {
+		op       gg.RepeatOp
+		expected [][]color.RGBA
+	}{
+		{gg.RepeatBoth, [][]color.RGBA{
+			{green, green, red, red, green, green},
+			{blue, blue, blue, blue, blue, blue},
+			{green, green, red, red, green, green},
+		}},
+		{gg.RepeatX, [][]color.RGBA{
+			{clear, clear, clear, clear, clear, clear},
+			{clear, clear, clear, clear, clear, clear},
+			{green, green, red, red, green, green},
+		}},
+		{gg.RepeatY, [][]color.RGBA{
+			{clear, clear, red, red, green, green},
+			{clear, clear, blue, blue, blue, blue},
+			{clear, clear, red, red, green, green},
+		}},
+		{gg.RepeatNone, [][]color.RGBA{
+			{clear, clear, clear, clear, clear, clear},
+			{clear, clear, clear, clear, clear, clear},
+			{clear, clear, red, red, green, green},
+		}},
+	}
+
+	for _, tt := range tests {
+		// the tile starts at (1, 2) of user space, scaled by 2,
+		// so the pixels on the left and top wrap around
+		dc := newContext(6, 6)
+		dc.Scale(2, 2)
+		dc.SetFillStyle(gg.NewTransformedSurfacePattern(tile, tt.op, gg.Translate(1, 2)))
+		rect(dc, 0, 0, 3, 3)
+		dc.Fill()
+
+		for y, row := range tt.expected {
+			for x, el := range row {
+				checkPixel(t, dc, x, 2*y+1, el)
+			}
+		}
+	}
+}
+
+func TestClip(t *testing.T) {
+	fill := func(dc *Context, c color.RGBA) {
+		dc.SetFillStyle(gg.NewSolidPattern(c))
+		rect(dc, 0, 0, 30, 10)
+		dc.Fill()
+	}
+
+	dc := newContext(30, 10)
+	dc.Push()
+	rect(dc, 0, 0, 20, 10)
+	dc.Clip()
+
+	// the clips intersect
+	dc.Push()
+	rect(dc, 10, 0, 20, 10)
+	dc.Clip()
+	fill(dc, red)
+	checkPixel(t, dc, 5, 5, clear)
+	checkPixel(t, dc, 15, 5, red)
+	checkPixel(t, dc, 25, 5, clear)
+
+	// pop restores the previous clip
+	dc.Pop()
+	fill(dc, green)
+	checkPixel(t, dc, 5, 5, green)
+	checkPixel(t, dc, 15, 5, green)
+	checkPixel(t, dc, 25, 5, clear)
+
+	// and then no clip at all
+	dc.Pop()
+	fill(dc, blue)
+	checkPixel(t, dc, 5, 5, blue)
+	checkPixel(t, dc, 25, 5, blue)
+
+	// the clip uses the fill rule
+	dc = newContext(30, 10)
+	dc.SetFillRule(gg.FillRuleEvenOdd)
+	rect(dc, 0, 0, 30, 10)
+	rect(dc, 10, 0, 10, 10)
+	dc.Clip()
+	fill(dc, red)
+	checkPixel(t, dc, 5, 5, red)
+	checkPixel(t, dc, 15, 5, clear)
+	checkPixel(t, dc, 25, 5, red)
+
+	dc.ResetClip()
+	fill(dc, blue)
+	checkPixel(t, dc, 15, 5, blue)
+}
+
+func TestMiterJoin(t *testing.T) {
+	// the miter of a right angle is sqrt(2) times the stroke width
+	tests := []struct {
+		join     gg.LineJoin
+		limit    float64
+		expected color.RGBA
+	}{
+		{gg.LineJoinMiter, 10, red},
+		{gg.LineJoinMiter, 1.5, red},
+		{gg.LineJoinMiter, 1.4, clear},
+		{gg.LineJoinBevel, 10, clear},
+		{gg.LineJoinRound, 10, clear},
+	}
+
+	for _, tt := range tests {
+		dc := newContext(100, 100)
+		dc.SetStrokeStyle(gg.NewSolidPattern(red))
+		dc.SetStrokeWeight(20)
+		dc.SetLineJoin(tt.join)
+		dc.SetMiterLimit(tt.limit)
+		dc.MoveTo(10, 50)
+		dc.LineTo(50, 50)
+		dc.LineTo(50, 90)
+		dc.Stroke()
+
+		// the outer corner of the join
+		checkPixel(t, dc, 58, 41, tt.expected)
+
+		// the segments and the inner side of the join
+		checkPixel(t, dc, 30, 45, red)
+		checkPixel(t, dc, 55, 70, red)
+		checkPixel(t, dc, 45, 55, red)
+
+		// the bevel cuts the corner, the round join doesn't reach it
+		checkPixel(t, dc, 53, 45, red)
+	}
+}
+
+func TestCubicTo(t *testing.T) {
+	// the middle of the curve is at (50, 20), moved down by 10
+	curve := func(dc *Context) {
+		dc.Translate(0, 10)
+		dc.MoveTo(10, 50)
+		dc.CubicTo(10, 10, 90, 10, 90, 50)
+	}
+
+	dc := newContext(100, 100)
+	dc.SetStrokeStyle(gg.NewSolidPattern(red))
+	dc.SetStrokeWeight(4)
+	curve(dc)
+	dc.Stroke()
+
+	checkPixel(t, dc, 50, 30, red)
+	checkPixel(t, dc, 10, 59, red)
+	checkPixel(t, dc, 90, 59, red)
+	checkPixel(t, dc, 50, 40, clear)
+	checkPixel(t, dc, 50, 20, clear)
+
+	// the fill is bounded by the curve
+	dc = newContext(100, 100)
+	dc.SetFillStyle(gg.NewSolidPattern(red))
+	curve(dc)
+	dc.ClosePath()
+	dc.Fill()
+
+	checkPixel(t, dc, 50, 26, clear)
+	checkPixel(t, dc, 50, 35, red)
+	checkPixel(t, dc, 50, 58, red)
+	checkPixel(t, dc, 50, 62, clear)
+}
+
+func TestCurveThrough(t *testing.T) {
+	points := [][2]float64{{10, 50}, {30, 20}, {70, 80}, {90, 50}}
+
+	for _, tension := range []float64{0, 0.5, 1} {
+		dc := newContext(100, 100)
+		dc.SetStrokeStyle(gg.NewSolidPattern(red))
+		dc.SetStrokeWeight(4)
+		gg.CurveThrough(dc, points, tension)
+		dc.Stroke()
+
+		for _, el := range points {
+			checkPixel(t, dc, int(el[0]), int(el[1]), red)
+		}
+
+		// the middle of the first segment, the curve bends away from it
+		switch tension {
+		case 0:
+			checkPixel(t, dc, 20, 35, clear)
+		case 1:
+			checkPixel(t, dc, 20, 35, red)
+		}
+	}
+}
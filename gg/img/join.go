@@ -0,0 +1,35 @@
+package img
+
+import (
+	"github.com/golang/freetype/raster"
+	"golang.org/x/image/math/fixed"
+)
+
+// miterJoiner returns a joiner extending the outer edges of the segments
+// until they meet. When the ratio of the miter length to the stroke width
+// exceeds the limit, the join falls back to a bevel join.
+func miterJoiner(limit float64) raster.Joiner {
+	return raster.JoinerFunc(func(lhs, rhs raster.Adder, halfWidth fixed.Int26_6, pivot, n0, n1 fixed.Point26_6) {
+		x0, y0 := float64(n0.X), float64(n0.Y)
+		x1, y1 := float64(n1.X), float64(n1.Y)
+
+		// the tip v lies on both the outer edges: v·n0 = v·n1 = |n0|²
+		h2 := x0*x0 + y0*y0
+		if d := h2 + x0*x1 + y0*y1; d > 0 {
+			k := h2 / d
+			vx, vy := k*(x0+x1), k*(y0+y1)
+			if vx*vx+vy*vy <= limit*limit*h2 {
+				v := fixed.Point26_6{X: fixed.Int26_6(vx), Y: fixed.Int26_6(vy)}
+				// the outer side is on the left if the path turns right
+				if x1*-y0+y1*x0 >= 0 {
+					lhs.Add1(pivot.Add(v))
+				} else {
+					rhs.Add1(pivot.Sub(v))
+				}
+			}
+		}
+
+		lhs.Add1(pivot.Add(n1))
+		rhs.Add1(pivot.Sub(n1))
+	})
+}
@@ -0,0 +1,327 @@
+package gg
+
+import "math"
+
+// PathBuilder is implemented by everything able to collect path commands:
+// the graphic contexts and the standalone paths.
+type PathBuilder interface {
+	// MoveTo creates a new subpath that start at the specified point
+	MoveTo(x, y float64)
+	// LineTo adds a line to the current subpath
+	LineTo(x, y float64)
+	// QuadraticTo adds a quadratic Bézier curve to the current subpath
+	QuadraticTo(x1, y1, x2, y2 float64)
+	// CubicTo adds a cubic Bézier curve to the current subpath
+	CubicTo(x1, y1, x2, y2, x3, y3 float64)
+	// ArcTo adds a circular arc to the current sub-path, using
+	// the given control points and radius.
+	ArcTo(x1, y1, x2, y2, radius float64)
+	// DrawEllipticalArc adds an elliptical arc to the current subpath
+	DrawEllipticalArc(x, y, rx, ry, angle1, angle2 float64)
+	// ClosePath closes the current subpath
+	ClosePath()
+	// CurrentPoint returns the current point of the current sub path
+	CurrentPoint() (float64, float64, bool)
+}
+
+// Point is a point of a path
+type Point struct {
+	X, Y float64
+}
+
+// Distance returns the distance from another point
+func (a Point) Distance(b Point) float64 {
+	return math.Hypot(a.X-b.X, a.Y-b.Y)
+}
+
+// Interpolate returns a new interpolated point
+func (a Point) Interpolate(b Point, t float64) Point {
+	x := a.X + (b.X-a.X)*t
+	y := a.Y + (b.Y-a.Y)*t
+	return Point{x, y}
+}
+
+// PathOp is the kind of a path segment
+type PathOp int
+
+const (
+	PathMoveTo PathOp = iota
+	PathLineTo
+	PathQuadraticTo
+	PathCubicTo
+	PathClose
+)
+
+// PathSegment is a segment of a path: the control points (if any)
+// followed by the end point
+type PathSegment struct {
+	Op     PathOp
+	Points []Point
+}
+
+// Path is a standalone path, built in user space and appended to the
+// current path of a graphic context (transformed by its current matrix)
+// every time it is drawn
+type Path struct {
+	Segments []PathSegment
+
+	start      Point
+	current    Point
+	hasCurrent bool
+}
+
+// NewPath returns an empty path
+func NewPath() *Path {
+	return &Path{}
+}
+
+func (p *Path) add(op PathOp, points ...Point) {
+	p.Segments = append(p.Segments, PathSegment{Op: op, Points: points})
+	if len(points) > 0 {
+		p.current = points[len(points)-1]
+	}
+}
+
+// MoveTo starts a new subpath at the specified point
+func (p *Path) MoveTo(x, y float64) {
+	p.start = Point{x, y}
+	p.add(PathMoveTo, p.start)
+	p.hasCurrent = true
+}
+
+// LineTo adds a line segment to the current subpath. If there is no
+// current point, it is equivalent to MoveTo(x, y)
+func (p *Path) LineTo(x, y float64) {
+	if !p.hasCurrent {
+		p.MoveTo(x, y)
+		return
+	}
+	p.add(PathLineTo, Point{x, y})
+}
+
+// QuadraticTo adds a quadratic Bézier curve to the current subpath.
+// If there is no current point, it first performs MoveTo(x1, y1)
+func (p *Path) QuadraticTo(x1, y1, x2, y2 float64) {
+	if !p.hasCurrent {
+		p.MoveTo(x1, y1)
+	}
+	p.add(PathQuadraticTo, Point{x1, y1}, Point{x2, y2})
+}
+
+// CubicTo adds a cubic Bézier curve to the current subpath.
+// If there is no current point, it first performs MoveTo(x1, y1)
+func (p *Path) CubicTo(x1, y1, x2, y2, x3, y3 float64) {
+	if !p.hasCurrent {
+		p.MoveTo(x1, y1)
+	}
+	p.add(PathCubicTo, Point{x1, y1}, Point{x2, y2}, Point{x3, y3})
+}
+
+// ArcTo adds a circular arc to the current subpath, using
+// the given control points and radius.
+func (p *Path) ArcTo(x1, y1, x2, y2, radius float64) {
+	ArcTo(p, x1, y1, x2, y2, radius)
+}
+
+// DrawEllipticalArc adds an elliptical arc to the current subpath
+func (p *Path) DrawEllipticalArc(x, y, rx, ry, angle1, angle2 float64) {
+	DrawEllipticalArc(p, x, y, rx, ry, angle1, angle2)
+}
+
+// ClosePath adds a line segment from the current point to the beginning
+// of the current subpath. If there is no current point, this is a no-op.
+func (p *Path) ClosePath() {
+	if p.hasCurrent {
+		p.add(PathClose)
+		p.current = p.start
+	}
+}
+
+// CurrentPoint returns the current point and if there is a current point
+func (p *Path) CurrentPoint() (float64, float64, bool) {
+	return p.current.X, p.current.Y, p.hasCurrent
+}
+
+// Transform returns a copy of the path with all the points
+// multiplied by the specified matrix
+func (p *Path) Transform(m Matrix) *Path {
+	res := &Path{hasCurrent: p.hasCurrent}
+	for _, seg := range p.Segments {
+		points := make([]Point, len(seg.Points))
+		for i, pt := range seg.Points {
+			points[i].X, points[i].Y = m.TransformPoint(pt.X, pt.Y)
+		}
+		res.Segments = append(res.Segments, PathSegment{Op: seg.Op, Points: points})
+	}
+	res.start.X, res.start.Y = m.TransformPoint(p.start.X, p.start.Y)
+	res.current.X, res.current.Y = m.TransformPoint(p.current.X, p.current.Y)
+	return res
+}
+
+// Flatten approximates the curves of the path with line segments, returning
+// a polyline for each subpath; closed subpaths end at their first point
+func (p *Path) Flatten() [][]Point {
+	var res [][]Point
+	var line []Point
+	var start Point
+
+	flush := func() {
+		if len(line) > 1 {
+			res = append(res, line)
+		}
+		line = nil
+	}
+
+	for _, seg := range p.Segments {
+		switch seg.Op {
+		case PathMoveTo:
+			flush()
+			start = seg.Points[0]
+			line = []Point{start}
+		case PathLineTo:
+			line = append(line, seg.Points[0])
+		case PathQuadraticTo:
+			p0, p1, p2 := line[len(line)-1], seg.Points[0], seg.Points[1]
+			n := curveSteps(p0, p1, p2)
+			for i := 1; i <= n; i++ {
+				t := float64(i) / float64(n)
+				u := 1 - t
+				line = append(line, Point{
+					u*u*p0.X + 2*u*t*p1.X + t*t*p2.X,
+					u*u*p0.Y + 2*u*t*p1.Y + t*t*p2.Y,
+				})
+			}
+		case PathCubicTo:
+			p0, p1, p2, p3 := line[len(line)-1], seg.Points[0], seg.Points[1], seg.Points[2]
+			n := curveSteps(p0, p1, p2, p3)
+			for i := 1; i <= n; i++ {
+				t := float64(i) / float64(n)
+				u := 1 - t
+				a, b, c, d := u*u*u, 3*u*u*t, 3*u*t*t, t*t*t
+				line = append(line, Point{
+					a*p0.X + b*p1.X + c*p2.X + d*p3.X,
+					a*p0.Y + b*p1.Y + c*p2.Y + d*p3.Y,
+				})
+			}
+		case PathClose:
+			line = append(line, start)
+			flush()
+			line = []Point{start}
+		}
+	}
+	flush()
+
+	return res
+}
+
+// curveSteps returns the number of line segments approximating
+// the curve with the specified control polygon
+func curveSteps(points ...Point) int {
+	l := 0.0
+	for i := 1; i < len(points); i++ {
+		l += points[i].Distance(points[i-1])
+	}
+	return int(math.Max(16, math.Min(512, math.Ceil(l/2))))
+}
+
+// Bounds returns the bounding box of the path
+func (p *Path) Bounds() (x0, y0, x1, y1 float64) {
+	first := true
+	for _, line := range p.Flatten() {
+		for _, pt := range line {
+			if first {
+				x0, y0, x1, y1 = pt.X, pt.Y, pt.X, pt.Y
+				first = false
+				continue
+			}
+			x0, y0 = math.Min(x0, pt.X), math.Min(y0, pt.Y)
+			x1, y1 = math.Max(x1, pt.X), math.Max(y1, pt.Y)
+		}
+	}
+	return
+}
+
+// Contains reports whether the specified point is inside the path, open
+// subpaths are implicitly closed as when filling
+func (p *Path) Contains(x, y float64, rule FillRule) bool {
+	winding := 0
+	for _, line := range p.Flatten() {
+		n := len(line)
+		for i := 0; i < n; i++ {
+			a, b := line[i], line[(i+1)%n]
+			if (a.Y <= y) == (b.Y <= y) {
+				continue
+			}
+			// the x where the edge crosses the horizontal line through y
+			cx := a.X + (y-a.Y)*(b.X-a.X)/(b.Y-a.Y)
+			if cx <= x {
+				continue
+			}
+			if b.Y > a.Y {
+				winding++
+			} else {
+				winding--
+			}
+		}
+	}
+
+	if rule == FillRuleEvenOdd {
+		return winding%2 != 0
+	}
+	return winding != 0
+}
+
+// Length returns the length of the path
+func (p *Path) Length() float64 {
+	res := 0.0
+	for _, line := range p.Flatten() {
+		for i := 1; i < len(line); i++ {
+			res += line[i].Distance(line[i-1])
+		}
+	}
+	return res
+}
+
+// PointAt returns the point at the specified distance along the path, and
+// the direction of the path there as an angle in radians. The distance is
+// clamped to the length of the path; ok is false if the path is empty.
+func (p *Path) PointAt(d float64) (pt Point, angle float64, ok bool) {
+	var a, b Point
+	for _, line := range p.Flatten() {
+		for i := 1; i < len(line); i++ {
+			a, b, ok = line[i-1], line[i], true
+			l := a.Distance(b)
+			if l == 0 {
+				continue
+			}
+			if d <= l {
+				return a.Interpolate(b, math.Max(0, d)/l), math.Atan2(b.Y-a.Y, b.X-a.X), true
+			}
+			d -= l
+		}
+	}
+
+	// past the end of the path
+	return b, math.Atan2(b.Y-a.Y, b.X-a.X), ok
+}
+
+// AppendPath adds all the subpaths of the path to the current path
+// of the specified builder.
+// It is shared by all the backends.
+func AppendPath(dc PathBuilder, p *Path) {
+	for _, seg := range p.Segments {
+		pts := seg.Points
+		switch seg.Op {
+		case PathMoveTo:
+			dc.MoveTo(pts[0].X, pts[0].Y)
+		case PathLineTo:
+			dc.LineTo(pts[0].X, pts[0].Y)
+		case PathQuadraticTo:
+			dc.QuadraticTo(pts[0].X, pts[0].Y, pts[1].X, pts[1].Y)
+		case PathCubicTo:
+			dc.CubicTo(pts[0].X, pts[0].Y, pts[1].X, pts[1].Y, pts[2].X, pts[2].Y)
+		case PathClose:
+			dc.ClosePath()
+		}
+	}
+}
@@ -3,6 +3,7 @@ package gg
 import (
 	"image"
 	"image/color"
+	"math"
 )
 
 type RepeatOp int
@@ -35,29 +36,75 @@ func NewSolidPattern(color color.Color) Pattern {
 type SurfacePattern struct {
 	im image.Image
 	op RepeatOp
+
+	// Matrix transforms the pattern space, where the image
+	// has the top left corner at the origin, to device space
+	Matrix  Matrix
+	inverse Matrix
 }
 
 func (p *SurfacePattern) ColorAt(x, y int) color.Color {
+	fx, fy := p.inverse.TransformPoint(float64(x)+0.5, float64(y)+0.5)
+	x, y = int(math.Floor(fx)), int(math.Floor(fy))
+
 	b := p.im.Bounds()
+	if b.Empty() {
+		return color.Transparent
+	}
+
+	inX := x >= 0 && x < b.Dx()
+	inY := y >= 0 && y < b.Dy()
 	switch p.op {
 	case RepeatX:
-		if y >= b.Dy() {
+		if !inY {
 			return color.Transparent
 		}
 	case RepeatY:
-		if x >= b.Dx() {
+		if !inX {
 			return color.Transparent
 		}
 	case RepeatNone:
-		if x >= b.Dx() || y >= b.Dy() {
+		if !inX || !inY {
 			return color.Transparent
 		}
 	}
-	x = x%b.Dx() + b.Min.X
-	y = y%b.Dy() + b.Min.Y
+	x = mod(x, b.Dx()) + b.Min.X
+	y = mod(y, b.Dy()) + b.Min.Y
 	return p.im.At(x, y)
 }
 
+// Image returns the repeated image
+func (p *SurfacePattern) Image() image.Image {
+	return p.im
+}
+
+// Repeat returns how the image is repeated
+func (p *SurfacePattern) Repeat() RepeatOp {
+	return p.op
+}
+
+// Transform satisfies the Transformer interface.
+func (p *SurfacePattern) Transform(m Matrix) Pattern {
+	res := *p
+	res.Matrix = p.Matrix.Multiply(m)
+	res.inverse = res.Matrix.Invert()
+	return &res
+}
+
 func NewSurfacePattern(im image.Image, op RepeatOp) Pattern {
-	return &SurfacePattern{im: im, op: op}
+	return NewTransformedSurfacePattern(im, op, Identity())
+}
+
+// NewTransformedSurfacePattern returns a pattern repeating the image,
+// placed in user space by the specified transformation matrix.
+func NewTransformedSurfacePattern(im image.Image, op RepeatOp, m Matrix) Pattern {
+	return &SurfacePattern{im: im, op: op, Matrix: m, inverse: m.Invert()}
+}
+
+// mod returns the non negative remainder of x / n
+func mod(x, n int) int {
+	if x %= n; x < 0 {
+		x += n
+	}
+	return x
 }
@@ -0,0 +1,758 @@
+package pdf
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/golang/freetype/truetype"
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+
+	"github.com/lucasepe/g2d/gg"
+)
+
+// Context implements the graphic context for drawing PDF documents
+type Context struct {
+	doc  *document
+	page *page
+
+	// path data in device coordinates
+	path       []byte
+	start      point
+	current    point
+	hasCurrent bool
+
+	fillColor     color.Color
+	fillPattern   gg.Pattern
+	strokeColor   color.Color
+	strokePattern gg.Pattern
+
+	dashes     []float64
+	dashOffset float64
+	lineWidth  float64
+	lineCap    gg.LineCap
+	lineJoin   gg.LineJoin
+	miterLimit float64
+	fillRule   gg.FillRule
+	font       *truetype.Font
+	fontSize   float64
+	matrix     gg.Matrix
+	clips      []clipPath
+	stack      []*Context
+}
+
+// clipPath is a path, in device coordinates, intersected
+// with the clipping region of each drawing operation
+type clipPath struct {
+	path    []byte
+	evenOdd bool
+}
+
+type point struct {
+	X, Y float64
+}
+
+// NewContext prepares a context for drawing a PDF document
+// whose pages have the specified width and height.
+func NewContext(width, height int) *Context {
+	doc := &document{}
+	return &Context{
+		doc:           doc,
+		page:          doc.addPage(width, height),
+		fillColor:     color.Transparent,
+		fillPattern:   gg.NewSolidPattern(color.White),
+		strokeColor:   color.Black,
+		strokePattern: gg.NewSolidPattern(color.Black),
+		lineWidth:     1,
+		miterLimit:    10,
+		fillRule:      gg.FillRuleWinding,
+		fontSize:      14,
+		matrix:        gg.Identity(),
+	}
+}
+
+// ShowPage ends the current page and starts a new blank page
+// with the same size.
+func (dc *Context) ShowPage() {
+	dc.page = dc.doc.addPage(dc.page.width, dc.page.height)
+}
+
+// SetPageSize changes the size of the current page and of the
+// pages started afterwards.
+func (dc *Context) SetPageSize(width, height int) {
+	dc.page.width = width
+	dc.page.height = height
+}
+
+// WriteTo writes the PDF document to w. A trailing blank page is omitted.
+func (dc *Context) WriteTo(w io.Writer) (int64, error) {
+	return dc.doc.writeTo(w)
+}
+
+// Formats returns the supported document formats.
+func (dc *Context) Formats() []string {
+	return []string{"pdf"}
+}
+
+// Encode writes the PDF document to w.
+func (dc *Context) Encode(w io.Writer, format string) error {
+	if format != "pdf" {
+		return fmt.Errorf("unsupported document format '%s'", format)
+	}
+	_, err := dc.WriteTo(w)
+	return err
+}
+
+// Width returns the width of the current page.
+func (dc *Context) Width() float64 { return float64(dc.page.width) }
+
+// Height returns the height of the current page.
+func (dc *Context) Height() float64 { return float64(dc.page.height) }
+
+// BeginPath starts a new subpath within the current path. There is no current
+// point after this operation.
+func (dc *Context) BeginPath() {
+	dc.hasCurrent = false
+}
+
+// MoveTo starts a new subpath within the current path starting at the
+// specified point.
+func (dc *Context) MoveTo(x, y float64) {
+	x, y = dc.TransformPoint(x, y)
+	p := point{x, y}
+	dc.path = appendPoints(dc.path, p)
+	dc.path = append(dc.path, " m\n"...)
+	dc.start = p
+	dc.current = p
+	dc.hasCurrent = true
+}
+
+// LineTo adds a line segment to the current path starting at the current
+// point. If there is no current point, it is equivalent to MoveTo(x, y)
+func (dc *Context) LineTo(x, y float64) {
+	if !dc.hasCurrent {
+		dc.MoveTo(x, y)
+		return
+	}
+	x, y = dc.TransformPoint(x, y)
+	p := point{x, y}
+	dc.path = appendPoints(dc.path, p)
+	dc.path = append(dc.path, " l\n"...)
+	dc.current = p
+}
+
+// QuadraticTo adds a quadratic bezier curve to the current path starting at
+// the current point. If there is no current point, it first performs
+// MoveTo(x1, y1)
+func (dc *Context) QuadraticTo(x1, y1, x2, y2 float64) {
+	if !dc.hasCurrent {
+		dc.MoveTo(x1, y1)
+	}
+	x1, y1 = dc.TransformPoint(x1, y1)
+	x2, y2 = dc.TransformPoint(x2, y2)
+
+	// PDF has only cubic curves: elevate the degree of the quadratic one
+	p0 := dc.current
+	c1 := point{p0.X + 2*(x1-p0.X)/3, p0.Y + 2*(y1-p0.Y)/3}
+	c2 := point{x2 + 2*(x1-x2)/3, y2 + 2*(y1-y2)/3}
+	p2 := point{x2, y2}
+	dc.path = appendPoints(dc.path, c1, c2, p2)
+	dc.path = append(dc.path, " c\n"...)
+	dc.current = p2
+}
+
+// CubicTo adds a cubic bezier curve to the current path starting at the
+// current point. If there is no current point, it first performs
+// MoveTo(x1, y1)
+func (dc *Context) CubicTo(x1, y1, x2, y2, x3, y3 float64) {
+	if !dc.hasCurrent {
+		dc.MoveTo(x1, y1)
+	}
+	x1, y1 = dc.TransformPoint(x1, y1)
+	x2, y2 = dc.TransformPoint(x2, y2)
+	x3, y3 = dc.TransformPoint(x3, y3)
+	p3 := point{x3, y3}
+	dc.path = appendPoints(dc.path, point{x1, y1}, point{x2, y2}, p3)
+	dc.path = append(dc.path, " c\n"...)
+	dc.current = p3
+}
+
+// AppendPath adds the subpaths of the specified path, transformed by the
+// current matrix, to the current path
+func (dc *Context) AppendPath(p *gg.Path) {
+	gg.AppendPath(dc, p)
+}
+
+// CurrentPath returns a copy of the current path in user space
+func (dc *Context) CurrentPath() *gg.Path {
+	res := gg.NewPath()
+
+	var v []float64
+	for _, el := range strings.Fields(string(dc.path)) {
+		switch el {
+		case "m":
+			res.MoveTo(v[0], v[1])
+		case "l":
+			res.LineTo(v[0], v[1])
+		case "c":
+			res.CubicTo(v[0], v[1], v[2], v[3], v[4], v[5])
+		case "h":
+			res.ClosePath()
+		default:
+			val, _ := strconv.ParseFloat(el, 64)
+			v = append(v, val)
+			continue
+		}
+		v = v[:0]
+	}
+
+	return res.Transform(dc.matrix.Invert())
+}
+
+// ArcTo adds a circular arc to the current sub-path, using
+// the given control points and radius.
+func (dc *Context) ArcTo(x1, y1, x2, y2, radius float64) {
+	gg.ArcTo(dc, x1, y1, x2, y2, radius)
+}
+
+// ClosePath adds a line segment from the current point to the beginning
+// of the current subpath. If there is no current point, this is a no-op.
+func (dc *Context) ClosePath() {
+	if dc.hasCurrent {
+		dc.path = append(dc.path, "h\n"...)
+		dc.current = dc.start
+	}
+}
+
+// CurrentPoint returns the current point and if there is a current point.
+// The point will have been transformed by the context's transformation matrix.
+func (dc *Context) CurrentPoint() (float64, float64, bool) {
+	if dc.hasCurrent {
+		return dc.current.X, dc.current.Y, true
+	}
+	return 0, 0, false
+}
+
+// ClearPath clears the current path. There is no current point after this
+// operation.
+func (dc *Context) ClearPath() {
+	dc.path = nil
+	dc.hasCurrent = false
+}
+
+// SetStrokeColor sets the current stroke color. r, g, b, a
+// values should be between 0 and 255, inclusive.
+func (dc *Context) SetStrokeColor(r, g, b, a int) {
+	dc.strokeColor = color.NRGBA{uint8(r), uint8(g), uint8(b), uint8(a)}
+	dc.strokePattern = gg.NewSolidPattern(dc.strokeColor)
+}
+
+// SetFillColor sets the current fill color. r, g, b, a values should be between 0 and
+// 255, inclusive.
+func (dc *Context) SetFillColor(r, g, b, a int) {
+	dc.fillColor = color.NRGBA{uint8(r), uint8(g), uint8(b), uint8(a)}
+	dc.fillPattern = gg.NewSolidPattern(dc.fillColor)
+}
+
+// SetFillRule sets the current fill rule
+func (dc *Context) SetFillRule(fillRule gg.FillRule) {
+	dc.fillRule = fillRule
+}
+
+// FillRule returns the current fill rule
+func (dc *Context) FillRule() gg.FillRule { return dc.fillRule }
+
+// SetFillStyle sets current fill style
+func (dc *Context) SetFillStyle(pattern gg.Pattern) {
+	if fillStyle, ok := pattern.(*gg.SolidPattern); ok {
+		dc.fillColor = fillStyle.Color
+	}
+	dc.fillPattern = pattern
+}
+
+// SetStrokeStyle sets current stroke style
+func (dc *Context) SetStrokeStyle(pattern gg.Pattern) {
+	dc.strokePattern = pattern
+}
+
+// SetStrokeWeight sets the lineWidth.
+func (dc *Context) SetStrokeWeight(lineWidth float64) { dc.lineWidth = lineWidth }
+
+// StrokeWeight returns the current line width
+func (dc *Context) StrokeWeight() float64 { return dc.lineWidth }
+
+// SetLineCap sets the current line cap
+func (dc *Context) SetLineCap(lineCap gg.LineCap) { dc.lineCap = lineCap }
+
+// SetLineJoin sets the current line join
+func (dc *Context) SetLineJoin(lineJoin gg.LineJoin) { dc.lineJoin = lineJoin }
+
+// SetMiterLimit sets the current miter limit
+func (dc *Context) SetMiterLimit(limit float64) { dc.miterLimit = limit }
+
+// SetLineDash sets the current dash
+func (dc *Context) SetLineDash(dashes ...float64) {
+	dc.dashes = dashes
+}
+
+// SetLineDashOffset sets the initial offset into the dash pattern
+func (dc *Context) SetLineDashOffset(offset float64) {
+	dc.dashOffset = offset
+}
+
+// SetFontSize sets the current font size
+func (dc *Context) SetFontSize(points float64) {
+	dc.fontSize = points * 72 / 96
+}
+
+// FontSize returns font's size
+func (dc *Context) FontSize() float64 {
+	return dc.fontSize
+}
+
+// SetFont sets the current font. Fonts parsed with gg.ParseFont are
+// embedded in the document, the others are drawn as outlines.
+func (dc *Context) SetFont(font *truetype.Font) {
+	dc.font = font
+}
+
+// Push saves the current state of the context for later retrieval. These
+// can be nested.
+func (dc *Context) Push() {
+	x := *dc
+	dc.stack = append(dc.stack, &x)
+}
+
+// Pop restores the last saved context state from the stack.
+func (dc *Context) Pop() {
+	if dc.stack == nil {
+		return
+	}
+	before := *dc
+	s := dc.stack
+	x, s := s[len(s)-1], s[:len(s)-1]
+	*dc = *x
+	dc.page = before.page
+	dc.path = before.path
+	dc.start = before.start
+	dc.current = before.current
+	dc.hasCurrent = before.hasCurrent
+}
+
+// Clear discards all the drawings of the current page and
+// fills it with the current fill color.
+func (dc *Context) Clear() {
+	dc.page.content.Reset()
+
+	nc := color.NRGBAModel.Convert(dc.fillColor).(color.NRGBA)
+	if nc.A == 0 {
+		return
+	}
+	fmt.Fprintf(&dc.page.content, "q\n%s\n0 0 %d %d re\nf\nQ\n",
+		dc.colorOps(nc, 255, "rg"), dc.page.width, dc.page.height)
+}
+
+// Stroke strokes the current path with the current color, line width,
+// line cap, line join and dash settings. The path is cleared after this
+// operation.
+func (dc *Context) Stroke() {
+	if len(dc.path) > 0 {
+		dc.draw(dc.strokeOps(), string(dc.path), "S")
+	}
+	dc.ClearPath()
+}
+
+// FillPreserve fills the current path with the current color. Open subpaths
+// are implicity closed. The path is preserved after this operation.
+func (dc *Context) FillPreserve() {
+	if len(dc.path) > 0 {
+		dc.draw(dc.fillOps(), string(dc.path), dc.fillOperator("f"))
+	}
+}
+
+// Fill fills the current path with the current color. Open subpaths
+// are implicity closed. The path is cleared after this operation.
+func (dc *Context) Fill() {
+	dc.FillPreserve()
+	dc.ClearPath()
+}
+
+// FillAndStroke first fills the paths and than strokes them
+func (dc *Context) FillAndStroke() {
+	if len(dc.path) > 0 {
+		dc.draw(dc.paintOps(dc.fillPattern, patternAlpha(dc.strokePattern), "rg"), dc.strokeStyleOps(),
+			dc.paintOps(dc.strokePattern, patternAlpha(dc.fillPattern), "RG"),
+			string(dc.path), dc.fillOperator("B"))
+	}
+	dc.ClearPath()
+}
+
+// ClipPreserve updates the clipping region by intersecting the current
+// clipping region with the current path as it would be filled by dc.Fill().
+// The path is preserved after this operation.
+func (dc *Context) ClipPreserve() {
+	clips := make([]clipPath, len(dc.clips), len(dc.clips)+1)
+	copy(clips, dc.clips)
+	dc.clips = append(clips, clipPath{
+		path:    append([]byte(nil), dc.path...),
+		evenOdd: dc.fillRule == gg.FillRuleEvenOdd,
+	})
+}
+
+// Clip updates the clipping region by intersecting the current
+// clipping region with the current path as it would be filled by dc.Fill().
+// The path is cleared after this operation.
+func (dc *Context) Clip() {
+	dc.ClipPreserve()
+	dc.ClearPath()
+}
+
+// ResetClip clears the clipping region.
+func (dc *Context) ResetClip() {
+	dc.clips = nil
+}
+
+// Transformation Matrix Operations
+
+// Identity resets the current transformation matrix to the identity matrix.
+// This results in no translating, scaling, rotating, or shearing.
+func (dc *Context) Identity() {
+	dc.matrix = gg.Identity()
+}
+
+// Translate updates the current matrix with a translation.
+func (dc *Context) Translate(x, y float64) {
+	dc.matrix = dc.matrix.Translate(x, y)
+}
+
+// Scale updates the current matrix with a scaling factor.
+// Scaling occurs about the origin.
+func (dc *Context) Scale(x, y float64) {
+	dc.matrix = dc.matrix.Scale(x, y)
+}
+
+// Rotate updates the current matrix with a anticlockwise rotation.
+// Rotation occurs about the origin. Angle is specified in radians.
+func (dc *Context) Rotate(angle float64) {
+	dc.matrix = dc.matrix.Rotate(angle)
+}
+
+// TransformPoint multiplies the specified point by the current matrix,
+// returning a transformed position.
+func (dc *Context) TransformPoint(x, y float64) (tx, ty float64) {
+	return dc.matrix.TransformPoint(x, y)
+}
+
+// Convenient Drawing Functions
+
+// SetPixelColor sets the color of the specified pixel using the current color.
+func (dc *Context) SetPixelColor(c color.Color, x, y int) {
+	nc := color.NRGBAModel.Convert(c).(color.NRGBA)
+	dc.draw(dc.colorOps(nc, 255, "rg"), fmt.Sprintf("%d %d 1 1 re", x, y), "f")
+}
+
+// DrawPoint draws a point
+func (dc *Context) DrawPoint(x, y float64) {
+	r := math.Max(1, dc.lineWidth)
+
+	dc.Push()
+	tx, ty := dc.TransformPoint(x, y)
+	dc.Identity()
+
+	dc.BeginPath()
+	dc.DrawEllipticalArc(tx, ty, r, r, 0, 2*math.Pi)
+	dc.ClosePath()
+
+	dc.Pop()
+}
+
+// DrawLine draws a line
+func (dc *Context) DrawLine(x1, y1, x2, y2 float64) {
+	dc.MoveTo(x1, y1)
+	dc.LineTo(x2, y2)
+}
+
+// DrawEllipticalArc draws an elliptical arc
+func (dc *Context) DrawEllipticalArc(x, y, rx, ry, angle1, angle2 float64) {
+	gg.DrawEllipticalArc(dc, x, y, rx, ry, angle1, angle2)
+}
+
+// DrawImageAnchored draws the specified image at the specified anchor point.
+// The anchor point is x - w * ax, y - h * ay, where w, h is the size of the
+// image. Use ax=0.5, ay=0.5 to center the image at the specified point.
+func (dc *Context) DrawImageAnchored(im image.Image, x, y int, ax, ay float64) {
+	s := im.Bounds().Size()
+	x -= int(ax * float64(s.X))
+	y -= int(ay * float64(s.Y))
+
+	res := dc.doc.imageResource(im)
+
+	// images are painted in the unit square, upside down
+	// because of the flipped y axis of the page
+	dc.draw(matrixOps(dc.matrix),
+		fmt.Sprintf("%d 0 0 -%d %d %d cm", s.X, s.Y, x, y+s.Y),
+		fmt.Sprintf("/%s Do", res.name))
+}
+
+// DrawStringAnchored draws the specified text at the specified anchor point.
+// The anchor point is x - w * ax, y - h * ay, where w, h is the size of the
+// text. Use ax=0.5, ay=0.5 to center the text at the specified point.
+func (dc *Context) DrawStringAnchored(s string, x, y, ax, ay float64) {
+	f, err := dc.currentFont()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning pdf.Context DrawStringAnchored error: %s", err.Error())
+		return
+	}
+
+	w, h := dc.MeasureString(s)
+	x -= ax * w
+	y += ay * h
+
+	data, ok := gg.FontData(f)
+	if !ok {
+		// the font can't be embedded: draw the glyph outlines
+		path, start, current, hasCurrent := dc.path, dc.start, dc.current, dc.hasCurrent
+		dc.path, dc.hasCurrent = nil, false
+
+		gg.TextOutline(dc, f, dc.fontSize, s, x, y)
+		if len(dc.path) > 0 {
+			dc.draw(dc.paintOps(dc.strokePattern, 255, "rg"), string(dc.path), "f")
+		}
+
+		dc.path, dc.start, dc.current, dc.hasCurrent = path, start, current, hasCurrent
+		return
+	}
+
+	res := dc.doc.fontResource(f, data)
+
+	// glyph positions are expressed in thousandths of the text size,
+	// kerning moves the next glyph on the left by negative amounts
+	const em = fixed.Int26_6(1000 << 6)
+
+	var sb strings.Builder
+	sb.WriteString("[<")
+	prev, hasPrev := truetype.Index(0), false
+	for _, r := range s {
+		idx := f.Index(r)
+		if hasPrev {
+			if kern := f.Kern(em, prev, idx).Round(); kern != 0 {
+				fmt.Fprintf(&sb, "> %d <", -kern)
+			}
+		}
+		fmt.Fprintf(&sb, "%04x", int(idx))
+		if _, ok := res.glyphs[idx]; !ok {
+			res.glyphs[idx] = r
+		}
+		prev, hasPrev = idx, true
+	}
+	sb.WriteString(">] TJ")
+
+	dc.draw(matrixOps(dc.matrix), dc.paintOps(dc.strokePattern, 255, "rg"),
+		fmt.Sprintf("BT\n/%s %s Tf\n1 0 0 -1 %s %s Tm", res.name, num(dc.fontSize), num(x), num(y)),
+		sb.String(), "ET")
+}
+
+// MeasureString returns the rendered width and height of the specified text
+// given the current font face.
+func (dc *Context) MeasureString(s string) (w, h float64) {
+	f, err := dc.currentFont()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning pdf.Context MeasureString error: %s", err.Error())
+		return 0, 0
+	}
+
+	d := &font.Drawer{Face: truetype.NewFace(f, &truetype.Options{Size: dc.fontSize})}
+	a := d.MeasureString(s)
+	return float64(a >> 6), dc.fontSize
+}
+
+// FontMetrics returns how far the current font face rises above
+// and descends below the baseline.
+func (dc *Context) FontMetrics() (ascent, descent float64) {
+	f, err := dc.currentFont()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning pdf.Context FontMetrics error: %s", err.Error())
+		return 0, 0
+	}
+
+	m := truetype.NewFace(f, &truetype.Options{Size: dc.fontSize}).Metrics()
+	return float64(m.Ascent) / 64, float64(m.Descent) / 64
+}
+
+// draw adds the operators to the current page, applying
+// the clipping region and isolating the graphic state.
+func (dc *Context) draw(ops ...string) {
+	c := &dc.page.content
+	c.WriteString("q\n")
+	for _, el := range dc.clips {
+		c.Write(el.path)
+		if el.evenOdd {
+			c.WriteString("W* n\n")
+		} else {
+			c.WriteString("W n\n")
+		}
+	}
+	for _, el := range ops {
+		c.WriteString(el)
+		if !strings.HasSuffix(el, "\n") {
+			c.WriteByte('\n')
+		}
+	}
+	c.WriteString("Q\n")
+}
+
+func (dc *Context) fillOperator(op string) string {
+	if dc.fillRule == gg.FillRuleEvenOdd {
+		return op + "*"
+	}
+	return op
+}
+
+func (dc *Context) fillOps() string {
+	return dc.paintOps(dc.fillPattern, 255, "rg")
+}
+
+func (dc *Context) strokeOps() string {
+	return dc.paintOps(dc.strokePattern, 255, "RG") + "\n" + dc.strokeStyleOps()
+}
+
+// paintOps returns the operators setting the paint (op is "rg" for the
+// non-stroking paint and "RG" for the stroking one) using the specified
+// pattern. The other opacity is left to the specified value.
+func (dc *Context) paintOps(pattern gg.Pattern, other uint8, op string) string {
+	p := gg.DevicePattern(pattern, dc.matrix)
+	if solid, ok := p.(*gg.SolidPattern); ok {
+		return dc.colorOps(color.NRGBAModel.Convert(solid.Color).(color.NRGBA), other, op)
+	}
+
+	res := dc.doc.patternResource(p, dc.page.width, dc.page.height)
+	if op == "RG" {
+		return fmt.Sprintf("/Pattern CS /%s SCN", res.name)
+	}
+	return fmt.Sprintf("/Pattern cs /%s scn", res.name)
+}
+
+func (dc *Context) strokeStyleOps() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s w", num(dc.lineWidth))
+
+	switch dc.lineCap {
+	case gg.LineCapRound:
+		sb.WriteString(" 1 J")
+	case gg.LineCapSquare:
+		sb.WriteString(" 2 J")
+	default:
+		sb.WriteString(" 0 J")
+	}
+
+	switch dc.lineJoin {
+	case gg.LineJoinRound:
+		sb.WriteString(" 1 j")
+	case gg.LineJoinBevel:
+		sb.WriteString(" 2 j")
+	default:
+		fmt.Fprintf(&sb, " 0 j %s M", num(dc.miterLimit))
+	}
+
+	if len(dc.dashes) > 0 {
+		dashes := make([]string, len(dc.dashes))
+		for i, el := range dc.dashes {
+			dashes[i] = num(el)
+		}
+		fmt.Fprintf(&sb, " [%s] %s d", strings.Join(dashes, " "), num(dc.dashOffset))
+	}
+
+	return sb.String()
+}
+
+// colorOps returns the operators setting the color (op is "rg" for the
+// non-stroking color and "RG" for the stroking one) and its opacity.
+// The other opacity is left to the specified value.
+func (dc *Context) colorOps(c color.NRGBA, other uint8, op string) string {
+	res := fmt.Sprintf("%s %s %s %s",
+		num(float64(c.R)/255), num(float64(c.G)/255), num(float64(c.B)/255), op)
+	if c.A == 255 && other == 255 {
+		return res
+	}
+
+	fillAlpha, strokeAlpha := float64(c.A)/255, float64(other)/255
+	if op == "RG" {
+		fillAlpha, strokeAlpha = strokeAlpha, fillAlpha
+	}
+	gs := dc.doc.stateResource(fillAlpha, strokeAlpha)
+	return res + fmt.Sprintf(" /%s gs", gs.name)
+}
+
+func (dc *Context) currentFont() (*truetype.Font, error) {
+	if dc.font == nil {
+		val, err := defaultFont()
+		if err != nil {
+			return nil, err
+		}
+		dc.font = val
+	}
+
+	return dc.font, nil
+}
+
+var (
+	defaultFontOnce sync.Once
+	defaultFontVal  *truetype.Font
+	defaultFontErr  error
+)
+
+// defaultFont parses the default font only once,
+// so it is embedded just once in the document.
+func defaultFont() (*truetype.Font, error) {
+	defaultFontOnce.Do(func() {
+		defaultFontVal, defaultFontErr = gg.BundledFont("mono")
+	})
+	return defaultFontVal, defaultFontErr
+}
+
+// patternAlpha returns the opacity of the solid colors,
+// the other patterns are painted as opaque.
+func patternAlpha(pattern gg.Pattern) uint8 {
+	if solid, ok := pattern.(*gg.SolidPattern); ok {
+		return color.NRGBAModel.Convert(solid.Color).(color.NRGBA).A
+	}
+	return 255
+}
+
+func appendPoints(buf []byte, points ...point) []byte {
+	for i, p := range points {
+		if i > 0 {
+			buf = append(buf, ' ')
+		}
+		buf = append(buf, num(p.X)...)
+		buf = append(buf, ' ')
+		buf = append(buf, num(p.Y)...)
+	}
+	return buf
+}
+
+func matrixOps(m gg.Matrix) string {
+	return matrixArray(m) + " cm"
+}
+
+func matrixArray(m gg.Matrix) string {
+	return fmt.Sprintf("%s %s %s %s %s %s",
+		num(m.XX), num(m.YX), num(m.XY), num(m.YY), num(m.X0), num(m.Y0))
+}
+
+// num formats a number using at most three decimal digits
+func num(v float64) string {
+	v = math.Round(v*1000) / 1000
+	if v == 0 {
+		// avoid "-0"
+		v = 0
+	}
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
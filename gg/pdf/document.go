@@ -0,0 +1,442 @@
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"sort"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/golang/freetype/truetype"
+	"golang.org/x/image/math/fixed"
+
+	"github.com/lucasepe/g2d/gg"
+)
+
+// document collects the pages and the resources (fonts, images,
+// graphic states and patterns) shared by all the pages.
+type document struct {
+	pages    []*page
+	fonts    []*fontResource
+	images   []*imageResource
+	states   []*stateResource
+	patterns []*patternResource
+}
+
+type page struct {
+	width   int
+	height  int
+	content bytes.Buffer
+}
+
+type fontResource struct {
+	name   string
+	font   *truetype.Font
+	data   []byte
+	glyphs map[truetype.Index]rune
+}
+
+type imageResource struct {
+	name string
+	im   image.Image
+}
+
+// stateResource is an external graphic state used to set the opacity
+type stateResource struct {
+	name        string
+	fillAlpha   float64
+	strokeAlpha float64
+}
+
+// patternResource is a pattern painted on a page with the specified height:
+// gradients become shading patterns, the other patterns are rendered
+// as images the size of the page, repeated by a tiling pattern.
+type patternResource struct {
+	name    string
+	pattern gg.Pattern
+	width   int
+	height  int
+	image   *imageResource
+}
+
+func (doc *document) addPage(width, height int) *page {
+	res := &page{width: width, height: height}
+	doc.pages = append(doc.pages, res)
+	return res
+}
+
+func (doc *document) fontResource(f *truetype.Font, data []byte) *fontResource {
+	for _, el := range doc.fonts {
+		if el.font == f {
+			return el
+		}
+	}
+
+	res := &fontResource{
+		name:   fmt.Sprintf("F%d", len(doc.fonts)+1),
+		font:   f,
+		data:   data,
+		glyphs: map[truetype.Index]rune{},
+	}
+	doc.fonts = append(doc.fonts, res)
+	return res
+}
+
+func (doc *document) imageResource(im image.Image) *imageResource {
+	res := &imageResource{
+		name: fmt.Sprintf("Im%d", len(doc.images)+1),
+		im:   im,
+	}
+	doc.images = append(doc.images, res)
+	return res
+}
+
+func (doc *document) stateResource(fillAlpha, strokeAlpha float64) *stateResource {
+	for _, el := range doc.states {
+		if el.fillAlpha == fillAlpha && el.strokeAlpha == strokeAlpha {
+			return el
+		}
+	}
+
+	res := &stateResource{
+		name:        fmt.Sprintf("GS%d", len(doc.states)+1),
+		fillAlpha:   fillAlpha,
+		strokeAlpha: strokeAlpha,
+	}
+	doc.states = append(doc.states, res)
+	return res
+}
+
+func (doc *document) patternResource(p gg.Pattern, width, height int) *patternResource {
+	res := &patternResource{
+		name:    fmt.Sprintf("P%d", len(doc.patterns)+1),
+		pattern: p,
+		width:   width,
+		height:  height,
+	}
+
+	switch p.(type) {
+	case *gg.LinearGradient, *gg.RadialGradient:
+	default:
+		res.image = doc.imageResource(gg.RenderPattern(p, width, height))
+	}
+
+	doc.patterns = append(doc.patterns, res)
+	return res
+}
+
+// writer keeps track of the objects offsets for the cross-reference table
+type writer struct {
+	buf     bytes.Buffer
+	offsets []int
+}
+
+func (w *writer) newObject() int {
+	w.offsets = append(w.offsets, 0)
+	return len(w.offsets)
+}
+
+func (w *writer) object(n int, format string, a ...interface{}) {
+	w.offsets[n-1] = w.buf.Len()
+	fmt.Fprintf(&w.buf, "%d 0 obj\n", n)
+	fmt.Fprintf(&w.buf, format, a...)
+	w.buf.WriteString("\nendobj\n")
+}
+
+func (w *writer) stream(n int, dict string, data []byte) {
+	var zb bytes.Buffer
+	zw := zlib.NewWriter(&zb)
+	zw.Write(data)
+	zw.Close()
+
+	w.offsets[n-1] = w.buf.Len()
+	fmt.Fprintf(&w.buf, "%d 0 obj\n<< %s /Filter /FlateDecode /Length %d >>\nstream\n", n, dict, zb.Len())
+	w.buf.Write(zb.Bytes())
+	w.buf.WriteString("\nendstream\nendobj\n")
+}
+
+// writeTo serializes the document. The last page is omitted if blank.
+func (doc *document) writeTo(out io.Writer) (int64, error) {
+	pages := doc.pages
+	if n := len(pages); n > 1 && pages[n-1].content.Len() == 0 {
+		pages = pages[:n-1]
+	}
+
+	w := &writer{}
+	w.buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	catalog := w.newObject()
+	pagesObj := w.newObject()
+	resources := w.newObject()
+
+	fontObjs := make([]int, len(doc.fonts))
+	for i := range doc.fonts {
+		fontObjs[i] = w.newObject()
+	}
+
+	imageObjs := make([]int, len(doc.images))
+	for i := range doc.images {
+		imageObjs[i] = w.newObject()
+	}
+
+	patternObjs := make([]int, len(doc.patterns))
+	for i := range doc.patterns {
+		patternObjs[i] = w.newObject()
+	}
+
+	pageObjs := make([]int, len(pages))
+	kids := make([]string, len(pages))
+	for i := range pages {
+		pageObjs[i] = w.newObject()
+		kids[i] = fmt.Sprintf("%d 0 R", pageObjs[i])
+	}
+
+	w.object(catalog, "<< /Type /Catalog /Pages %d 0 R >>", pagesObj)
+	w.object(pagesObj, "<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages))
+
+	var res strings.Builder
+	res.WriteString("<< /ProcSet [/PDF /Text /ImageB /ImageC]")
+	if len(doc.fonts) > 0 {
+		res.WriteString(" /Font <<")
+		for i, el := range doc.fonts {
+			fmt.Fprintf(&res, " /%s %d 0 R", el.name, fontObjs[i])
+		}
+		res.WriteString(" >>")
+	}
+	if len(doc.images) > 0 {
+		res.WriteString(" /XObject <<")
+		for i, el := range doc.images {
+			fmt.Fprintf(&res, " /%s %d 0 R", el.name, imageObjs[i])
+		}
+		res.WriteString(" >>")
+	}
+	if len(doc.states) > 0 {
+		res.WriteString(" /ExtGState <<")
+		for _, el := range doc.states {
+			fmt.Fprintf(&res, " /%s << /ca %s /CA %s >>", el.name, num(el.fillAlpha), num(el.strokeAlpha))
+		}
+		res.WriteString(" >>")
+	}
+	if len(doc.patterns) > 0 {
+		res.WriteString(" /Pattern <<")
+		for i, el := range doc.patterns {
+			fmt.Fprintf(&res, " /%s %d 0 R", el.name, patternObjs[i])
+		}
+		res.WriteString(" >>")
+	}
+	res.WriteString(" >>")
+	w.object(resources, res.String())
+
+	for i, el := range doc.fonts {
+		el.write(w, fontObjs[i])
+	}
+
+	for i, el := range doc.images {
+		el.write(w, imageObjs[i])
+	}
+
+	for i, el := range doc.patterns {
+		el.write(w, patternObjs[i], resources)
+	}
+
+	for i, el := range pages {
+		content := w.newObject()
+		w.object(pageObjs[i], "<< /Type /Page /Parent %d 0 R /MediaBox [0 0 %d %d] /Resources %d 0 R /Contents %d 0 R >>",
+			pagesObj, el.width, el.height, resources, content)
+
+		// flip the y axis so that the origin is at the top left corner
+		var data bytes.Buffer
+		fmt.Fprintf(&data, "1 0 0 -1 0 %d cm\n", el.height)
+		data.Write(el.content.Bytes())
+		w.stream(content, "", data.Bytes())
+	}
+
+	xref := w.buf.Len()
+	fmt.Fprintf(&w.buf, "xref\n0 %d\n0000000000 65535 f \n", len(w.offsets)+1)
+	for _, el := range w.offsets {
+		fmt.Fprintf(&w.buf, "%010d 00000 n \n", el)
+	}
+	fmt.Fprintf(&w.buf, "trailer\n<< /Size %d /Root %d 0 R >>\nstartxref\n%d\n%%%%EOF\n",
+		len(w.offsets)+1, catalog, xref)
+
+	return w.buf.WriteTo(out)
+}
+
+// write embeds the whole TrueType font as a composite font
+// that uses the glyph indices as character codes.
+func (fr *fontResource) write(w *writer, n int) {
+	const em = fixed.Int26_6(1000 << 6)
+
+	cidFont := w.newObject()
+	descriptor := w.newObject()
+	fontFile := w.newObject()
+	toUnicode := w.newObject()
+
+	name := postscriptName(fr.font)
+
+	w.object(n, "<< /Type /Font /Subtype /Type0 /BaseFont /%s /Encoding /Identity-H "+
+		"/DescendantFonts [%d 0 R] /ToUnicode %d 0 R >>", name, cidFont, toUnicode)
+
+	indices := make([]int, 0, len(fr.glyphs))
+	for idx := range fr.glyphs {
+		indices = append(indices, int(idx))
+	}
+	sort.Ints(indices)
+
+	var widths strings.Builder
+	for _, idx := range indices {
+		adv := fr.font.HMetric(em, truetype.Index(idx)).AdvanceWidth
+		fmt.Fprintf(&widths, "%d [%d] ", idx, adv.Round())
+	}
+
+	w.object(cidFont, "<< /Type /Font /Subtype /CIDFontType2 /BaseFont /%s "+
+		"/CIDSystemInfo << /Registry (Adobe) /Ordering (Identity) /Supplement 0 >> "+
+		"/FontDescriptor %d 0 R /DW 1000 /W [%s] /CIDToGIDMap /Identity >>",
+		name, descriptor, widths.String())
+
+	bounds := fr.font.Bounds(em)
+	metrics := truetype.NewFace(fr.font, &truetype.Options{Size: 1000}).Metrics()
+	w.object(descriptor, "<< /Type /FontDescriptor /FontName /%s /Flags 4 /FontBBox [%d %d %d %d] "+
+		"/ItalicAngle 0 /Ascent %d /Descent %d /CapHeight %d /StemV 80 /FontFile2 %d 0 R >>",
+		name, bounds.Min.X.Round(), bounds.Min.Y.Round(), bounds.Max.X.Round(), bounds.Max.Y.Round(),
+		metrics.Ascent.Round(), -metrics.Descent.Round(), metrics.Ascent.Round(), fontFile)
+
+	w.stream(fontFile, fmt.Sprintf("/Length1 %d", len(fr.data)), fr.data)
+
+	var cmap bytes.Buffer
+	cmap.WriteString("/CIDInit /ProcSet findresource begin\n12 dict begin\nbegincmap\n" +
+		"/CIDSystemInfo << /Registry (Adobe) /Ordering (UCS) /Supplement 0 >> def\n" +
+		"/CMapName /Adobe-Identity-UCS def\n/CMapType 2 def\n" +
+		"1 begincodespacerange\n<0000> <FFFF>\nendcodespacerange\n")
+	for i := 0; i < len(indices); i += 100 {
+		chunk := indices[i:]
+		if len(chunk) > 100 {
+			chunk = chunk[:100]
+		}
+		fmt.Fprintf(&cmap, "%d beginbfchar\n", len(chunk))
+		for _, idx := range chunk {
+			fmt.Fprintf(&cmap, "<%04x> <", idx)
+			for _, el := range utf16.Encode([]rune{fr.glyphs[truetype.Index(idx)]}) {
+				fmt.Fprintf(&cmap, "%04x", el)
+			}
+			cmap.WriteString(">\n")
+		}
+		cmap.WriteString("endbfchar\n")
+	}
+	cmap.WriteString("endcmap\nCMapName currentdict /CMap defineresource pop\nend\nend\n")
+	w.stream(toUnicode, "", cmap.Bytes())
+}
+
+// write embeds the image as RGB samples with an optional soft mask for the alpha channel
+func (ir *imageResource) write(w *writer, n int) {
+	b := ir.im.Bounds()
+	rgb := make([]byte, 0, b.Dx()*b.Dy()*3)
+	alpha := make([]byte, 0, b.Dx()*b.Dy())
+	opaque := true
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			c := color.NRGBAModel.Convert(ir.im.At(x, y)).(color.NRGBA)
+			rgb = append(rgb, c.R, c.G, c.B)
+			alpha = append(alpha, c.A)
+			if c.A != 255 {
+				opaque = false
+			}
+		}
+	}
+
+	dict := fmt.Sprintf("/Type /XObject /Subtype /Image /Width %d /Height %d "+
+		"/ColorSpace /DeviceRGB /BitsPerComponent 8", b.Dx(), b.Dy())
+	if !opaque {
+		mask := w.newObject()
+		dict += fmt.Sprintf(" /SMask %d 0 R", mask)
+		w.stream(mask, fmt.Sprintf("/Type /XObject /Subtype /Image /Width %d /Height %d "+
+			"/ColorSpace /DeviceGray /BitsPerComponent 8", b.Dx(), b.Dy()), alpha)
+	}
+	w.stream(n, dict, rgb)
+}
+
+// write embeds the pattern, the tiling patterns use the shared resources
+func (pr *patternResource) write(w *writer, n int, resources int) {
+	// patterns are defined in the default coordinate space
+	// of the page, whose y axis is flipped
+	flip := gg.Matrix{XX: 1, YY: -1, Y0: float64(pr.height)}
+
+	switch p := pr.pattern.(type) {
+	case *gg.LinearGradient:
+		w.object(n, "<< /Type /Pattern /PatternType 2 /Matrix [%s] /Shading << /ShadingType 2 "+
+			"/ColorSpace /DeviceRGB /Coords [%s %s %s %s] /Function %s /Extend [true true] >> >>",
+			matrixArray(p.Matrix.Multiply(flip)), num(p.X0), num(p.Y0), num(p.X1), num(p.Y1),
+			stopsFunction(p.Stops))
+	case *gg.RadialGradient:
+		w.object(n, "<< /Type /Pattern /PatternType 2 /Matrix [%s] /Shading << /ShadingType 3 "+
+			"/ColorSpace /DeviceRGB /Coords [%s %s %s %s %s %s] /Function %s /Extend [true true] >> >>",
+			matrixArray(p.Matrix.Multiply(flip)), num(p.X0), num(p.Y0), num(p.R0),
+			num(p.X1), num(p.Y1), num(p.R1), stopsFunction(p.Stops))
+	default:
+		dict := fmt.Sprintf("/Type /Pattern /PatternType 1 /PaintType 1 /TilingType 1 "+
+			"/BBox [0 0 %d %d] /XStep %d /YStep %d /Matrix [%s] /Resources %d 0 R",
+			pr.width, pr.height, pr.width, pr.height, matrixArray(flip), resources)
+		content := fmt.Sprintf("%d 0 0 -%d 0 %d cm\n/%s Do\n", pr.width, pr.height, pr.height, pr.image.name)
+		w.stream(n, dict, []byte(content))
+	}
+}
+
+// stopsFunction returns the function interpolating the colors of the stops,
+// stitching an exponential interpolation function for each pair of stops.
+// The opacity of the stops is ignored.
+func stopsFunction(stops gg.Stops) string {
+	if len(stops) == 0 {
+		stops = gg.Stops{{Offset: 0, Color: color.Black}}
+	}
+	if first := stops[0]; first.Offset > 0 {
+		stops = append(gg.Stops{{Offset: 0, Color: first.Color}}, stops...)
+	}
+	if last := stops[len(stops)-1]; last.Offset < 1 {
+		stops = append(stops, gg.Stop{Offset: 1, Color: last.Color})
+	}
+
+	rgb := func(c color.Color) string {
+		nc := color.NRGBAModel.Convert(c).(color.NRGBA)
+		return fmt.Sprintf("[%s %s %s]", num(float64(nc.R)/255), num(float64(nc.G)/255), num(float64(nc.B)/255))
+	}
+
+	if len(stops) == 1 {
+		c := rgb(stops[0].Color)
+		return fmt.Sprintf("<< /FunctionType 2 /Domain [0 1] /C0 %s /C1 %s /N 1 >>", c, c)
+	}
+
+	var fns, bounds, encode []string
+	for i := 1; i < len(stops); i++ {
+		fns = append(fns, fmt.Sprintf("<< /FunctionType 2 /Domain [0 1] /C0 %s /C1 %s /N 1 >>",
+			rgb(stops[i-1].Color), rgb(stops[i].Color)))
+		encode = append(encode, "0 1")
+		if i < len(stops)-1 {
+			bounds = append(bounds, num(stops[i].Offset))
+		}
+	}
+
+	return fmt.Sprintf("<< /FunctionType 3 /Domain [0 1] /Functions [%s] /Bounds [%s] /Encode [%s] >>",
+		strings.Join(fns, " "), strings.Join(bounds, " "), strings.Join(encode, " "))
+}
+
+func postscriptName(f *truetype.Font) string {
+	name := f.Name(truetype.NameIDPostscriptName)
+	if name == "" {
+		name = f.Name(truetype.NameIDFontFullName)
+	}
+
+	var sb strings.Builder
+	for _, r := range name {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' {
+			sb.WriteRune(r)
+		}
+	}
+	if sb.Len() == 0 {
+		return "Font"
+	}
+	return sb.String()
+}
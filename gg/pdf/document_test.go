@@ -0,0 +1,272 @@
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"golang.org/x/image/font/gofont/goregular"
+
+	"github.com/lucasepe/g2d/gg"
+)
+
+// object is an indirect object of a PDF file
+type object struct {
+	dict   string
+	stream []byte
+}
+
+// parse reads back the document through its cross-reference table,
+// checking that each entry points to the right object.
+func parse(t *testing.T, dc *Context) (map[int]object, string) {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if err := dc.Encode(&buf, "pdf"); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+
+	if !bytes.HasPrefix(data, []byte("%PDF-1.4\n")) {
+		t.Fatalf("missing PDF header")
+	}
+	if !bytes.HasSuffix(data, []byte("%%EOF\n")) {
+		t.Fatalf("missing end of file marker")
+	}
+
+	m := regexp.MustCompile(`startxref\n(\d+)\n%%EOF\n$`).FindSubmatch(data)
+	if m == nil {
+		t.Fatalf("missing startxref")
+	}
+	xref, _ := strconv.Atoi(string(m[1]))
+	if !bytes.HasPrefix(data[xref:], []byte("xref\n")) {
+		t.Fatalf("startxref %d does not point to the cross-reference table", xref)
+	}
+
+	lines := strings.Split(string(data[xref:]), "\n")
+	var first, count int
+	fmt.Sscanf(lines[1], "%d %d", &first, &count)
+	if first != 0 || lines[2] != "0000000000 65535 f " {
+		t.Fatalf("wrong first cross-reference entries: %q", lines[1:3])
+	}
+
+	trailer := strings.Join(lines[count+2:], "\n")
+	if !strings.HasPrefix(trailer, fmt.Sprintf("trailer\n<< /Size %d /Root ", count)) {
+		t.Fatalf("wrong trailer: %q", trailer)
+	}
+
+	objects := map[int]object{}
+	for n := 1; n < count; n++ {
+		entry := lines[n+2]
+		if len(entry) != 19 || !strings.HasSuffix(entry, " 00000 n ") {
+			t.Fatalf("malformed cross-reference entry %q", entry)
+		}
+		offset, _ := strconv.Atoi(entry[:10])
+
+		header := fmt.Sprintf("%d 0 obj\n", n)
+		if !bytes.HasPrefix(data[offset:], []byte(header)) {
+			t.Fatalf("cross-reference entry of object %d points to %q", n, data[offset:offset+20])
+		}
+		body := data[offset+len(header):]
+
+		if end := bytes.Index(body, []byte(">>\nstream\n")); end >= 0 && end < bytes.Index(body, []byte("\nendobj\n")) {
+			dict := string(body[:end+2])
+			l := regexp.MustCompile(`/Length (\d+)`).FindStringSubmatch(dict)
+			if l == nil {
+				t.Fatalf("stream of object %d without length", n)
+			}
+			size, _ := strconv.Atoi(l[1])
+			raw := body[end+len(">>\nstream\n"):]
+			if !bytes.HasPrefix(raw[size:], []byte("\nendstream\nendobj\n")) {
+				t.Fatalf("wrong length of the stream of object %d", n)
+			}
+
+			zr, err := zlib.NewReader(bytes.NewReader(raw[:size]))
+			if err != nil {
+				t.Fatalf("object %d: %s", n, err)
+			}
+			stream, err := ioutil.ReadAll(zr)
+			if err != nil {
+				t.Fatalf("object %d: %s", n, err)
+			}
+			objects[n] = object{dict: dict, stream: stream}
+			continue
+		}
+
+		end := bytes.Index(body, []byte("\nendobj\n"))
+		objects[n] = object{dict: string(body[:end])}
+	}
+
+	return objects, trailer
+}
+
+// ref returns the object referenced by the key in the dictionary
+func ref(t *testing.T, objects map[int]object, dict, key string) object {
+	t.Helper()
+
+	m := regexp.MustCompile(key + ` ?(\d+) 0 R`).FindStringSubmatch(dict)
+	if m == nil {
+		t.Fatalf("missing %s in %q", key, dict)
+	}
+	n, _ := strconv.Atoi(m[1])
+	res, ok := objects[n]
+	if !ok {
+		t.Fatalf("%s references the missing object %d", key, n)
+	}
+	return res
+}
+
+// pages returns the content streams of the pages, in order
+func pages(t *testing.T, objects map[int]object, trailer string) []string {
+	t.Helper()
+
+	catalog := ref(t, objects, trailer, "/Root")
+	root := ref(t, objects, catalog.dict, "/Pages")
+
+	m := regexp.MustCompile(`/Kids \[([^\]]*)\] /Count (\d+)`).FindStringSubmatch(root.dict)
+	if m == nil {
+		t.Fatalf("malformed pages tree %q", root.dict)
+	}
+
+	var res []string
+	for _, kid := range regexp.MustCompile(`\d+ 0 R`).FindAllString(m[1], -1) {
+		page := ref(t, objects, "/Kids ["+kid, `/Kids \[`)
+		if !strings.HasPrefix(page.dict, "<< /Type /Page ") {
+			t.Fatalf("kid is not a page: %q", page.dict)
+		}
+		res = append(res, string(ref(t, objects, page.dict, "/Contents").stream))
+	}
+
+	if count, _ := strconv.Atoi(m[2]); count != len(res) {
+		t.Fatalf("wrong page count. expected=%d, got=%d", len(res), count)
+	}
+	return res
+}
+
+func TestDocument(t *testing.T) {
+	dc := NewContext(200, 100)
+	dc.MoveTo(10, 20)
+	dc.LineTo(30, 40)
+	dc.Stroke()
+
+	objects, trailer := parse(t, dc)
+
+	content := pages(t, objects, trailer)
+	if len(content) != 1 {
+		t.Fatalf("wrong number of pages. expected=1, got=%d", len(content))
+	}
+	if !strings.HasPrefix(content[0], "1 0 0 -1 0 100 cm\n") {
+		t.Errorf("the y axis is not flipped: %q", content[0])
+	}
+	if !strings.Contains(content[0], "10 20 m\n30 40 l\n") {
+		t.Errorf("missing path in the page content: %q", content[0])
+	}
+
+	catalog := ref(t, objects, trailer, "/Root")
+	page := ref(t, objects, ref(t, objects, catalog.dict, "/Pages").dict, `/Kids \[`)
+	if !strings.Contains(page.dict, "/MediaBox [0 0 200 100]") {
+		t.Errorf("wrong media box: %q", page.dict)
+	}
+}
+
+func TestPaginator(t *testing.T) {
+	dc := NewContext(100, 100)
+
+	var p gg.Paginator = dc
+	for i := 1; i <= 3; i++ {
+		if i == 3 {
+			dc.SetPageSize(300, 50)
+		}
+		dc.MoveTo(float64(i), 0)
+		dc.LineTo(0, float64(i))
+		dc.Stroke()
+		p.ShowPage()
+	}
+
+	objects, trailer := parse(t, dc)
+
+	// the blank page started by the last ShowPage is omitted
+	content := pages(t, objects, trailer)
+	if len(content) != 3 {
+		t.Fatalf("wrong number of pages. expected=3, got=%d", len(content))
+	}
+	for i, el := range content {
+		if !strings.Contains(el, fmt.Sprintf("%d 0 m\n0 %d l\n", i+1, i+1)) {
+			t.Errorf("wrong content of page #%d: %q", i+1, el)
+		}
+	}
+
+	if !strings.Contains(content[2], "1 0 0 -1 0 50 cm") {
+		t.Errorf("the last page does not have the new size: %q", content[2])
+	}
+}
+
+func TestEmbeddedFont(t *testing.T) {
+	f, err := gg.ParseFont("goregular", goregular.TTF)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dc := NewContext(200, 100)
+	dc.SetFont(f)
+	dc.DrawStringAnchored("Hello", 100, 50, 0.5, 0.5)
+	dc.DrawStringAnchored("World", 100, 80, 0.5, 0.5)
+
+	objects, trailer := parse(t, dc)
+
+	content := pages(t, objects, trailer)[0]
+	if strings.Count(content, "/F1 ") != 2 || strings.Count(content, "TJ") != 2 {
+		t.Fatalf("text not drawn with the embedded font: %q", content)
+	}
+
+	var fonts []object
+	for _, el := range objects {
+		if strings.HasPrefix(el.dict, "<< /Type /Font /Subtype /Type0 ") {
+			fonts = append(fonts, el)
+		}
+	}
+	if len(fonts) != 1 {
+		t.Fatalf("the font must be embedded once. got=%d", len(fonts))
+	}
+
+	cid := ref(t, objects, fonts[0].dict, `/DescendantFonts \[`)
+	descriptor := ref(t, objects, cid.dict, "/FontDescriptor")
+	file := ref(t, objects, descriptor.dict, "/FontFile2")
+	if !bytes.Equal(file.stream, goregular.TTF) {
+		t.Errorf("the embedded font data differs from the original")
+	}
+	if !strings.Contains(file.dict, fmt.Sprintf("/Length1 %d", len(goregular.TTF))) {
+		t.Errorf("wrong /Length1: %q", file.dict)
+	}
+
+	// every glyph used maps back to its character
+	cmap := string(ref(t, objects, fonts[0].dict, "/ToUnicode").stream)
+	for _, r := range "HeloWrd" {
+		entry := fmt.Sprintf("<%04x> <%04x>", int(f.Index(r)), r)
+		if !strings.Contains(cmap, entry) {
+			t.Errorf("missing %q in the ToUnicode map", entry)
+		}
+	}
+}
+
+func TestBundledFont(t *testing.T) {
+	f, err := gg.BundledFont("bold")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dc := NewContext(200, 100)
+	dc.SetFont(f)
+	dc.DrawStringAnchored("Hi", 100, 50, 0.5, 0.5)
+
+	objects, trailer := parse(t, dc)
+	content := pages(t, objects, trailer)[0]
+	if !strings.Contains(content, "TJ") {
+		t.Errorf("bundled font not embedded: %q", content)
+	}
+}
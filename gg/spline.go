@@ -0,0 +1,38 @@
+package gg
+
+// CurveThrough adds to the current path of the specified builder a
+// Catmull-Rom spline passing through all the given points, as a sequence of
+// cubic Bézier curves. The tension ranges from 0 (a Catmull-Rom spline) to 1
+// (straight segments). The spline is connected to the path's latest point
+// with a straight line, if any.
+func CurveThrough(dc PathBuilder, points [][2]float64, tension float64) {
+	if len(points) == 0 {
+		return
+	}
+
+	if _, _, ok := dc.CurrentPoint(); ok {
+		dc.LineTo(points[0][0], points[0][1])
+	} else {
+		dc.MoveTo(points[0][0], points[0][1])
+	}
+
+	// the end points are repeated to give the tangents there
+	k := (1 - tension) / 6
+	n := len(points)
+	for i := 0; i < n-1; i++ {
+		p0 := points[i]
+		if i > 0 {
+			p0 = points[i-1]
+		}
+		p1, p2 := points[i], points[i+1]
+		p3 := p2
+		if i+2 < n {
+			p3 = points[i+2]
+		}
+
+		dc.CubicTo(
+			p1[0]+k*(p2[0]-p0[0]), p1[1]+k*(p2[1]-p0[1]),
+			p2[0]-k*(p3[0]-p1[0]), p2[1]-k*(p3[1]-p1[1]),
+			p2[0], p2[1])
+	}
+}
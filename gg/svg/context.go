@@ -0,0 +1,807 @@
+package svg
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/golang/freetype/truetype"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gomono"
+
+	"github.com/lucasepe/g2d/gg"
+)
+
+// Context implements the graphic context for drawing SVG documents
+type Context struct {
+	doc    *document
+	width  int
+	height int
+
+	// path data in device coordinates
+	path       []byte
+	start      point
+	current    point
+	hasCurrent bool
+
+	fillColor     color.Color
+	fillPattern   gg.Pattern
+	strokeColor   color.Color
+	strokePattern gg.Pattern
+
+	dashes     []float64
+	dashOffset float64
+	lineWidth  float64
+	lineCap    gg.LineCap
+	lineJoin   gg.LineJoin
+	miterLimit float64
+	fillRule   gg.FillRule
+	font       *truetype.Font
+	fontSize   float64
+	matrix     gg.Matrix
+	clipID     string
+	stack      []*Context
+}
+
+// document holds the SVG elements shared by all the saved states
+type document struct {
+	defs bytes.Buffer
+	body bytes.Buffer
+	ids  int
+
+	// the ids of the surface patterns already defined
+	patterns map[patternKey]string
+}
+
+// patternKey is a pattern as painted with a transformation matrix
+type patternKey struct {
+	pattern gg.Pattern
+	matrix  gg.Matrix
+}
+
+func (doc *document) newID(prefix string) string {
+	doc.ids++
+	return fmt.Sprintf("%s%d", prefix, doc.ids)
+}
+
+type point struct {
+	X, Y float64
+}
+
+// NewContext prepares a context for drawing an SVG document
+// with the specified width and height.
+func NewContext(width, height int) *Context {
+	return &Context{
+		doc:           &document{},
+		width:         width,
+		height:        height,
+		fillColor:     color.Transparent,
+		fillPattern:   gg.NewSolidPattern(color.White),
+		strokeColor:   color.Black,
+		strokePattern: gg.NewSolidPattern(color.Black),
+		lineWidth:     1,
+		miterLimit:    10,
+		fillRule:      gg.FillRuleWinding,
+		fontSize:      14,
+		matrix:        gg.Identity(),
+	}
+}
+
+// WriteTo writes the SVG document to w.
+func (dc *Context) WriteTo(w io.Writer) (int64, error) {
+	var buf bytes.Buffer
+	buf.WriteString(`<?xml version="1.0" encoding="UTF-8"?>` + "\n")
+	fmt.Fprintf(&buf, `<svg xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink" `+
+		`version="1.1" width="%d" height="%d" viewBox="0 0 %d %d">`+"\n",
+		dc.width, dc.height, dc.width, dc.height)
+	if dc.doc.defs.Len() > 0 {
+		buf.WriteString("<defs>\n")
+		buf.Write(dc.doc.defs.Bytes())
+		buf.WriteString("</defs>\n")
+	}
+	buf.Write(dc.doc.body.Bytes())
+	buf.WriteString("</svg>\n")
+
+	return buf.WriteTo(w)
+}
+
+// Formats returns the supported document formats.
+func (dc *Context) Formats() []string {
+	return []string{"svg"}
+}
+
+// Encode writes the SVG document to w.
+func (dc *Context) Encode(w io.Writer, format string) error {
+	if format != "svg" {
+		return fmt.Errorf("unsupported document format '%s'", format)
+	}
+	_, err := dc.WriteTo(w)
+	return err
+}
+
+// Width returns the width of the document.
+func (dc *Context) Width() float64 { return float64(dc.width) }
+
+// Height returns the height of the document.
+func (dc *Context) Height() float64 { return float64(dc.height) }
+
+// BeginPath starts a new subpath within the current path. There is no current
+// point after this operation.
+func (dc *Context) BeginPath() {
+	dc.hasCurrent = false
+}
+
+// MoveTo starts a new subpath within the current path starting at the
+// specified point.
+func (dc *Context) MoveTo(x, y float64) {
+	x, y = dc.TransformPoint(x, y)
+	p := point{x, y}
+	dc.path = append(dc.path, 'M')
+	dc.path = appendPoints(dc.path, p)
+	dc.start = p
+	dc.current = p
+	dc.hasCurrent = true
+}
+
+// LineTo adds a line segment to the current path starting at the current
+// point. If there is no current point, it is equivalent to MoveTo(x, y)
+func (dc *Context) LineTo(x, y float64) {
+	if !dc.hasCurrent {
+		dc.MoveTo(x, y)
+		return
+	}
+	x, y = dc.TransformPoint(x, y)
+	p := point{x, y}
+	dc.path = append(dc.path, 'L')
+	dc.path = appendPoints(dc.path, p)
+	dc.current = p
+}
+
+// QuadraticTo adds a quadratic bezier curve to the current path starting at
+// the current point. If there is no current point, it first performs
+// MoveTo(x1, y1)
+func (dc *Context) QuadraticTo(x1, y1, x2, y2 float64) {
+	if !dc.hasCurrent {
+		dc.MoveTo(x1, y1)
+	}
+	x1, y1 = dc.TransformPoint(x1, y1)
+	x2, y2 = dc.TransformPoint(x2, y2)
+	p1 := point{x1, y1}
+	p2 := point{x2, y2}
+	dc.path = append(dc.path, 'Q')
+	dc.path = appendPoints(dc.path, p1, p2)
+	dc.current = p2
+}
+
+// CubicTo adds a cubic bezier curve to the current path starting at the
+// current point. If there is no current point, it first performs
+// MoveTo(x1, y1)
+func (dc *Context) CubicTo(x1, y1, x2, y2, x3, y3 float64) {
+	if !dc.hasCurrent {
+		dc.MoveTo(x1, y1)
+	}
+	x1, y1 = dc.TransformPoint(x1, y1)
+	x2, y2 = dc.TransformPoint(x2, y2)
+	x3, y3 = dc.TransformPoint(x3, y3)
+	p1 := point{x1, y1}
+	p2 := point{x2, y2}
+	p3 := point{x3, y3}
+	dc.path = append(dc.path, 'C')
+	dc.path = appendPoints(dc.path, p1, p2, p3)
+	dc.current = p3
+}
+
+// AppendPath adds the subpaths of the specified path, transformed by the
+// current matrix, to the current path
+func (dc *Context) AppendPath(p *gg.Path) {
+	gg.AppendPath(dc, p)
+}
+
+// CurrentPath returns a copy of the current path in user space
+func (dc *Context) CurrentPath() *gg.Path {
+	res := gg.NewPath()
+
+	var op byte
+	var v []float64
+	for _, el := range strings.Fields(pathCommands.Replace(string(dc.path))) {
+		if n, ok := pathArgs[el[0]]; ok {
+			op, v = el[0], v[:0]
+			if n == 0 {
+				res.ClosePath()
+			}
+			continue
+		}
+
+		val, _ := strconv.ParseFloat(el, 64)
+		if v = append(v, val); len(v) < pathArgs[op] {
+			continue
+		}
+		switch op {
+		case 'M':
+			res.MoveTo(v[0], v[1])
+		case 'L':
+			res.LineTo(v[0], v[1])
+		case 'Q':
+			res.QuadraticTo(v[0], v[1], v[2], v[3])
+		case 'C':
+			res.CubicTo(v[0], v[1], v[2], v[3], v[4], v[5])
+		}
+		v = v[:0]
+	}
+
+	return res.Transform(dc.matrix.Invert())
+}
+
+// pathCommands separates the commands from the coordinates of the path data
+var pathCommands = strings.NewReplacer("M", " M ", "L", " L ", "Q", " Q ", "C", " C ", "Z", " Z ")
+
+// pathArgs is the number of coordinates of each path command
+var pathArgs = map[byte]int{'M': 2, 'L': 2, 'Q': 4, 'C': 6, 'Z': 0}
+
+// ArcTo adds a circular arc to the current sub-path, using
+// the given control points and radius.
+func (dc *Context) ArcTo(x1, y1, x2, y2, radius float64) {
+	gg.ArcTo(dc, x1, y1, x2, y2, radius)
+}
+
+// ClosePath adds a line segment from the current point to the beginning
+// of the current subpath. If there is no current point, this is a no-op.
+func (dc *Context) ClosePath() {
+	if dc.hasCurrent {
+		dc.path = append(dc.path, 'Z')
+		dc.current = dc.start
+	}
+}
+
+// CurrentPoint returns the current point and if there is a current point.
+// The point will have been transformed by the context's transformation matrix.
+func (dc *Context) CurrentPoint() (float64, float64, bool) {
+	if dc.hasCurrent {
+		return dc.current.X, dc.current.Y, true
+	}
+	return 0, 0, false
+}
+
+// ClearPath clears the current path. There is no current point after this
+// operation.
+func (dc *Context) ClearPath() {
+	dc.path = nil
+	dc.hasCurrent = false
+}
+
+// SetStrokeColor sets the current stroke color. r, g, b, a
+// values should be between 0 and 255, inclusive.
+func (dc *Context) SetStrokeColor(r, g, b, a int) {
+	dc.strokeColor = color.NRGBA{uint8(r), uint8(g), uint8(b), uint8(a)}
+	dc.strokePattern = gg.NewSolidPattern(dc.strokeColor)
+}
+
+// SetFillColor sets the current fill color. r, g, b, a values should be between 0 and
+// 255, inclusive.
+func (dc *Context) SetFillColor(r, g, b, a int) {
+	dc.fillColor = color.NRGBA{uint8(r), uint8(g), uint8(b), uint8(a)}
+	dc.fillPattern = gg.NewSolidPattern(dc.fillColor)
+}
+
+// SetFillRule sets the current fill rule
+func (dc *Context) SetFillRule(fillRule gg.FillRule) {
+	dc.fillRule = fillRule
+}
+
+// FillRule returns the current fill rule
+func (dc *Context) FillRule() gg.FillRule { return dc.fillRule }
+
+// SetFillStyle sets current fill style
+func (dc *Context) SetFillStyle(pattern gg.Pattern) {
+	if fillStyle, ok := pattern.(*gg.SolidPattern); ok {
+		dc.fillColor = fillStyle.Color
+	}
+	dc.fillPattern = pattern
+}
+
+// SetStrokeStyle sets current stroke style
+func (dc *Context) SetStrokeStyle(pattern gg.Pattern) {
+	dc.strokePattern = pattern
+}
+
+// SetStrokeWeight sets the lineWidth.
+func (dc *Context) SetStrokeWeight(lineWidth float64) { dc.lineWidth = lineWidth }
+
+// StrokeWeight returns the current line width
+func (dc *Context) StrokeWeight() float64 { return dc.lineWidth }
+
+// SetLineCap sets the current line cap
+func (dc *Context) SetLineCap(lineCap gg.LineCap) { dc.lineCap = lineCap }
+
+// SetLineJoin sets the current line join
+func (dc *Context) SetLineJoin(lineJoin gg.LineJoin) { dc.lineJoin = lineJoin }
+
+// SetMiterLimit sets the current miter limit
+func (dc *Context) SetMiterLimit(limit float64) { dc.miterLimit = limit }
+
+// SetLineDash sets the current dash
+func (dc *Context) SetLineDash(dashes ...float64) {
+	dc.dashes = dashes
+}
+
+// SetLineDashOffset sets the initial offset into the dash pattern
+func (dc *Context) SetLineDashOffset(offset float64) {
+	dc.dashOffset = offset
+}
+
+// SetFontSize sets the current font size
+func (dc *Context) SetFontSize(points float64) {
+	dc.fontSize = points * 72 / 96
+}
+
+// FontSize returns font's size
+func (dc *Context) FontSize() float64 {
+	return dc.fontSize
+}
+
+// SetFont sets the current font
+func (dc *Context) SetFont(font *truetype.Font) {
+	dc.font = font
+}
+
+// Push saves the current state of the context for later retrieval. These
+// can be nested.
+func (dc *Context) Push() {
+	x := *dc
+	dc.stack = append(dc.stack, &x)
+}
+
+// Pop restores the last saved context state from the stack.
+func (dc *Context) Pop() {
+	if dc.stack == nil {
+		return
+	}
+	before := *dc
+	s := dc.stack
+	x, s := s[len(s)-1], s[:len(s)-1]
+	*dc = *x
+	dc.path = before.path
+	dc.start = before.start
+	dc.current = before.current
+	dc.hasCurrent = before.hasCurrent
+}
+
+// Clear discards all the drawings and fills the entire
+// document with the current fill color.
+func (dc *Context) Clear() {
+	dc.doc.body.Reset()
+	fill := dc.paint("fill", gg.NewSolidPattern(dc.fillColor))
+	fmt.Fprintf(&dc.doc.body, `<rect width="%d" height="%d"%s/>`+"\n", dc.width, dc.height, fill)
+}
+
+// Stroke strokes the current path with the current color, line width,
+// line cap, line join and dash settings. The path is cleared after this
+// operation.
+func (dc *Context) Stroke() {
+	if len(dc.path) > 0 {
+		dc.element(fmt.Sprintf(`<path d="%s" fill="none"%s`, dc.path, dc.strokeAttrs()))
+	}
+	dc.ClearPath()
+}
+
+// FillPreserve fills the current path with the current color. Open subpaths
+// are implicity closed. The path is preserved after this operation.
+func (dc *Context) FillPreserve() {
+	if len(dc.path) > 0 {
+		dc.element(fmt.Sprintf(`<path d="%s"%s`, dc.path, dc.fillAttrs()))
+	}
+}
+
+// Fill fills the current path with the current color. Open subpaths
+// are implicity closed. The path is cleared after this operation.
+func (dc *Context) Fill() {
+	dc.FillPreserve()
+	dc.ClearPath()
+}
+
+// FillAndStroke first fills the paths and than strokes them
+func (dc *Context) FillAndStroke() {
+	if len(dc.path) > 0 {
+		dc.element(fmt.Sprintf(`<path d="%s"%s%s`, dc.path, dc.fillAttrs(), dc.strokeAttrs()))
+	}
+	dc.ClearPath()
+}
+
+// ClipPreserve updates the clipping region by intersecting the current
+// clipping region with the current path as it would be filled by dc.Fill().
+// The path is preserved after this operation.
+func (dc *Context) ClipPreserve() {
+	id := dc.doc.newID("clip")
+
+	fmt.Fprintf(&dc.doc.defs, `<clipPath id="%s"`, id)
+	if dc.clipID != "" {
+		// nested clip paths intersect the clipping regions
+		fmt.Fprintf(&dc.doc.defs, ` clip-path="url(#%s)"`, dc.clipID)
+	}
+	fmt.Fprintf(&dc.doc.defs, `><path d="%s"`, dc.path)
+	if dc.fillRule == gg.FillRuleEvenOdd {
+		dc.doc.defs.WriteString(` clip-rule="evenodd"`)
+	}
+	dc.doc.defs.WriteString("/></clipPath>\n")
+
+	dc.clipID = id
+}
+
+// Clip updates the clipping region by intersecting the current
+// clipping region with the current path as it would be filled by dc.Fill().
+// The path is cleared after this operation.
+func (dc *Context) Clip() {
+	dc.ClipPreserve()
+	dc.ClearPath()
+}
+
+// ResetClip clears the clipping region.
+func (dc *Context) ResetClip() {
+	dc.clipID = ""
+}
+
+// Transformation Matrix Operations
+
+// Identity resets the current transformation matrix to the identity matrix.
+// This results in no translating, scaling, rotating, or shearing.
+func (dc *Context) Identity() {
+	dc.matrix = gg.Identity()
+}
+
+// Translate updates the current matrix with a translation.
+func (dc *Context) Translate(x, y float64) {
+	dc.matrix = dc.matrix.Translate(x, y)
+}
+
+// Scale updates the current matrix with a scaling factor.
+// Scaling occurs about the origin.
+func (dc *Context) Scale(x, y float64) {
+	dc.matrix = dc.matrix.Scale(x, y)
+}
+
+// Rotate updates the current matrix with a anticlockwise rotation.
+// Rotation occurs about the origin. Angle is specified in radians.
+func (dc *Context) Rotate(angle float64) {
+	dc.matrix = dc.matrix.Rotate(angle)
+}
+
+// TransformPoint multiplies the specified point by the current matrix,
+// returning a transformed position.
+func (dc *Context) TransformPoint(x, y float64) (tx, ty float64) {
+	return dc.matrix.TransformPoint(x, y)
+}
+
+// Convenient Drawing Functions
+
+// SetPixelColor sets the color of the specified pixel using the current color.
+func (dc *Context) SetPixelColor(c color.Color, x, y int) {
+	dc.element(fmt.Sprintf(`<rect x="%d" y="%d" width="1" height="1"%s`,
+		x, y, dc.paint("fill", gg.NewSolidPattern(c))))
+}
+
+// DrawPoint draws a point
+func (dc *Context) DrawPoint(x, y float64) {
+	r := math.Max(1, dc.lineWidth)
+
+	dc.Push()
+	tx, ty := dc.TransformPoint(x, y)
+	dc.Identity()
+
+	dc.BeginPath()
+	dc.DrawEllipticalArc(tx, ty, r, r, 0, 2*math.Pi)
+	dc.ClosePath()
+
+	dc.Pop()
+}
+
+// DrawLine draws a line
+func (dc *Context) DrawLine(x1, y1, x2, y2 float64) {
+	dc.MoveTo(x1, y1)
+	dc.LineTo(x2, y2)
+}
+
+// DrawEllipticalArc draws an elliptical arc
+func (dc *Context) DrawEllipticalArc(x, y, rx, ry, angle1, angle2 float64) {
+	gg.DrawEllipticalArc(dc, x, y, rx, ry, angle1, angle2)
+}
+
+// DrawImageAnchored draws the specified image at the specified anchor point.
+// The anchor point is x - w * ax, y - h * ay, where w, h is the size of the
+// image. Use ax=0.5, ay=0.5 to center the image at the specified point.
+// The image is embedded in the document as a PNG data URI.
+func (dc *Context) DrawImageAnchored(im image.Image, x, y int, ax, ay float64) {
+	s := im.Bounds().Size()
+	x -= int(ax * float64(s.X))
+	y -= int(ay * float64(s.Y))
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, im); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning svg.Context DrawImageAnchored error: %s", err.Error())
+		return
+	}
+
+	m := dc.matrix.Translate(float64(x), float64(y))
+	dc.element(fmt.Sprintf(`<image width="%d" height="%d" transform="%s" xlink:href="data:image/png;base64,%s"`,
+		s.X, s.Y, matrixAttr(m), base64.StdEncoding.EncodeToString(buf.Bytes())))
+}
+
+// DrawStringAnchored draws the specified text at the specified anchor point.
+// The anchor point is x - w * ax, y - h * ay, where w, h is the size of the
+// text. Use ax=0.5, ay=0.5 to center the text at the specified point.
+// Glyphs are drawn as outlines, so the document does not depend on the
+// fonts installed on the viewer machine.
+func (dc *Context) DrawStringAnchored(s string, x, y, ax, ay float64) {
+	f, err := dc.currentFont()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning svg.Context DrawStringAnchored error: %s", err.Error())
+		return
+	}
+
+	w, h := dc.MeasureString(s)
+	x -= ax * w
+	y += ay * h
+
+	// glyph outlines must not be mixed with the current path
+	path, start, current, hasCurrent := dc.path, dc.start, dc.current, dc.hasCurrent
+	dc.path, dc.hasCurrent = nil, false
+
+	gg.TextOutline(dc, f, dc.fontSize, s, x, y)
+	if len(dc.path) > 0 {
+		dc.element(fmt.Sprintf(`<path d="%s"%s`, dc.path, dc.paint("fill", dc.strokePattern)))
+	}
+
+	dc.path, dc.start, dc.current, dc.hasCurrent = path, start, current, hasCurrent
+}
+
+// MeasureString returns the rendered width and height of the specified text
+// given the current font face.
+func (dc *Context) MeasureString(s string) (w, h float64) {
+	f, err := dc.currentFont()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning svg.Context MeasureString error: %s", err.Error())
+		return 0, 0
+	}
+
+	d := &font.Drawer{Face: truetype.NewFace(f, &truetype.Options{Size: dc.fontSize})}
+	a := d.MeasureString(s)
+	return float64(a >> 6), dc.fontSize
+}
+
+// FontMetrics returns how far the current font face rises above
+// and descends below the baseline.
+func (dc *Context) FontMetrics() (ascent, descent float64) {
+	f, err := dc.currentFont()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning svg.Context FontMetrics error: %s", err.Error())
+		return 0, 0
+	}
+
+	m := truetype.NewFace(f, &truetype.Options{Size: dc.fontSize}).Metrics()
+	return float64(m.Ascent) / 64, float64(m.Descent) / 64
+}
+
+// element adds a drawing element, applying the current clipping region.
+// The open tag must be left unterminated.
+func (dc *Context) element(tag string) {
+	dc.doc.body.WriteString(tag)
+	if dc.clipID != "" {
+		fmt.Fprintf(&dc.doc.body, ` clip-path="url(#%s)"`, dc.clipID)
+	}
+	dc.doc.body.WriteString("/>\n")
+}
+
+func (dc *Context) fillAttrs() string {
+	res := dc.paint("fill", dc.fillPattern)
+	if dc.fillRule == gg.FillRuleEvenOdd {
+		res += ` fill-rule="evenodd"`
+	}
+	return res
+}
+
+func (dc *Context) strokeAttrs() string {
+	var sb strings.Builder
+	sb.WriteString(dc.paint("stroke", dc.strokePattern))
+	fmt.Fprintf(&sb, ` stroke-width="%s"`, num(dc.lineWidth))
+
+	switch dc.lineCap {
+	case gg.LineCapRound:
+		sb.WriteString(` stroke-linecap="round"`)
+	case gg.LineCapSquare:
+		sb.WriteString(` stroke-linecap="square"`)
+	}
+
+	switch dc.lineJoin {
+	case gg.LineJoinRound:
+		sb.WriteString(` stroke-linejoin="round"`)
+	case gg.LineJoinBevel:
+		sb.WriteString(` stroke-linejoin="bevel"`)
+	case gg.LineJoinMiter:
+		fmt.Fprintf(&sb, ` stroke-linejoin="miter" stroke-miterlimit="%s"`, num(dc.miterLimit))
+	}
+
+	if len(dc.dashes) > 0 {
+		dashes := make([]string, len(dc.dashes))
+		for i, el := range dc.dashes {
+			dashes[i] = num(el)
+		}
+		fmt.Fprintf(&sb, ` stroke-dasharray="%s"`, strings.Join(dashes, " "))
+		if dc.dashOffset != 0 {
+			fmt.Fprintf(&sb, ` stroke-dashoffset="%s"`, num(dc.dashOffset))
+		}
+	}
+
+	return sb.String()
+}
+
+// paint returns the attributes to paint using the specified pattern.
+// Linear and radial gradients and surface patterns are added to the
+// definitions, the other patterns are rendered as images the size
+// of the document.
+func (dc *Context) paint(attr string, pattern gg.Pattern) string {
+	switch p := gg.DevicePattern(pattern, dc.matrix).(type) {
+	case *gg.SolidPattern:
+		return colorAttrs(attr, p.Color)
+	case *gg.LinearGradient:
+		id := dc.doc.newID("grad")
+		fmt.Fprintf(&dc.doc.defs, `<linearGradient id="%s" gradientUnits="userSpaceOnUse" `+
+			`x1="%s" y1="%s" x2="%s" y2="%s" gradientTransform="%s">`+"\n",
+			id, num(p.X0), num(p.Y0), num(p.X1), num(p.Y1), matrixAttr(p.Matrix))
+		writeStops(&dc.doc.defs, p.Stops)
+		dc.doc.defs.WriteString("</linearGradient>\n")
+		return fmt.Sprintf(` %s="url(#%s)"`, attr, id)
+	case *gg.RadialGradient:
+		id := dc.doc.newID("grad")
+		fmt.Fprintf(&dc.doc.defs, `<radialGradient id="%s" gradientUnits="userSpaceOnUse" `+
+			`cx="%s" cy="%s" r="%s" fx="%s" fy="%s"`,
+			id, num(p.X1), num(p.Y1), num(p.R1), num(p.X0), num(p.Y0))
+		if p.R0 != 0 {
+			fmt.Fprintf(&dc.doc.defs, ` fr="%s"`, num(p.R0))
+		}
+		fmt.Fprintf(&dc.doc.defs, ` gradientTransform="%s">`+"\n", matrixAttr(p.Matrix))
+		writeStops(&dc.doc.defs, p.Stops)
+		dc.doc.defs.WriteString("</radialGradient>\n")
+		return fmt.Sprintf(` %s="url(#%s)"`, attr, id)
+	case *gg.SurfacePattern:
+		id, err := dc.surfacePattern(patternKey{pattern, dc.matrix}, p)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning svg.Context paint error: %s", err.Error())
+			return fmt.Sprintf(` %s="none"`, attr)
+		}
+		return fmt.Sprintf(` %s="url(#%s)"`, attr, id)
+	default:
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, gg.RenderPattern(p, dc.width, dc.height)); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning svg.Context paint error: %s", err.Error())
+			return fmt.Sprintf(` %s="none"`, attr)
+		}
+
+		id := dc.doc.newID("pattern")
+		fmt.Fprintf(&dc.doc.defs, `<pattern id="%s" patternUnits="userSpaceOnUse" width="%d" height="%d">`+
+			`<image width="%d" height="%d" xlink:href="data:image/png;base64,%s"/></pattern>`+"\n",
+			id, dc.width, dc.height, dc.width, dc.height, base64.StdEncoding.EncodeToString(buf.Bytes()))
+		return fmt.Sprintf(` %s="url(#%s)"`, attr, id)
+	}
+}
+
+// surfacePattern returns the id of the pattern repeating the image, adding
+// it to the definitions the first time it is used. Along the axes where the
+// image is not repeated, the pattern tile covers the whole document, so
+// that the image is painted just once.
+func (dc *Context) surfacePattern(key patternKey, p *gg.SurfacePattern) (string, error) {
+	if id, ok := dc.doc.patterns[key]; ok {
+		return id, nil
+	}
+
+	im := p.Image()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, im); err != nil {
+		return "", err
+	}
+
+	// the document bounds in pattern space
+	inv := p.Matrix.Invert()
+	x0, y0 := math.Inf(1), math.Inf(1)
+	x1, y1 := math.Inf(-1), math.Inf(-1)
+	for _, pt := range []point{{0, 0}, {float64(dc.width), 0}, {0, float64(dc.height)}, {float64(dc.width), float64(dc.height)}} {
+		x, y := inv.TransformPoint(pt.X, pt.Y)
+		x0, y0 = math.Min(x0, x), math.Min(y0, y)
+		x1, y1 = math.Max(x1, x), math.Max(y1, y)
+	}
+
+	s := im.Bounds().Size()
+	tx, tw := tile(s.X, x0, x1, p.Repeat() == gg.RepeatBoth || p.Repeat() == gg.RepeatX)
+	ty, th := tile(s.Y, y0, y1, p.Repeat() == gg.RepeatBoth || p.Repeat() == gg.RepeatY)
+
+	id := dc.doc.newID("pattern")
+	fmt.Fprintf(&dc.doc.defs, `<pattern id="%s" patternUnits="userSpaceOnUse" x="%s" y="%s" width="%s" height="%s" patternTransform="%s">`+
+		`<image x="%s" y="%s" width="%d" height="%d" xlink:href="data:image/png;base64,%s"/></pattern>`+"\n",
+		id, num(tx), num(ty), num(tw), num(th), matrixAttr(p.Matrix),
+		num(-tx), num(-ty), s.X, s.Y, base64.StdEncoding.EncodeToString(buf.Bytes()))
+
+	if dc.doc.patterns == nil {
+		dc.doc.patterns = map[patternKey]string{}
+	}
+	dc.doc.patterns[key] = id
+	return id, nil
+}
+
+// tile returns the position and the size of a pattern tile along an axis:
+// the size of the image if it is repeated, otherwise a tile including
+// both the image and the document bounds lo, hi.
+func tile(size int, lo, hi float64, repeat bool) (float64, float64) {
+	if repeat {
+		return 0, float64(size)
+	}
+	lo, hi = math.Min(0, math.Floor(lo)), math.Max(float64(size), math.Ceil(hi))
+	return lo, hi - lo
+}
+
+// writeStops writes the color stops of a gradient
+func writeStops(buf *bytes.Buffer, stops gg.Stops) {
+	for _, el := range stops {
+		nc := color.NRGBAModel.Convert(el.Color).(color.NRGBA)
+		fmt.Fprintf(buf, `<stop offset="%s" stop-color="#%02x%02x%02x" stop-opacity="%s"/>`+"\n",
+			num(el.Offset), nc.R, nc.G, nc.B, num(float64(nc.A)/255))
+	}
+}
+
+// colorAttrs returns the attributes to paint using the specified color
+func colorAttrs(attr string, c color.Color) string {
+	nc := color.NRGBAModel.Convert(c).(color.NRGBA)
+	if nc.A == 0 {
+		return fmt.Sprintf(` %s="none"`, attr)
+	}
+
+	res := fmt.Sprintf(` %s="#%02x%02x%02x"`, attr, nc.R, nc.G, nc.B)
+	if nc.A < 255 {
+		res += fmt.Sprintf(` %s-opacity="%s"`, attr, num(float64(nc.A)/255))
+	}
+	return res
+}
+
+func (dc *Context) currentFont() (*truetype.Font, error) {
+	if dc.font == nil {
+		val, err := truetype.Parse(gomono.TTF)
+		if err != nil {
+			return nil, err
+		}
+		dc.font = val
+	}
+
+	return dc.font, nil
+}
+
+func appendPoints(buf []byte, points ...point) []byte {
+	for i, p := range points {
+		if i > 0 {
+			buf = append(buf, ' ')
+		}
+		buf = append(buf, num(p.X)...)
+		buf = append(buf, ' ')
+		buf = append(buf, num(p.Y)...)
+	}
+	return buf
+}
+
+func matrixAttr(m gg.Matrix) string {
+	return fmt.Sprintf("matrix(%s %s %s %s %s %s)",
+		num(m.XX), num(m.YX), num(m.XY), num(m.YY), num(m.X0), num(m.Y0))
+}
+
+// num formats a number using at most three decimal digits
+func num(v float64) string {
+	v = math.Round(v*1000) / 1000
+	if v == 0 {
+		// avoid "-0"
+		v = 0
+	}
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
@@ -0,0 +1,400 @@
+package svg
+
+import (
+	"bytes"
+	"encoding/xml"
+	"image"
+	"image/color"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/lucasepe/g2d/gg"
+)
+
+// node is an element of the SVG document
+type node struct {
+	XMLName xml.Name
+	Attrs   []xml.Attr `xml:",any,attr"`
+	Nodes   []node     `xml:",any"`
+}
+
+func (n node) attr(name string) string {
+	for _, el := range n.Attrs {
+		if el.Name.Local == name {
+			return el.Value
+		}
+	}
+	return ""
+}
+
+// find returns all the descendants with the specified tag name
+func (n node) find(name string) []node {
+	var res []node
+	for _, el := range n.Nodes {
+		if el.XMLName.Local == name {
+			res = append(res, el)
+		}
+		res = append(res, el.find(name)...)
+	}
+	return res
+}
+
+// body returns the drawing elements, the definitions excluded
+func (n node) body() []node {
+	var res []node
+	for _, el := range n.Nodes {
+		if el.XMLName.Local != "defs" {
+			res = append(res, el)
+		}
+	}
+	return res
+}
+
+func rect(dc *Context, x, y, w, h float64) {
+	dc.MoveTo(x, y)
+	dc.LineTo(x+w, y)
+	dc.LineTo(x+w, y+h)
+	dc.LineTo(x, y+h)
+	dc.ClosePath()
+}
+
+func parse(t *testing.T, dc *Context) node {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if err := dc.Encode(&buf, "svg"); err != nil {
+		t.Fatal(err)
+	}
+
+	var res node
+	if err := xml.Unmarshal(buf.Bytes(), &res); err != nil {
+		t.Fatalf("invalid document: %s\n%s", err, buf.String())
+	}
+	return res
+}
+
+func TestDocument(t *testing.T) {
+	doc := parse(t, NewContext(120, 80))
+
+	if doc.XMLName.Local != "svg" {
+		t.Fatalf("wrong root element. expected=svg, got=%s", doc.XMLName.Local)
+	}
+	for name, expected := range map[string]string{"width": "120", "height": "80", "viewBox": "0 0 120 80"} {
+		if got := doc.attr(name); got != expected {
+			t.Errorf("wrong %s. expected=%q, got=%q", name, expected, got)
+		}
+	}
+	if len(doc.find("defs")) != 0 {
+		t.Errorf("unexpected definitions in an empty document")
+	}
+}
+
+func TestPathData(t *testing.T) {
+	tests := []struct {
+		draw     func(dc *Context)
+		expected string
+	}{
+		{
+			func(dc *Context) {
+				dc.MoveTo(10, 20)
+				dc.LineTo(30, 40)
+				dc.QuadraticTo(50, 0, 60, 10)
+				dc.CubicTo(1, 2, 3, 4, 5.5, 6.25)
+				dc.ClosePath()
+			},
+			"M10 20L30 40Q50 0 60 10C1 2 3 4 5.5 6.25Z",
+		},
+		{
+			func(dc *Context) {
+				dc.LineTo(1, 1)
+				dc.LineTo(2, 1)
+			},
+			"M1 1L2 1",
+		},
+		{
+			func(dc *Context) {
+				dc.Translate(10, 5)
+				dc.Scale(2, 2)
+				dc.MoveTo(1, 1)
+				dc.LineTo(2, 1.0004)
+			},
+			"M12 7L14 7.001",
+		},
+		{
+			func(dc *Context) {
+				dc.Translate(50, 50)
+				dc.Rotate(1.5707963267948966)
+				dc.MoveTo(10, 0)
+			},
+			"M50 60",
+		},
+	}
+
+	for _, tt := range tests {
+		dc := NewContext(100, 100)
+		tt.draw(dc)
+		dc.Stroke()
+
+		paths := parse(t, dc).find("path")
+		if len(paths) != 1 {
+			t.Fatalf("wrong number of paths. expected=1, got=%d", len(paths))
+		}
+		if got := paths[0].attr("d"); got != tt.expected {
+			t.Errorf("wrong path data. expected=%q, got=%q", tt.expected, got)
+		}
+		if got := paths[0].attr("fill"); got != "none" {
+			t.Errorf("stroked path is filled with %q", got)
+		}
+	}
+}
+
+func TestPaint(t *testing.T) {
+	dc := NewContext(100, 100)
+	dc.SetStrokeColor(255, 0, 0, 128)
+	dc.SetStrokeWeight(3)
+	dc.SetLineJoin(gg.LineJoinMiter)
+	dc.SetMiterLimit(4)
+	dc.SetLineDash(4, 2)
+	dc.DrawLine(0, 0, 10, 10)
+	dc.Stroke()
+
+	dc.SetFillColor(0, 0, 255, 255)
+	dc.SetFillRule(gg.FillRuleEvenOdd)
+	dc.DrawLine(0, 0, 10, 10)
+	dc.Fill()
+
+	paths := parse(t, dc).find("path")
+	if len(paths) != 2 {
+		t.Fatalf("wrong number of paths. expected=2, got=%d", len(paths))
+	}
+
+	expected := [][2]string{
+		{"stroke", "#ff0000"},
+		{"stroke-opacity", "0.502"},
+		{"stroke-width", "3"},
+		{"stroke-linejoin", "miter"},
+		{"stroke-miterlimit", "4"},
+		{"stroke-dasharray", "4 2"},
+	}
+	for _, el := range expected {
+		if got := paths[0].attr(el[0]); got != el[1] {
+			t.Errorf("wrong %s. expected=%q, got=%q", el[0], el[1], got)
+		}
+	}
+
+	if got := paths[1].attr("fill"); got != "#0000ff" {
+		t.Errorf("wrong fill. expected=%q, got=%q", "#0000ff", got)
+	}
+	if got := paths[1].attr("fill-rule"); got != "evenodd" {
+		t.Errorf("wrong fill-rule. expected=%q, got=%q", "evenodd", got)
+	}
+}
+
+func TestClip(t *testing.T) {
+	dc := NewContext(100, 100)
+
+	rect(dc, 10, 10, 50, 50)
+	dc.Clip()
+	rect(dc, 0, 0, 100, 100)
+	dc.Fill()
+
+	dc.Push()
+	dc.DrawEllipticalArc(30, 30, 10, 10, 0, 2*math.Pi)
+	dc.Clip()
+	rect(dc, 0, 0, 100, 100)
+	dc.Fill()
+	dc.Pop()
+
+	rect(dc, 0, 0, 100, 100)
+	dc.Fill()
+
+	dc.ResetClip()
+	rect(dc, 0, 0, 100, 100)
+	dc.Fill()
+
+	doc := parse(t, dc)
+
+	clips := doc.find("clipPath")
+	if len(clips) != 2 {
+		t.Fatalf("wrong number of clip paths. expected=2, got=%d", len(clips))
+	}
+	first, second := clips[0].attr("id"), clips[1].attr("id")
+	if got := clips[1].attr("clip-path"); got != "url(#"+first+")" {
+		t.Errorf("nested clip path does not intersect the outer one. got=%q", got)
+	}
+	if got := clips[0].find("path")[0].attr("d"); got != "M10 10L60 10L60 60L10 60Z" {
+		t.Errorf("wrong clip path data. got=%q", got)
+	}
+
+	expected := []string{"url(#" + first + ")", "url(#" + second + ")", "url(#" + first + ")", ""}
+	body := doc.body()
+	if len(body) != len(expected) {
+		t.Fatalf("wrong number of elements. expected=%d, got=%d", len(expected), len(body))
+	}
+	for i, el := range body {
+		if got := el.attr("clip-path"); got != expected[i] {
+			t.Errorf("element #%d has the wrong clip-path. expected=%q, got=%q", i, expected[i], got)
+		}
+	}
+}
+
+func TestGradients(t *testing.T) {
+	dc := NewContext(100, 100)
+
+	linear := gg.NewLinearGradient(0, 0, 100, 0)
+	linear.AddColorStop(0, color.Black)
+	linear.AddColorStop(1, color.NRGBA{255, 0, 0, 128})
+	dc.Translate(10, 0)
+	dc.SetFillStyle(linear)
+	rect(dc, 0, 0, 10, 10)
+	dc.Fill()
+
+	radial := gg.NewRadialGradient(50, 50, 0, 50, 50, 40)
+	radial.AddColorStop(0, color.White)
+	radial.AddColorStop(1, color.Black)
+	dc.SetStrokeStyle(radial)
+	dc.DrawLine(0, 0, 10, 10)
+	dc.Stroke()
+
+	doc := parse(t, dc)
+
+	grads := doc.find("linearGradient")
+	if len(grads) != 1 {
+		t.Fatalf("wrong number of linear gradients. expected=1, got=%d", len(grads))
+	}
+	g := grads[0]
+	for name, expected := range map[string]string{
+		"gradientUnits":     "userSpaceOnUse",
+		"x1":                "0",
+		"x2":                "100",
+		"gradientTransform": "matrix(1 0 0 1 10 0)",
+	} {
+		if got := g.attr(name); got != expected {
+			t.Errorf("wrong linear gradient %s. expected=%q, got=%q", name, expected, got)
+		}
+	}
+
+	stops := g.find("stop")
+	if len(stops) != 2 {
+		t.Fatalf("wrong number of stops. expected=2, got=%d", len(stops))
+	}
+	if got := stops[1].attr("stop-color"); got != "#ff0000" {
+		t.Errorf("wrong stop color. expected=%q, got=%q", "#ff0000", got)
+	}
+	if got := stops[1].attr("stop-opacity"); got != "0.502" {
+		t.Errorf("wrong stop opacity. expected=%q, got=%q", "0.502", got)
+	}
+
+	radials := doc.find("radialGradient")
+	if len(radials) != 1 {
+		t.Fatalf("wrong number of radial gradients. expected=1, got=%d", len(radials))
+	}
+	if got := radials[0].attr("r"); got != "40" {
+		t.Errorf("wrong radius. expected=%q, got=%q", "40", got)
+	}
+
+	paths := doc.find("path")
+	if got := paths[0].attr("fill"); got != "url(#"+g.attr("id")+")" {
+		t.Errorf("path not filled with the linear gradient. got=%q", got)
+	}
+	if got := paths[1].attr("stroke"); got != "url(#"+radials[0].attr("id")+")" {
+		t.Errorf("path not stroked with the radial gradient. got=%q", got)
+	}
+}
+
+func TestSurfacePattern(t *testing.T) {
+	im := image.NewNRGBA(image.Rect(0, 0, 8, 4))
+
+	tests := []struct {
+		op       gg.RepeatOp
+		expected [4]string
+		position string
+	}{
+		{gg.RepeatBoth, [4]string{"0", "0", "8", "4"}, "0 0"},
+		{gg.RepeatX, [4]string{"0", "-5", "8", "100"}, "0 5"},
+		{gg.RepeatNone, [4]string{"-10", "-5", "100", "100"}, "10 5"},
+	}
+
+	for _, tt := range tests {
+		dc := NewContext(100, 100)
+		dc.SetFillStyle(gg.NewSurfacePattern(im, tt.op))
+		dc.Translate(10, 5)
+		for i := 0; i < 3; i++ {
+			rect(dc, float64(i*10), 0, 5, 5)
+			dc.Fill()
+		}
+
+		doc := parse(t, dc)
+		patterns := doc.find("pattern")
+		if len(patterns) != 1 {
+			t.Fatalf("the pattern is not shared by the fills. expected=1, got=%d", len(patterns))
+		}
+
+		p := patterns[0]
+		for i, name := range []string{"x", "y", "width", "height"} {
+			if got := p.attr(name); got != tt.expected[i] {
+				t.Errorf("wrong pattern %s. expected=%q, got=%q", name, tt.expected[i], got)
+			}
+		}
+		if got := p.attr("patternTransform"); got != "matrix(1 0 0 1 10 5)" {
+			t.Errorf("wrong pattern transform. got=%q", got)
+		}
+
+		images := p.find("image")
+		if len(images) != 1 || images[0].attr("width") != "8" || images[0].attr("height") != "4" {
+			t.Fatalf("the pattern does not hold the image tile. got=%+v", images)
+		}
+		if got := images[0].attr("x") + " " + images[0].attr("y"); got != tt.position {
+			t.Errorf("wrong image position. got=%q", got)
+		}
+
+		for _, el := range doc.find("path") {
+			if got := el.attr("fill"); got != "url(#"+p.attr("id")+")" {
+				t.Errorf("path not filled with the pattern. got=%q", got)
+			}
+		}
+	}
+}
+
+func TestText(t *testing.T) {
+	dc := NewContext(200, 100)
+	dc.SetStrokeColor(0, 128, 0, 255)
+	dc.MoveTo(1, 2)
+	dc.LineTo(3, 4)
+	dc.DrawStringAnchored("Hi", 100, 50, 0.5, 0.5)
+
+	doc := parse(t, dc)
+	if len(doc.find("text")) != 0 {
+		t.Errorf("text must be drawn as outlines")
+	}
+
+	paths := doc.find("path")
+	if len(paths) != 1 {
+		t.Fatalf("wrong number of paths. expected=1, got=%d", len(paths))
+	}
+	if got := paths[0].attr("fill"); got != "#008000" {
+		t.Errorf("text not painted with the stroke color. got=%q", got)
+	}
+	if d := paths[0].attr("d"); strings.Count(d, "M") < 2 {
+		t.Errorf("wrong glyph outlines. got=%q", d)
+	}
+
+	w, _ := dc.MeasureString("Hi")
+	x0, _, x1, _ := glyphBounds(paths[0].attr("d"))
+	if x0 < 100-w/2-1 || x1 > 100+w/2+1 {
+		t.Errorf("text not centered. got=[%g, %g], width=%g", x0, x1, w)
+	}
+
+	// the current path is preserved
+	if got := string(dc.path); got != "M1 2L3 4" {
+		t.Errorf("current path changed by the text. got=%q", got)
+	}
+}
+
+// glyphBounds returns the bounding box of the path data
+func glyphBounds(d string) (x0, y0, x1, y1 float64) {
+	dc := NewContext(1, 1)
+	dc.path = []byte(d)
+	return dc.CurrentPath().Bounds()
+}
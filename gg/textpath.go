@@ -0,0 +1,41 @@
+package gg
+
+// DrawStringOnPath draws the specified text along the path, with the
+// baseline of each glyph on the path and rotated to follow its direction.
+// The text is aligned on the path (left from the start, center, right to
+// the end) then moved forward by offset. The glyphs falling outside
+// the path are not drawn.
+// It is shared by all the backends.
+func DrawStringOnPath(dc GraphicContext, p *Path, s string, offset float64, align Align) {
+	l := p.Length()
+	if l == 0 || s == "" {
+		return
+	}
+
+	w, _ := dc.MeasureString(s)
+	switch align {
+	case AlignCenter:
+		offset += (l - w) / 2
+	case AlignRight:
+		offset += l - w
+	}
+
+	// the position of each glyph is the width of the text before it,
+	// so that the kerning is taken into account
+	start := 0.0
+	for i, r := range s {
+		end, _ := dc.MeasureString(s[:i+len(string(r))])
+		mid := offset + (start+end)/2
+		start = end
+		if mid < 0 || mid > l {
+			continue
+		}
+
+		pt, angle, _ := p.PointAt(mid)
+		dc.Push()
+		dc.Translate(pt.X, pt.Y)
+		dc.Rotate(angle)
+		dc.DrawStringAnchored(string(r), 0, 0, 0.5, 0)
+		dc.Pop()
+	}
+}
@@ -56,3 +56,31 @@ func splitOnSpace(x string) []string {
 	result = append(result, x[pi:])
 	return result
 }
+
+// DrawStringWrapped word wraps the specified text to the given width and
+// draws it in a box whose top left corner is x, y. Each line is aligned
+// inside the box, lineSpacing is the distance between the baselines as a
+// multiple of the font height. It returns the height of the box.
+// It is shared by all the backends.
+func DrawStringWrapped(dc GraphicContext, s string, x, y, width, lineSpacing float64, align Align) float64 {
+	lines := WordWrap(dc, s, width)
+	if len(lines) == 0 {
+		return 0
+	}
+
+	ax := 0.0
+	switch align {
+	case AlignCenter:
+		ax, x = 0.5, x+width/2
+	case AlignRight:
+		ax, x = 1, x+width
+	}
+
+	ascent, descent := dc.FontMetrics()
+	step := dc.FontSize() * lineSpacing
+	for i, line := range lines {
+		dc.DrawStringAnchored(line, x, y+ascent+float64(i)*step, ax, 0)
+	}
+
+	return float64(len(lines)-1)*step + ascent + descent
+}
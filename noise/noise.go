@@ -0,0 +1,241 @@
+// Package noise implements coherent noise functions (Perlin, simplex and
+// Worley) summed over several octaves, all of them defined in 3D: lower
+// dimensions are sampled at y = 0 and z = 0.
+package noise
+
+import (
+	"math"
+	"math/rand"
+)
+
+const (
+	// DefaultOctaves is the default number of octaves
+	DefaultOctaves = 4
+	// DefaultFalloff is the default amplitude falloff of each octave
+	DefaultFalloff = 0.5
+	// MaxOctaves is the maximum number of octaves, the frequency of
+	// the next ones exceeds the resolution of any drawing
+	MaxOctaves = 16
+)
+
+// Noise generates coherent noise from a seeded permutation table,
+// the same seed always generates the same values
+type Noise struct {
+	perm    [512]int
+	octaves int
+	falloff float64
+}
+
+// New returns a noise generator seeded with the specified value
+func New(seed int64) *Noise {
+	n := &Noise{octaves: DefaultOctaves, falloff: DefaultFalloff}
+	n.Reseed(seed)
+	return n
+}
+
+// Reseed rebuilds the permutation table from the seed, keeping the detail
+func (n *Noise) Reseed(seed int64) {
+	p := rand.New(rand.NewSource(seed)).Perm(256)
+	for i := 0; i < 512; i++ {
+		n.perm[i] = p[i&255]
+	}
+}
+
+// SetDetail sets the number of octaves and the falloff: each octave has
+// double the frequency and falloff times the amplitude of the previous one
+func (n *Noise) SetDetail(octaves int, falloff float64) {
+	n.octaves = octaves
+	n.falloff = falloff
+}
+
+// Perlin returns the Perlin noise at the specified point, in range [0, 1]
+func (n *Noise) Perlin(x, y, z float64) float64 {
+	return n.fbm(x, y, z, func(x, y, z float64) float64 {
+		return (n.perlin(x, y, z) + 1) / 2
+	})
+}
+
+// Simplex returns the simplex noise at the specified point, in range [0, 1]
+func (n *Noise) Simplex(x, y, z float64) float64 {
+	return n.fbm(x, y, z, func(x, y, z float64) float64 {
+		return (n.simplex(x, y, z) + 1) / 2
+	})
+}
+
+// Worley returns the cellular noise at the specified point: the distance
+// to the nearest of the points scattered one per unit cell, in range [0, 1]
+func (n *Noise) Worley(x, y, z float64) float64 {
+	return n.fbm(x, y, z, n.worley)
+}
+
+// octaveOffset moves the samples of each octave by a multiple of it: the
+// octaves are not aligned and never sample the lattice points, where the
+// Perlin noise is zero, at integer inputs; neither the plane y = z = 0
+// used by the lower dimensions, where some gradients add nothing.
+var octaveOffset = [3]float64{0.3127, 0.7193, 0.5419}
+
+// fbm sums the octaves of the noise function, normalized to its range
+func (n *Noise) fbm(x, y, z float64, fn func(x, y, z float64) float64) float64 {
+	sum, total := 0.0, 0.0
+	amp, freq := 1.0, 1.0
+	for i := 0; i < n.octaves; i++ {
+		k := float64(i + 1)
+		sum = sum + amp*fn(x*freq+k*octaveOffset[0], y*freq+k*octaveOffset[1], z*freq+k*octaveOffset[2])
+		total = total + amp
+		amp = amp * n.falloff
+		freq = freq * 2
+	}
+
+	if total == 0 {
+		return 0
+	}
+
+	return clamp(sum / total)
+}
+
+// perlin is the improved Perlin noise, in range [-1, 1]
+func (n *Noise) perlin(x, y, z float64) float64 {
+	fx, fy, fz := math.Floor(x), math.Floor(y), math.Floor(z)
+	X, Y, Z := int(fx)&255, int(fy)&255, int(fz)&255
+	x, y, z = x-fx, y-fy, z-fz
+
+	u, v, w := fade(x), fade(y), fade(z)
+
+	p := &n.perm
+	A := p[X] + Y
+	AA, AB := p[A]+Z, p[A+1]+Z
+	B := p[X+1] + Y
+	BA, BB := p[B]+Z, p[B+1]+Z
+
+	return lerp(w,
+		lerp(v,
+			lerp(u, grad(p[AA], x, y, z), grad(p[BA], x-1, y, z)),
+			lerp(u, grad(p[AB], x, y-1, z), grad(p[BB], x-1, y-1, z))),
+		lerp(v,
+			lerp(u, grad(p[AA+1], x, y, z-1), grad(p[BA+1], x-1, y, z-1)),
+			lerp(u, grad(p[AB+1], x, y-1, z-1), grad(p[BB+1], x-1, y-1, z-1))))
+}
+
+func fade(t float64) float64 { return t * t * t * (t*(t*6-15) + 10) }
+
+func lerp(t, a, b float64) float64 { return a + t*(b-a) }
+
+func grad(hash int, x, y, z float64) float64 {
+	h := hash & 15
+
+	u := y
+	if h < 8 {
+		u = x
+	}
+
+	v := z
+	if h < 4 {
+		v = y
+	} else if h == 12 || h == 14 {
+		v = x
+	}
+
+	if h&1 != 0 {
+		u = -u
+	}
+	if h&2 != 0 {
+		v = -v
+	}
+	return u + v
+}
+
+var grad3 = [12][3]float64{
+	{1, 1, 0}, {-1, 1, 0}, {1, -1, 0}, {-1, -1, 0},
+	{1, 0, 1}, {-1, 0, 1}, {1, 0, -1}, {-1, 0, -1},
+	{0, 1, 1}, {0, -1, 1}, {0, 1, -1}, {0, -1, -1},
+}
+
+// simplex is the 3D simplex noise, in range [-1, 1]
+func (n *Noise) simplex(x, y, z float64) float64 {
+	const (
+		f3 = 1.0 / 3.0
+		g3 = 1.0 / 6.0
+	)
+
+	// skew the input space to find the simplex cell
+	s := (x + y + z) * f3
+	i, j, k := math.Floor(x+s), math.Floor(y+s), math.Floor(z+s)
+
+	t := (i + j + k) * g3
+	x0, y0, z0 := x-(i-t), y-(j-t), z-(k-t)
+
+	// the second and third corners of the simplex
+	var i1, j1, k1, i2, j2, k2 float64
+	if x0 >= y0 {
+		if y0 >= z0 {
+			i1, j1, k1, i2, j2, k2 = 1, 0, 0, 1, 1, 0
+		} else if x0 >= z0 {
+			i1, j1, k1, i2, j2, k2 = 1, 0, 0, 1, 0, 1
+		} else {
+			i1, j1, k1, i2, j2, k2 = 0, 0, 1, 1, 0, 1
+		}
+	} else {
+		if y0 < z0 {
+			i1, j1, k1, i2, j2, k2 = 0, 0, 1, 0, 1, 1
+		} else if x0 < z0 {
+			i1, j1, k1, i2, j2, k2 = 0, 1, 0, 0, 1, 1
+		} else {
+			i1, j1, k1, i2, j2, k2 = 0, 1, 0, 1, 1, 0
+		}
+	}
+
+	x1, y1, z1 := x0-i1+g3, y0-j1+g3, z0-k1+g3
+	x2, y2, z2 := x0-i2+2*g3, y0-j2+2*g3, z0-k2+2*g3
+	x3, y3, z3 := x0-1+3*g3, y0-1+3*g3, z0-1+3*g3
+
+	p := &n.perm
+	ii, jj, kk := int(i)&255, int(j)&255, int(k)&255
+	gi0 := p[ii+p[jj+p[kk]]] % 12
+	gi1 := p[ii+int(i1)+p[jj+int(j1)+p[kk+int(k1)]]] % 12
+	gi2 := p[ii+int(i2)+p[jj+int(j2)+p[kk+int(k2)]]] % 12
+	gi3 := p[ii+1+p[jj+1+p[kk+1]]] % 12
+
+	corner := func(g int, x, y, z float64) float64 {
+		t := 0.6 - x*x - y*y - z*z
+		if t < 0 {
+			return 0
+		}
+		t = t * t
+		return t * t * (grad3[g][0]*x + grad3[g][1]*y + grad3[g][2]*z)
+	}
+
+	return 32 * (corner(gi0, x0, y0, z0) + corner(gi1, x1, y1, z1) +
+		corner(gi2, x2, y2, z2) + corner(gi3, x3, y3, z3))
+}
+
+// worley returns the distance to the nearest feature point, in range [0, 1]
+func (n *Noise) worley(x, y, z float64) float64 {
+	fx, fy, fz := math.Floor(x), math.Floor(y), math.Floor(z)
+
+	p := &n.perm
+	best := math.MaxFloat64
+	for dx := -1; dx <= 1; dx++ {
+		for dy := -1; dy <= 1; dy++ {
+			for dz := -1; dz <= 1; dz++ {
+				cx, cy, cz := int(fx)+dx, int(fy)+dy, int(fz)+dz
+
+				// the feature point of the cell
+				h := p[p[p[cx&255]+cy&255]+cz&255]
+				px := float64(cx) + float64(p[h])/255
+				py := float64(cy) + float64(p[(h+85)&255])/255
+				pz := float64(cz) + float64(p[(h+170)&255])/255
+
+				d := (px-x)*(px-x) + (py-y)*(py-y) + (pz-z)*(pz-z)
+				if d < best {
+					best = d
+				}
+			}
+		}
+	}
+
+	return clamp(math.Sqrt(best))
+}
+
+func clamp(v float64) float64 {
+	return math.Max(0, math.Min(1, v))
+}
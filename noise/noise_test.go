@@ -0,0 +1,86 @@
+package noise
+
+import (
+	"math"
+	"testing"
+)
+
+func TestRange(t *testing.T) {
+	n := New(42)
+
+	fns := map[string]func(x, y, z float64) float64{
+		"perlin":  n.Perlin,
+		"simplex": n.Simplex,
+		"worley":  n.Worley,
+	}
+
+	for name, fn := range fns {
+		min, max := 1.0, 0.0
+		for i := 0; i < 1000; i++ {
+			v := fn(float64(i)*0.37-100, float64(i)*0.11, float64(i)*0.05)
+			if v < 0 || v > 1 {
+				t.Fatalf("%s: got [%f] out of range [0, 1]", name, v)
+			}
+			min, max = math.Min(min, v), math.Max(max, v)
+		}
+		if max-min < 0.1 {
+			t.Errorf("%s: values in [%f, %f] are not spread enough", name, min, max)
+		}
+	}
+}
+
+func TestSeed(t *testing.T) {
+	a, b, c := New(1), New(1), New(2)
+
+	same := true
+	for i := 0; i < 50; i++ {
+		x, y := float64(i)*0.3, float64(i)*0.7
+		if a.Perlin(x, y, 0) != b.Perlin(x, y, 0) {
+			t.Fatalf("same seed, different values at (%f, %f)", x, y)
+		}
+		if a.Perlin(x, y, 0) != c.Perlin(x, y, 0) {
+			same = false
+		}
+	}
+	if same {
+		t.Errorf("different seeds, same values")
+	}
+
+	c.Reseed(1)
+	if got, want := c.Simplex(0.5, 1.5, 2.5), a.Simplex(0.5, 1.5, 2.5); got != want {
+		t.Errorf("got [%f] want [%f]", got, want)
+	}
+}
+
+func TestSmooth(t *testing.T) {
+	n := New(7)
+	n.SetDetail(1, DefaultFalloff)
+
+	for i := 0; i < 100; i++ {
+		x := float64(i) * 0.1
+		if d := math.Abs(n.Perlin(x, 0, 0) - n.Perlin(x+0.001, 0, 0)); d > 0.01 {
+			t.Fatalf("perlin: step [%f] too large at x=%f", d, x)
+		}
+	}
+}
+
+func TestIntegerSamples(t *testing.T) {
+	n := New(42)
+
+	for _, octaves := range []int{1, DefaultOctaves} {
+		n.SetDetail(octaves, DefaultFalloff)
+
+		// noise(i) must not draw a flat line
+		seen := map[float64]bool{}
+		for i := 0; i < 10; i++ {
+			v := n.Perlin(float64(i), 0, 0)
+			if v == 0.5 {
+				t.Errorf("octaves %d: perlin is 0.5 at x=%d", octaves, i)
+			}
+			seen[v] = true
+		}
+		if len(seen) < 10 {
+			t.Errorf("octaves %d: only %d different values at the integer samples", octaves, len(seen))
+		}
+	}
+}
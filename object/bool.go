@@ -36,6 +36,11 @@ func (b *Boolean) Clone() Object {
 	return &Boolean{Value: b.Value}
 }
 
+// HashKey returns a HashKey object (complies with Hashable interface)
+func (b *Boolean) HashKey() HashKey {
+	return HashKey{Type: b.Type(), Value: uint64(b.Int())}
+}
+
 // Type returns the type of the object
 func (b *Boolean) Type() Type { return BOOLEAN }
 
@@ -0,0 +1,124 @@
+package object
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/lucasepe/g2d/ast"
+	"github.com/lucasepe/g2d/code"
+	"github.com/lucasepe/g2d/token"
+)
+
+// Slot locates a local variable: the number of scopes to walk up
+// from the current one and the index of the value in that scope
+type Slot struct {
+	Depth int
+	Index int
+}
+
+// Variable is a name that can be bound by the current function, or by
+// any of the enclosing ones, in the candidate slots (innermost first).
+// If no slot holds a value the name is looked up in the environment.
+type Variable struct {
+	Name  string
+	Slots []Slot
+}
+
+// CompiledFunction holds the bytecode of a function (or of a program)
+// together with the constants, names and variables it refers to
+type CompiledFunction struct {
+	Instructions code.Instructions
+	NumLocals    int
+	Parameters   []*ast.Identifier
+	Body         *ast.BlockStatement
+
+	// ParameterSlots holds the local slots of the
+	// parameters, -1 for the reserved names
+	ParameterSlots []int
+	Constants      []Object
+	Names          []string
+	Vars           []Variable
+	Imports        []*ast.ImportStatement
+
+	// Tokens maps the offset of the instructions that
+	// can fail to the token used for the error location
+	Tokens map[int]token.Token
+}
+
+// Bool implements the Object Bool method
+func (cf *CompiledFunction) Bool() bool { return false }
+
+// Type returns the type of the object
+func (cf *CompiledFunction) Type() Type { return FUNCTION }
+
+// Inspect returns a stringified version of the object for debugging
+func (cf *CompiledFunction) Inspect() string {
+	return inspectFunction(cf.Parameters, cf.Body)
+}
+
+// ToInterface converts this object to a go-interface, which will allow
+// it to be used naturally in our sprintf/printf primitives.
+//
+// It might also be helpful for embedded users.
+func (cf *CompiledFunction) ToInterface() interface{} {
+	return "<FUNCTION>"
+}
+
+func (cf *CompiledFunction) String() string {
+	return signature(cf.Parameters)
+}
+
+// Scope holds the values of the local variables of a function call
+type Scope struct {
+	Values []Object
+	Parent *Scope
+}
+
+// Closure is a compiled function bound to the scopes of the enclosing
+// function calls and to the environment where it was defined.
+type Closure struct {
+	Fn    *CompiledFunction
+	Scope *Scope
+	Env   *Environment
+}
+
+// Bool implements the Object Bool method
+func (c *Closure) Bool() bool { return false }
+
+// Type returns the type of the object
+func (c *Closure) Type() Type { return FUNCTION }
+
+// Inspect returns a stringified version of the object for debugging
+func (c *Closure) Inspect() string { return c.Fn.Inspect() }
+
+// ToInterface converts this object to a go-interface, which will allow
+// it to be used naturally in our sprintf/printf primitives.
+//
+// It might also be helpful for embedded users.
+func (c *Closure) ToInterface() interface{} {
+	return "<FUNCTION>"
+}
+
+func (c *Closure) String() string { return c.Fn.String() }
+
+// inspectFunction returns the source of a function
+func inspectFunction(parameters []*ast.Identifier, body *ast.BlockStatement) string {
+	var out bytes.Buffer
+
+	out.WriteString(signature(parameters))
+	out.WriteString(" {\n")
+	out.WriteString(body.String())
+	out.WriteString("\n}")
+
+	return out.String()
+}
+
+// signature returns the signature of a function: fn(a, b)
+func signature(parameters []*ast.Identifier) string {
+	params := []string{}
+	for _, p := range parameters {
+		params = append(params, p.String())
+	}
+
+	return "fn(" + strings.Join(params, ", ") + ")"
+}
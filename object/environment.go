@@ -3,18 +3,19 @@ package object
 import (
 	"fmt"
 	"math"
+	"math/rand"
 	"strings"
+	"time"
 
 	"github.com/lucasepe/g2d/gg"
-)
-
-var (
-	snapshotCounter = 0
+	"github.com/lucasepe/g2d/gg/anim"
+	"github.com/lucasepe/g2d/noise"
 )
 
 const (
 	keySnapshotFolder = "__SNAPSHOT_FOLDER__"
 	keySnapshotPrefix = "__SNAPSHOT_PREFIX__"
+	keyScriptPath     = "__SCRIPT_PATH__"
 )
 
 // EnvironmentOption defines a functional option for the environment creation
@@ -34,27 +35,58 @@ func WithSnapshotPrefix(prefix string) EnvironmentOption {
 	}
 }
 
+// WithScriptPath sets the path (or URL) of the evaluated script
+func WithScriptPath(path string) EnvironmentOption {
+	return func(env *Environment) {
+		env.store[keyScriptPath] = &String{Value: path}
+	}
+}
+
+// WithSeed sets the seed of the random numbers generator
+func WithSeed(seed int64) EnvironmentOption {
+	return func(env *Environment) {
+		env.SetSeed(seed)
+	}
+}
+
+// session holds the state shared by all the environments
+// of the same evaluation, imported modules included
+type session struct {
+	gContext     gg.GraphicContext
+	random       *rand.Rand
+	seed         int64
+	noise        *noise.Noise
+	animation    *anim.Animation
+	modules      map[string]*Module
+	importing    []string
+	dependencies []string
+	snapshots    int
+}
+
 // Environment is an object that holds a mapping of names to bound objets
 type Environment struct {
-	gContext gg.GraphicContext
-	store    map[string]Object
-	parent   *Environment
+	store   map[string]Object
+	parent  *Environment
+	session *session
 }
 
 // NewEnvironment constructs a new Environment object to hold bindings
 // of identifiers to their names
 func NewEnvironment(ctx gg.GraphicContext, opts ...EnvironmentOption) *Environment {
 	res := &Environment{
-		store:    make(map[string]Object),
-		gContext: ctx,
+		store: make(map[string]Object),
+		session: &session{
+			gContext: ctx,
+			modules:  make(map[string]*Module),
+		},
 	}
 
+	res.SetSeed(time.Now().UnixNano())
+
 	res.store["PI"] = &Float{Value: math.Pi}
 	res.store["HALF_PI"] = &Float{Value: math.Pi / 2}
 	res.store["QUARTER_PI"] = &Float{Value: math.Pi / 4}
 	res.store["TWO_PI"] = &Float{Value: 2.0 * math.Pi}
-	res.store["WIDTH"] = &Float{Value: ctx.Width()}
-	res.store["HEIGHT"] = &Float{Value: ctx.Height()}
 
 	// Loop through each option
 	for _, opt := range opts {
@@ -71,15 +103,44 @@ func NewEnvironment(ctx gg.GraphicContext, opts ...EnvironmentOption) *Environme
 func (e *Environment) Clone() *Environment {
 	// Create a new Environment referring to the same `canvas`
 	env := &Environment{
-		store:    make(map[string]Object),
-		gContext: e.gContext,
+		store:   make(map[string]Object),
+		session: e.session,
 	}
 	env.parent = e
 	return env
 }
 
+// NewModuleEnvironment returns a new top level Environment for the module
+// at the specified path; the new environment shares the graphic context,
+// the settings and the constants of the current one but none of its bindings
+func (e *Environment) NewModuleEnvironment(path string) *Environment {
+	env := &Environment{
+		store:   make(map[string]Object),
+		session: e.session,
+	}
+
+	for k, v := range e.root().store {
+		if IsReserved(k) {
+			env.store[k] = v
+		}
+	}
+	env.store[keyScriptPath] = &String{Value: path}
+
+	return env
+}
+
 // Get returns the object bound by name
 func (e *Environment) Get(name string) (Object, bool) {
+	// the size of the canvas can change at any time
+	if ctx := e.session.gContext; ctx != nil {
+		switch name {
+		case "WIDTH":
+			return &Float{Value: ctx.Width()}, true
+		case "HEIGHT":
+			return &Float{Value: ctx.Height()}, true
+		}
+	}
+
 	obj, ok := e.store[name]
 	if !ok && e.parent != nil {
 		obj, ok = e.parent.Get(name)
@@ -89,7 +150,7 @@ func (e *Environment) Get(name string) (Object, bool) {
 
 // Set stores the object with the given name
 func (e *Environment) Set(name string, val Object) (Object, bool) {
-	if isReserved(name) {
+	if IsReserved(name) {
 		return nil, false
 	}
 
@@ -98,27 +159,144 @@ func (e *Environment) Set(name string, val Object) (Object, bool) {
 }
 
 // GraphicContext returns the graphics context
-func (e *Environment) GraphicContext() gg.GraphicContext { return e.gContext }
+func (e *Environment) GraphicContext() gg.GraphicContext { return e.session.gContext }
 
-// SetGraphicContext sets the graphics context
+// SetGraphicContext sets the graphics context, shared by all
+// the environments of the same evaluation
 func (e *Environment) SetGraphicContext(ctx gg.GraphicContext) {
-	e.gContext = ctx
-	if ctx != nil {
-		e.store["WIDTH"] = &Float{Value: ctx.Width()}
-		e.store["HEIGHT"] = &Float{Value: ctx.Height()}
+	e.session.gContext = ctx
+}
+
+// Animation returns the animation in progress, nil if none.
+func (e *Environment) Animation() *anim.Animation {
+	return e.session.animation
+}
+
+// SetAnimation sets the animation in progress, nil to end it.
+func (e *Environment) SetAnimation(a *anim.Animation) {
+	e.session.animation = a
+}
+
+// Rand returns the random numbers generator, the same
+// seed always generates the same sequence of numbers.
+func (e *Environment) Rand() *rand.Rand {
+	return e.session.random
+}
+
+// Seed returns the seed of the random numbers generator.
+func (e *Environment) Seed() int64 {
+	return e.session.seed
+}
+
+// SetSeed restarts the random numbers generator with the specified seed.
+func (e *Environment) SetSeed(seed int64) {
+	e.session.seed = seed
+	e.session.random = rand.New(rand.NewSource(seed))
+	if e.session.noise != nil {
+		e.session.noise.Reseed(seed)
+	}
+}
+
+// Noise returns the noise generator, seeded with the same
+// seed of the random numbers generator.
+func (e *Environment) Noise() *noise.Noise {
+	if e.session.noise == nil {
+		e.session.noise = noise.New(e.session.seed)
 	}
+	return e.session.noise
+}
+
+// Module returns the already evaluated module at the specified path.
+func (e *Environment) Module(path string) (*Module, bool) {
+	m, ok := e.session.modules[path]
+	return m, ok
+}
+
+// BeginImport marks the module at the specified path as being evaluated;
+// if the module is already being evaluated (an import cycle) it returns
+// an error reporting the chain of imports.
+func (e *Environment) BeginImport(path string) error {
+	for i, el := range e.session.importing {
+		if el == path {
+			chain := append([]string{}, e.session.importing[i:]...)
+			chain = append(chain, path)
+			return fmt.Errorf("import cycle detected: %s", strings.Join(chain, " -> "))
+		}
+	}
+
+	e.session.importing = append(e.session.importing, path)
+	return nil
+}
+
+// EndImport marks the module at the specified path as evaluated,
+// caching it if not nil.
+func (e *Environment) EndImport(path string, m *Module) {
+	if n := len(e.session.importing); n > 0 && e.session.importing[n-1] == path {
+		e.session.importing = e.session.importing[:n-1]
+	}
+
+	if m != nil {
+		e.session.modules[path] = m
+	}
+}
+
+// root returns the outermost enclosing environment
+func (e *Environment) root() *Environment {
+	res := e
+	for res.parent != nil {
+		res = res.parent
+	}
+	return res
 }
 
-// SnapshotFilename returns the snapshot filename
-func (e *Environment) SnapshotFilename() string {
-	pattern := "frame_%04d.png"
+// SnapshotFilename returns the snapshot filename; ext is the filename extension.
+func (e *Environment) SnapshotFilename(ext string) string {
+	pattern := "frame_%04d" + ext
 	if obj, ok := e.Get(keySnapshotPrefix); ok {
 		prefix := obj.(*String).Value
-		pattern = fmt.Sprintf("%s_%%04d.png", prefix)
+		pattern = fmt.Sprintf("%s_%%04d%s", prefix, ext)
 	}
 
-	snapshotCounter = snapshotCounter + 1
-	return fmt.Sprintf(pattern, snapshotCounter)
+	e.session.snapshots = e.session.snapshots + 1
+	return fmt.Sprintf(pattern, e.session.snapshots)
+}
+
+// DocumentFilename returns the filename of the documents, like PDF files,
+// collecting all the snapshots as pages; ext is the filename extension.
+func (e *Environment) DocumentFilename(ext string) string {
+	prefix := "frame"
+	if obj, ok := e.Get(keySnapshotPrefix); ok {
+		prefix = obj.(*String).Value
+	}
+
+	return prefix + ext
+}
+
+// AddDependency records the path (or URL) of a file loaded by the script.
+func (e *Environment) AddDependency(path string) {
+	for _, el := range e.session.dependencies {
+		if el == path {
+			return
+		}
+	}
+	e.session.dependencies = append(e.session.dependencies, path)
+}
+
+// Dependencies returns the paths (or URLs) of all the files
+// loaded by the script, imported modules included.
+func (e *Environment) Dependencies() []string {
+	return e.session.dependencies
+}
+
+// ScriptPath returns the path (or URL) of the evaluated script,
+// empty if unknown (i.e. the script was read from stdin)
+func (e *Environment) ScriptPath() string {
+	obj, ok := e.Get(keyScriptPath)
+	if !ok {
+		return ""
+	}
+
+	return obj.(*String).Value
 }
 
 // SnapshotFolder returns the snapshot output folder
@@ -131,7 +309,8 @@ func (e *Environment) SnapshotFolder() string {
 	return obj.(*String).Value
 }
 
-func isReserved(key string) bool {
+// IsReserved returns true if the name can't be bound by the scripts
+func IsReserved(key string) bool {
 	if strings.HasPrefix(key, "__") && strings.HasSuffix(key, "__") {
 		return true
 	}
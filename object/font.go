@@ -0,0 +1,36 @@
+package object
+
+import (
+	"fmt"
+
+	"github.com/golang/freetype/truetype"
+)
+
+// Font represents a TrueType font, loaded from a file
+// or one of the bundled Go fonts
+type Font struct {
+	Name  string
+	Value *truetype.Font
+}
+
+// Bool implements the Object Bool method
+func (f *Font) Bool() bool { return f.Value != nil }
+
+// Type returns the type of the object
+func (f *Font) Type() Type { return FONT }
+
+// Inspect returns a stringified version of the object for debugging
+func (f *Font) Inspect() string { return fmt.Sprintf("<font %s>", f.Name) }
+
+// ToInterface converts this object to a go-interface, which will allow
+// it to be used naturally in our sprintf/printf primitives.
+//
+// It might also be helpful for embedded users.
+func (f *Font) ToInterface() interface{} { return "<FONT>" }
+
+// Clone creates a new copy
+func (f *Font) Clone() Object {
+	return &Font{Name: f.Name, Value: f.Value}
+}
+
+func (f *Font) String() string { return f.Inspect() }
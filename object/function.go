@@ -1,9 +1,6 @@
 package object
 
 import (
-	"bytes"
-	"strings"
-
 	"github.com/lucasepe/g2d/ast"
 )
 
@@ -25,21 +22,7 @@ func (f *Function) Type() Type { return FUNCTION }
 
 // Inspect returns a stringified version of the object for debugging
 func (f *Function) Inspect() string {
-	var out bytes.Buffer
-
-	params := []string{}
-	for _, p := range f.Parameters {
-		params = append(params, p.String())
-	}
-
-	out.WriteString("fn")
-	out.WriteString("(")
-	out.WriteString(strings.Join(params, ", "))
-	out.WriteString(") {\n")
-	out.WriteString(f.Body.String())
-	out.WriteString("\n}")
-
-	return out.String()
+	return inspectFunction(f.Parameters, f.Body)
 }
 
 // ToInterface converts this object to a go-interface, which will allow
@@ -51,19 +34,7 @@ func (f *Function) ToInterface() interface{} {
 }
 
 func (f *Function) String() string {
-	var out bytes.Buffer
-
-	params := []string{}
-	for _, p := range f.Parameters {
-		params = append(params, p.String())
-	}
-
-	out.WriteString("fn")
-	out.WriteString("(")
-	out.WriteString(strings.Join(params, ", "))
-	out.WriteString(")")
-
-	return out.String()
+	return signature(f.Parameters)
 }
 
 // Return is the return type and used to hold the value of another object.
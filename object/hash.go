@@ -0,0 +1,187 @@
+package object
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// HashKey represents a hash key object and holds the Type of Object
+// hashed and its hash value in Value
+type HashKey struct {
+	Type  Type
+	Value uint64
+}
+
+// Hashable is the interface for all hashable objects which must implement
+// the HashKey() method which returns a HashKey result.
+type Hashable interface {
+	HashKey() HashKey
+}
+
+// HashPair is an object that holds a key and value of type Object
+type HashPair struct {
+	Key   Object
+	Value Object
+}
+
+// Hash is a hash map and holds a map of HashKey to HashPair(s)
+// remembering the keys insertion order
+type Hash struct {
+	Pairs map[HashKey]HashPair
+	order []HashKey
+}
+
+// NewHash creates an empty hash
+func NewHash() *Hash {
+	return &Hash{Pairs: make(map[HashKey]HashPair)}
+}
+
+// Get returns the value associated to the specified key
+func (h *Hash) Get(key Object) (Object, error) {
+	hashable, ok := key.(Hashable)
+	if !ok {
+		return nil, fmt.Errorf("unusable as hash key: %s", key.Type())
+	}
+
+	pair, ok := h.Pairs[hashable.HashKey()]
+	if !ok {
+		return nil, nil
+	}
+	return pair.Value, nil
+}
+
+// Set associates the value to the specified key
+func (h *Hash) Set(key, value Object) error {
+	hashable, ok := key.(Hashable)
+	if !ok {
+		return fmt.Errorf("unusable as hash key: %s", key.Type())
+	}
+
+	hk := hashable.HashKey()
+	if _, ok := h.Pairs[hk]; !ok {
+		h.order = append(h.order, hk)
+	}
+	h.Pairs[hk] = HashPair{Key: key, Value: value}
+	return nil
+}
+
+// Has returns true if the hash contains the specified key
+func (h *Hash) Has(key Object) bool {
+	hashable, ok := key.(Hashable)
+	if !ok {
+		return false
+	}
+
+	_, ok = h.Pairs[hashable.HashKey()]
+	return ok
+}
+
+// Delete removes the specified key, returns true if it was present
+func (h *Hash) Delete(key Object) bool {
+	hashable, ok := key.(Hashable)
+	if !ok {
+		return false
+	}
+
+	hk := hashable.HashKey()
+	if _, ok := h.Pairs[hk]; !ok {
+		return false
+	}
+
+	delete(h.Pairs, hk)
+	for i, el := range h.order {
+		if el == hk {
+			h.order = append(h.order[:i], h.order[i+1:]...)
+			break
+		}
+	}
+	return true
+}
+
+// Keys returns the keys in insertion order
+func (h *Hash) Keys() []Object {
+	res := make([]Object, len(h.order))
+	for i, el := range h.order {
+		res[i] = h.Pairs[el].Key
+	}
+	return res
+}
+
+// Values returns the values in insertion order
+func (h *Hash) Values() []Object {
+	res := make([]Object, len(h.order))
+	for i, el := range h.order {
+		res[i] = h.Pairs[el].Value
+	}
+	return res
+}
+
+// Len complies with Sizeable interface
+func (h *Hash) Len() int {
+	return len(h.Pairs)
+}
+
+// Bool implements the Object Bool method
+func (h *Hash) Bool() bool {
+	return len(h.Pairs) > 0
+}
+
+// Compare complies with Comparable interface
+func (h *Hash) Compare(other Object) int {
+	if obj, ok := other.(*Hash); ok {
+		if len(h.Pairs) != len(obj.Pairs) {
+			return -1
+		}
+		for hk, pair := range h.Pairs {
+			val, ok := obj.Pairs[hk]
+			if !ok {
+				return -1
+			}
+			cmp, ok := pair.Value.(Comparable)
+			if !ok {
+				return -1
+			}
+			if cmp.Compare(val.Value) != 0 {
+				return cmp.Compare(val.Value)
+			}
+		}
+
+		return 0
+	}
+	return -1
+}
+
+// Type returns the type of the object
+func (h *Hash) Type() Type { return HASH }
+
+// Inspect returns a stringified version of the object for debugging
+func (h *Hash) Inspect() string {
+	var out bytes.Buffer
+
+	pairs := []string{}
+	for _, hk := range h.order {
+		pair := h.Pairs[hk]
+		pairs = append(pairs, fmt.Sprintf("%s: %s", pair.Key.Inspect(), pair.Value.Inspect()))
+	}
+
+	out.WriteString("{")
+	out.WriteString(strings.Join(pairs, ", "))
+	out.WriteString("}")
+
+	return out.String()
+}
+
+// ToInterface converts this object to a go-interface, which will allow
+// it to be used naturally in our sprintf/printf primitives.
+//
+// It might also be helpful for embedded users.
+func (h *Hash) ToInterface() interface{} {
+	res := make(map[interface{}]interface{}, len(h.Pairs))
+	for _, pair := range h.Pairs {
+		res[pair.Key.ToInterface()] = pair.Value.ToInterface()
+	}
+	return res
+}
+
+func (h *Hash) String() string { return h.Inspect() }
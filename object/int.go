@@ -43,6 +43,11 @@ func (i *Integer) Clone() Object {
 	return &Integer{Value: i.Value}
 }
 
+// HashKey returns a HashKey object (complies with Hashable interface)
+func (i *Integer) HashKey() HashKey {
+	return HashKey{Type: i.Type(), Value: uint64(i.Value)}
+}
+
 // Inspect returns a stringified version of the object for debugging
 func (i *Integer) Inspect() string { return fmt.Sprintf("%d", i.Value) }
 
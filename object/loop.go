@@ -0,0 +1,43 @@
+package object
+
+// Break is the object used to unwind the evaluation
+// up to the innermost enclosing loop, exiting it
+type Break struct{}
+
+// Bool implements the Object Bool method
+func (b *Break) Bool() bool { return true }
+
+// Type returns the type of the object
+func (b *Break) Type() Type { return BREAK }
+
+// Inspect returns a stringified version of the object for debugging
+func (b *Break) Inspect() string { return "break" }
+
+// ToInterface converts this object to a go-interface, which will allow
+// it to be used naturally in our sprintf/printf primitives.
+//
+// It might also be helpful for embedded users.
+func (b *Break) ToInterface() interface{} { return "<BREAK>" }
+
+func (b *Break) String() string { return b.Inspect() }
+
+// Continue is the object used to unwind the evaluation up
+// to the innermost enclosing loop, starting the next iteration
+type Continue struct{}
+
+// Bool implements the Object Bool method
+func (c *Continue) Bool() bool { return true }
+
+// Type returns the type of the object
+func (c *Continue) Type() Type { return CONTINUE }
+
+// Inspect returns a stringified version of the object for debugging
+func (c *Continue) Inspect() string { return "continue" }
+
+// ToInterface converts this object to a go-interface, which will allow
+// it to be used naturally in our sprintf/printf primitives.
+//
+// It might also be helpful for embedded users.
+func (c *Continue) ToInterface() interface{} { return "<CONTINUE>" }
+
+func (c *Continue) String() string { return c.Inspect() }
@@ -0,0 +1,39 @@
+package object
+
+import (
+	"fmt"
+)
+
+// Module represents an imported g2d script; the top level
+// bindings of the script are the members of the module
+type Module struct {
+	Name string
+	Path string
+	Env  *Environment
+}
+
+// Get returns the member bound by name
+func (m *Module) Get(name string) (Object, bool) {
+	if IsReserved(name) {
+		return nil, false
+	}
+	obj, ok := m.Env.store[name]
+	return obj, ok
+}
+
+// Bool implements the Object Bool method
+func (m *Module) Bool() bool { return true }
+
+// Type returns the type of the object
+func (m *Module) Type() Type { return MODULE }
+
+// Inspect returns a stringified version of the object for debugging
+func (m *Module) Inspect() string { return fmt.Sprintf("<module %s>", m.Name) }
+
+// ToInterface converts this object to a go-interface, which will allow
+// it to be used naturally in our sprintf/printf primitives.
+//
+// It might also be helpful for embedded users.
+func (m *Module) ToInterface() interface{} { return m.Inspect() }
+
+func (m *Module) String() string { return m.Inspect() }
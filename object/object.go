@@ -27,6 +27,12 @@ const (
 	// RETURN is the Return object type
 	RETURN = "return"
 
+	// BREAK is the Break object type
+	BREAK = "break"
+
+	// CONTINUE is the Continue object type
+	CONTINUE = "continue"
+
 	// ERROR is the Error object type
 	ERROR = "error"
 
@@ -41,6 +47,21 @@ const (
 
 	// IMAGE is the Image object type
 	IMAGE = "image"
+
+	// PATTERN is the Pattern object type
+	PATTERN = "pattern"
+
+	// PATH is the Path object type
+	PATH = "path"
+
+	// FONT is the Font object type
+	FONT = "font"
+
+	// HASH is the Hash object type
+	HASH = "hash"
+
+	// MODULE is the Module object type
+	MODULE = "module"
 )
 
 // Comparable is the interface for comparing two Object and their underlying
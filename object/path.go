@@ -0,0 +1,30 @@
+package object
+
+import (
+	"fmt"
+
+	"github.com/lucasepe/g2d/gg"
+)
+
+// Path represents a standalone path that can be built once
+// and drawn many times
+type Path struct {
+	Value *gg.Path
+}
+
+// Bool implements the Object Bool method
+func (p *Path) Bool() bool { return len(p.Value.Segments) > 0 }
+
+// Type returns the type of the object
+func (p *Path) Type() Type { return PATH }
+
+// Inspect returns a stringified version of the object for debugging
+func (p *Path) Inspect() string { return fmt.Sprintf("<path %d segments>", len(p.Value.Segments)) }
+
+// ToInterface converts this object to a go-interface, which will allow
+// it to be used naturally in our sprintf/printf primitives.
+//
+// It might also be helpful for embedded users.
+func (p *Path) ToInterface() interface{} { return "<PATH>" }
+
+func (p *Path) String() string { return p.Inspect() }
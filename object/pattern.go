@@ -0,0 +1,33 @@
+package object
+
+import (
+	"github.com/lucasepe/g2d/gg"
+)
+
+// Pattern represents a paint (a gradient, an image pattern...)
+// usable as fill or stroke color
+type Pattern struct {
+	Value gg.Pattern
+}
+
+// Bool implements the Object Bool method
+func (p *Pattern) Bool() bool { return p.Value != nil }
+
+// Type returns the type of the object
+func (p *Pattern) Type() Type { return PATTERN }
+
+// Inspect returns a stringified version of the object for debugging
+func (p *Pattern) Inspect() string { return "<PATTERN>" }
+
+// ToInterface converts this object to a go-interface, which will allow
+// it to be used naturally in our sprintf/printf primitives.
+//
+// It might also be helpful for embedded users.
+func (p *Pattern) ToInterface() interface{} { return "<PATTERN>" }
+
+// Clone creates a new copy
+func (p *Pattern) Clone() Object {
+	return &Pattern{Value: p.Value}
+}
+
+func (p *Pattern) String() string { return p.Inspect() }
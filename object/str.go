@@ -1,6 +1,7 @@
 package object
 
 import (
+	"hash/fnv"
 	"unicode/utf8"
 )
 
@@ -47,6 +48,14 @@ func (s *String) Compare(other Object) int {
 	return 1
 }
 
+// HashKey returns a HashKey object (complies with Hashable interface)
+func (s *String) HashKey() HashKey {
+	h := fnv.New64a()
+	h.Write([]byte(s.Value))
+
+	return HashKey{Type: s.Type(), Value: h.Sum64()}
+}
+
 // Clone creates a new copy (complies with Immutable interface)
 func (s *String) Clone() Object {
 	return &String{Value: s.Value}
@@ -1078,3 +1078,187 @@ func TestParsingIndexExpressions(t *testing.T) {
 		return
 	}
 }
+
+func TestParsingHashLiterals(t *testing.T) {
+	input := `{"one": 1, "two": 2, "three": 3}`
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	hash, ok := stmt.Expression.(*ast.HashLiteral)
+	if !ok {
+		t.Fatalf("exp is not ast.HashLiteral. got=%T", stmt.Expression)
+	}
+
+	if len(hash.Keys) != 3 {
+		t.Fatalf("hash.Keys has wrong length. got=%d", len(hash.Keys))
+	}
+
+	expected := []struct {
+		key   string
+		value int64
+	}{
+		{"one", 1},
+		{"two", 2},
+		{"three", 3},
+	}
+
+	for i, tt := range expected {
+		literal, ok := hash.Keys[i].(*ast.StringLiteral)
+		if !ok {
+			t.Errorf("key is not ast.StringLiteral. got=%T", hash.Keys[i])
+			continue
+		}
+
+		if literal.Value != tt.key {
+			t.Errorf("key not %q. got=%q", tt.key, literal.Value)
+		}
+
+		testIntegerLiteral(t, hash.Values[i], tt.value)
+	}
+}
+
+func TestParsingEmptyHashLiteral(t *testing.T) {
+	input := "{}"
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	hash, ok := stmt.Expression.(*ast.HashLiteral)
+	if !ok {
+		t.Fatalf("exp is not ast.HashLiteral. got=%T", stmt.Expression)
+	}
+
+	if len(hash.Keys) != 0 {
+		t.Errorf("hash.Keys has wrong length. got=%d", len(hash.Keys))
+	}
+}
+
+func TestParsingSelectorExpressions(t *testing.T) {
+	input := "shape.width"
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	indexExp, ok := stmt.Expression.(*ast.IndexExpression)
+	if !ok {
+		t.Fatalf("exp not *ast.IndexExpression. got=%T", stmt.Expression)
+	}
+
+	if !testIdentifier(t, indexExp.Left, "shape") {
+		return
+	}
+
+	literal, ok := indexExp.Index.(*ast.StringLiteral)
+	if !ok || literal.Value != "width" {
+		t.Errorf("index is not \"width\". got=%T (%+v)", indexExp.Index, indexExp.Index)
+	}
+}
+
+func TestForInExpression(t *testing.T) {
+	input := `for i in range(0, 10, 2) { if (i > 4) { break }; continue; }`
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if len(program.Statements) != 1 {
+		t.Fatalf("program.Statements does not contain %d statements. got=%d\n",
+			1, len(program.Statements))
+	}
+
+	stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+	if !ok {
+		t.Fatalf("program.Statements[0] is not ast.ExpressionStatement. got=%T",
+			program.Statements[0])
+	}
+
+	exp, ok := stmt.Expression.(*ast.ForInExpression)
+	if !ok {
+		t.Fatalf("stmt.Expression is not ast.ForInExpression. got=%T",
+			stmt.Expression)
+	}
+
+	if !testIdentifier(t, exp.Variable, "i") {
+		return
+	}
+
+	call, ok := exp.Iterable.(*ast.CallExpression)
+	if !ok {
+		t.Fatalf("exp.Iterable is not ast.CallExpression. got=%T", exp.Iterable)
+	}
+
+	if !testIdentifier(t, call.Function, "range") {
+		return
+	}
+
+	if len(exp.Body.Statements) != 2 {
+		t.Fatalf("body is not 2 statements. got=%d\n", len(exp.Body.Statements))
+	}
+
+	if _, ok := exp.Body.Statements[1].(*ast.ContinueStatement); !ok {
+		t.Fatalf("Statements[1] is not ast.ContinueStatement. got=%T",
+			exp.Body.Statements[1])
+	}
+
+	ifExp := exp.Body.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.IfExpression)
+	if _, ok := ifExp.Consequence.Statements[0].(*ast.BreakStatement); !ok {
+		t.Fatalf("consequence is not ast.BreakStatement. got=%T",
+			ifExp.Consequence.Statements[0])
+	}
+}
+
+func TestImportStatements(t *testing.T) {
+	tests := []struct {
+		input        string
+		expectedPath string
+		expectedName string
+	}{
+		{`import "lib.g2d"`, "lib.g2d", ""},
+		{`import "../lib/grid.g2d";`, "../lib/grid.g2d", ""},
+		{`import "http://example.com/palette.g2d" as colors`, "http://example.com/palette.g2d", "colors"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if len(program.Statements) != 1 {
+			t.Fatalf("program.Statements does not contain 1 statements. got=%d",
+				len(program.Statements))
+		}
+
+		stmt, ok := program.Statements[0].(*ast.ImportStatement)
+		if !ok {
+			t.Fatalf("program.Statements[0] is not ast.ImportStatement. got=%T",
+				program.Statements[0])
+		}
+
+		if stmt.Path.Value != tt.expectedPath {
+			t.Errorf("stmt.Path.Value not %q. got=%q", tt.expectedPath, stmt.Path.Value)
+		}
+
+		if tt.expectedName == "" {
+			if stmt.Name != nil {
+				t.Errorf("stmt.Name is not nil. got=%q", stmt.Name.Value)
+			}
+			continue
+		}
+
+		if !testIdentifier(t, stmt.Name, tt.expectedName) {
+			return
+		}
+	}
+}
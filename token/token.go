@@ -118,6 +118,16 @@ const (
 	RETURN = "RETURN"
 	// WHILE the `while` keyword (while)
 	WHILE = "WHILE"
+	// FOR the `for` keyword (for)
+	FOR = "FOR"
+	// IN the `in` keyword (in)
+	IN = "IN"
+	// BREAK the `break` keyword (break)
+	BREAK = "BREAK"
+	// CONTINUE the `continue` keyword (continue)
+	CONTINUE = "CONTINUE"
+	// IMPORT the `import` keyword (import)
+	IMPORT = "IMPORT"
 
 	// SWITCH the `switc` keyword
 	SWITCH = "switch"
@@ -137,6 +147,12 @@ var keywords = map[string]Type{
 	"return": RETURN,
 	"while":  WHILE,
 
+	"for":      FOR,
+	"in":       IN,
+	"break":    BREAK,
+	"continue": CONTINUE,
+	"import":   IMPORT,
+
 	"case":    CASE,
 	"switch":  SWITCH,
 	"default": DEFAULT,
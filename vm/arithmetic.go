@@ -0,0 +1,77 @@
+package vm
+
+import (
+	"github.com/lucasepe/g2d/eval"
+	"github.com/lucasepe/g2d/object"
+)
+
+// arithmetic applies the most common operators to numbers without the
+// type switches of eval.Infix, it returns false for any other operation
+func arithmetic(operator string, left, right object.Object) (object.Object, bool) {
+	switch l := left.(type) {
+	case *object.Integer:
+		switch r := right.(type) {
+		case *object.Integer:
+			return integerArithmetic(operator, l.Value, r.Value)
+		case *object.Float:
+			return floatArithmetic(operator, float64(l.Value), r.Value)
+		}
+
+	case *object.Float:
+		switch r := right.(type) {
+		case *object.Integer:
+			return floatArithmetic(operator, l.Value, float64(r.Value))
+		case *object.Float:
+			return floatArithmetic(operator, l.Value, r.Value)
+		}
+	}
+
+	return nil, false
+}
+
+func integerArithmetic(operator string, l, r int64) (object.Object, bool) {
+	switch operator {
+	case "+":
+		return &object.Integer{Value: l + r}, true
+	case "-":
+		return &object.Integer{Value: l - r}, true
+	case "*":
+		return &object.Integer{Value: l * r}, true
+	case "%":
+		if r == 0 {
+			break
+		}
+		return &object.Integer{Value: l % r}, true
+	case "<":
+		return eval.NativeBool(l < r), true
+	case "<=":
+		return eval.NativeBool(l <= r), true
+	case ">":
+		return eval.NativeBool(l > r), true
+	case ">=":
+		return eval.NativeBool(l >= r), true
+	case "==":
+		return eval.NativeBool(l == r), true
+	case "!=":
+		return eval.NativeBool(l != r), true
+	}
+
+	return nil, false
+}
+
+// floatArithmetic leaves the comparisons to eval.Infix
+// since floats are compared with a tolerance
+func floatArithmetic(operator string, l, r float64) (object.Object, bool) {
+	switch operator {
+	case "+":
+		return &object.Float{Value: l + r}, true
+	case "-":
+		return &object.Float{Value: l - r}, true
+	case "*":
+		return &object.Float{Value: l * r}, true
+	case "/":
+		return &object.Float{Value: l / r}, true
+	}
+
+	return nil, false
+}
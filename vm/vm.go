@@ -0,0 +1,475 @@
+package vm
+
+// Package vm implements the virtual machine -- a stack based interpreter
+// of the bytecode produced by the compiler; it runs the same programs and
+// builtins of the evaluator (with the same semantics) but faster.
+
+import (
+	"github.com/lucasepe/g2d/ast"
+	"github.com/lucasepe/g2d/builtins"
+	"github.com/lucasepe/g2d/code"
+	"github.com/lucasepe/g2d/compiler"
+	"github.com/lucasepe/g2d/eval"
+	"github.com/lucasepe/g2d/lexer"
+	"github.com/lucasepe/g2d/object"
+	"github.com/lucasepe/g2d/token"
+)
+
+const (
+	// StackSize is the maximum number of values on the stack
+	StackSize = 2048
+
+	// MaxFrames is the maximum depth of the function calls
+	MaxFrames = 1024
+)
+
+// frame holds the state of a function call
+type frame struct {
+	cl    *object.Closure
+	ip    int
+	bp    int
+	scope *object.Scope
+
+	// stack pointers marked at the beginning of the loops
+	marks []int
+}
+
+// VM executes the bytecode of a compiled program
+type VM struct {
+	stack []object.Object
+	sp    int
+
+	frames []frame
+	fp     int
+}
+
+// New returns a virtual machine that runs the main function of
+// a compiled program binding the top level names in env
+func New(main *object.CompiledFunction, env *object.Environment) *VM {
+	vm := &VM{
+		stack:  make([]object.Object, StackSize),
+		frames: make([]frame, MaxFrames),
+	}
+
+	vm.frames[0] = frame{cl: &object.Closure{Fn: main, Env: env}}
+	vm.fp = 1
+
+	return vm
+}
+
+// BeginRun (program, env, lexer) object.Object
+// REPL and testing modules call this function to init the lexer used for
+// error location, it's the counterpart of eval.BeginEval
+func BeginRun(program *ast.Program, env *object.Environment, l *lexer.Lexer) object.Object {
+	eval.SetLexer(l)
+	return Run(program, env)
+}
+
+// Run compiles and runs the program, returns the value of the
+// last statement (or of the return statement) or the error
+func Run(program *ast.Program, env *object.Environment) object.Object {
+	main, err := compiler.Compile(program)
+	if err != nil {
+		return object.NewError("CompileError: %s", err.Error())
+	}
+
+	return New(main, env).Run()
+}
+
+// Run executes the main function, returns its value or the error
+func (vm *VM) Run() object.Object {
+	f := &vm.frames[vm.fp-1]
+	fn := f.cl.Fn
+	ins := fn.Instructions
+
+	for {
+		ip := f.ip
+		op := code.Opcode(ins[ip])
+
+		switch op {
+		case code.OpConstant:
+			idx := code.ReadUint16(ins[ip+1:])
+			f.ip = ip + 3
+			if err := vm.push(fn.Constants[idx]); err != nil {
+				return err
+			}
+
+		case code.OpPop:
+			f.ip = ip + 1
+			vm.sp--
+
+		case code.OpTrue, code.OpFalse, code.OpNull:
+			f.ip = ip + 1
+
+			var obj object.Object = eval.NULL
+			if op == code.OpTrue {
+				obj = eval.TRUE
+			} else if op == code.OpFalse {
+				obj = eval.FALSE
+			}
+
+			if err := vm.push(obj); err != nil {
+				return err
+			}
+
+		case code.OpInfix:
+			operator := fn.Names[code.ReadUint16(ins[ip+1:])]
+			f.ip = ip + 3
+
+			right := vm.stack[vm.sp-1]
+			left := vm.stack[vm.sp-2]
+
+			res, ok := arithmetic(operator, left, right)
+			if !ok {
+				res = eval.Infix(fn.Tokens[ip], operator, left, right)
+				if isError(res) {
+					return res
+				}
+			}
+
+			vm.sp--
+			vm.stack[vm.sp-1] = res
+
+		case code.OpPrefix:
+			operator := fn.Names[code.ReadUint16(ins[ip+1:])]
+			f.ip = ip + 3
+
+			res := eval.Prefix(fn.Tokens[ip], operator, vm.stack[vm.sp-1])
+			if isError(res) {
+				return res
+			}
+			vm.stack[vm.sp-1] = res
+
+		case code.OpJump:
+			f.ip = int(code.ReadUint32(ins[ip+1:]))
+
+		case code.OpJumpNotTruthy:
+			f.ip = ip + 5
+			vm.sp--
+			if !eval.IsTruthy(vm.stack[vm.sp]) {
+				f.ip = int(code.ReadUint32(ins[ip+1:]))
+			}
+
+		case code.OpGetName:
+			name := fn.Names[code.ReadUint16(ins[ip+1:])]
+			f.ip = ip + 3
+
+			obj := vm.lookup(f, ip, name)
+			if isError(obj) {
+				return obj
+			}
+			if err := vm.push(obj); err != nil {
+				return err
+			}
+
+		case code.OpBindName, code.OpAssignName:
+			name := fn.Names[code.ReadUint16(ins[ip+1:])]
+			f.ip = ip + 3
+
+			vm.sp--
+			val := vm.stack[vm.sp]
+			if immutable, ok := val.(object.Immutable); ok && op == code.OpBindName {
+				val = immutable.Clone()
+			}
+
+			if _, ok := f.cl.Env.Set(name, val); !ok {
+				return eval.NewError(fn.Tokens[ip], "reserved keyword `%s`", name)
+			}
+
+		case code.OpGetVar:
+			v := &fn.Vars[code.ReadUint16(ins[ip+1:])]
+			f.ip = ip + 3
+
+			var obj object.Object
+			for _, el := range v.Slots {
+				scope := f.scope
+				for i := el.Depth; i > 0; i-- {
+					scope = scope.Parent
+				}
+				if obj = scope.Values[el.Index]; obj != nil {
+					break
+				}
+			}
+
+			if obj == nil {
+				obj = vm.lookup(f, ip, v.Name)
+				if isError(obj) {
+					return obj
+				}
+			}
+
+			if err := vm.push(obj); err != nil {
+				return err
+			}
+
+		case code.OpBindLocal, code.OpAssignLocal:
+			idx := code.ReadUint16(ins[ip+1:])
+			f.ip = ip + 3
+
+			vm.sp--
+			val := vm.stack[vm.sp]
+			if immutable, ok := val.(object.Immutable); ok && op == code.OpBindLocal {
+				val = immutable.Clone()
+			}
+			f.scope.Values[idx] = val
+
+		case code.OpArray:
+			n := int(code.ReadUint16(ins[ip+1:]))
+			f.ip = ip + 3
+
+			elements := make([]object.Object, n)
+			copy(elements, vm.stack[vm.sp-n:vm.sp])
+			vm.sp = vm.sp - n
+
+			if err := vm.push(&object.Array{Elements: elements}); err != nil {
+				return err
+			}
+
+		case code.OpHash:
+			n := int(code.ReadUint16(ins[ip+1:]))
+			f.ip = ip + 3
+
+			hash := object.NewHash()
+			for i := vm.sp - 2*n; i < vm.sp; i += 2 {
+				if err := hash.Set(vm.stack[i], vm.stack[i+1]); err != nil {
+					return eval.NewError(fn.Tokens[ip], "%s", err.Error())
+				}
+			}
+			vm.sp = vm.sp - 2*n
+
+			if err := vm.push(hash); err != nil {
+				return err
+			}
+
+		case code.OpIndex:
+			f.ip = ip + 1
+
+			res := eval.Index(fn.Tokens[ip], vm.stack[vm.sp-2], vm.stack[vm.sp-1])
+			if isError(res) {
+				return res
+			}
+
+			vm.sp--
+			vm.stack[vm.sp-1] = res
+
+		case code.OpSetIndex:
+			f.ip = ip + 1
+
+			value, left, index := vm.stack[vm.sp-3], vm.stack[vm.sp-2], vm.stack[vm.sp-1]
+			res := eval.SetIndex(fn.Tokens[ip], left, index, value)
+			if isError(res) {
+				return res
+			}
+
+			vm.sp = vm.sp - 2
+			vm.stack[vm.sp-1] = res
+
+		case code.OpClosure:
+			cf := fn.Constants[code.ReadUint16(ins[ip+1:])].(*object.CompiledFunction)
+			f.ip = ip + 3
+
+			cl := &object.Closure{Fn: cf, Scope: f.scope, Env: f.cl.Env}
+			if err := vm.push(cl); err != nil {
+				return err
+			}
+
+		case code.OpCall:
+			n := int(code.ReadUint8(ins[ip+1:]))
+			f.ip = ip + 2
+
+			switch callee := vm.stack[vm.sp-1-n].(type) {
+			case *object.Closure:
+				if err := vm.call(callee, n, fn.Tokens[ip]); err != nil {
+					return err
+				}
+				f = &vm.frames[vm.fp-1]
+				fn = f.cl.Fn
+				ins = fn.Instructions
+
+			case *object.Builtin:
+				args := make([]object.Object, n)
+				copy(args, vm.stack[vm.sp-n:vm.sp])
+
+				res := callee.Fn(f.cl.Env, args...)
+				if res == nil {
+					res = eval.NULL
+				}
+				if isError(res) {
+					return res
+				}
+
+				vm.sp = vm.sp - n
+				vm.stack[vm.sp-1] = res
+
+			default:
+				return eval.NewError(fn.Tokens[ip], "not a function: %s", callee.Type())
+			}
+
+		case code.OpReturnValue:
+			res := vm.stack[vm.sp-1]
+			if vm.fp == 1 {
+				return res
+			}
+
+			vm.sp = f.bp
+			vm.stack[vm.sp-1] = res
+
+			vm.fp--
+			f = &vm.frames[vm.fp-1]
+			fn = f.cl.Fn
+			ins = fn.Instructions
+
+		case code.OpLoop:
+			f.ip = ip + 1
+			f.marks = append(f.marks, vm.sp)
+
+		case code.OpUnwind:
+			f.ip = ip + 1
+			vm.sp = f.marks[len(f.marks)-1]
+
+		case code.OpLoopEnd:
+			n := int(code.ReadUint8(ins[ip+1:]))
+			f.ip = ip + 2
+
+			f.marks = f.marks[:len(f.marks)-1]
+			if n > 0 {
+				res := vm.stack[vm.sp-1]
+				vm.sp = vm.sp - n
+				vm.stack[vm.sp-1] = res
+			}
+
+		case code.OpIter:
+			f.ip = ip + 1
+
+			items, err := eval.Iterate(fn.Tokens[ip], vm.stack[vm.sp-1])
+			if err != nil {
+				return err
+			}
+			vm.stack[vm.sp-1] = &iterator{items: items}
+
+		case code.OpIterNext:
+			f.ip = ip + 5
+
+			it := vm.stack[vm.sp-2].(*iterator)
+			if it.next >= len(it.items) {
+				f.ip = int(code.ReadUint32(ins[ip+1:]))
+				continue
+			}
+
+			it.next++
+			if err := vm.push(it.items[it.next-1]); err != nil {
+				return err
+			}
+
+		case code.OpCase:
+			f.ip = ip + 1
+
+			vm.sp--
+			matches := eval.CaseMatches(vm.stack[vm.sp-1], vm.stack[vm.sp])
+			if err := vm.push(eval.NativeBool(matches)); err != nil {
+				return err
+			}
+
+		case code.OpImport:
+			is := fn.Imports[code.ReadUint16(ins[ip+1:])]
+			f.ip = ip + 3
+
+			module := eval.ImportModule(is, f.cl.Env, Run)
+			if isError(module) {
+				return module
+			}
+			if err := vm.push(module); err != nil {
+				return err
+			}
+
+		case code.OpRaise:
+			msg := fn.Names[code.ReadUint16(ins[ip+1:])]
+			return eval.NewError(fn.Tokens[ip], "%s", msg)
+
+		default:
+			return object.NewError("InternalError: unknown opcode %d", op)
+		}
+	}
+}
+
+func (vm *VM) push(obj object.Object) object.Object {
+	if vm.sp >= StackSize {
+		return object.NewError("StackOverflowError: too many values on the stack")
+	}
+
+	vm.stack[vm.sp] = obj
+	vm.sp++
+	return nil
+}
+
+// call pushes the frame of the closure called with
+// the n arguments on top of the stack
+func (vm *VM) call(cl *object.Closure, n int, tok token.Token) object.Object {
+	cf := cl.Fn
+	if n < len(cf.Parameters) {
+		return eval.NewError(tok, "argument `%s` to function `%s` is missing", cf.Parameters[n].Value, cl)
+	}
+
+	if vm.fp >= MaxFrames {
+		return object.NewError("StackOverflowError: maximum call depth (%d) exceeded", MaxFrames)
+	}
+
+	scope := &object.Scope{Values: make([]object.Object, cf.NumLocals), Parent: cl.Scope}
+	for i, slot := range cf.ParameterSlots {
+		if slot >= 0 {
+			scope.Values[slot] = vm.stack[vm.sp-n+i]
+		}
+	}
+	vm.sp = vm.sp - n
+
+	vm.frames[vm.fp] = frame{
+		cl:    cl,
+		bp:    vm.sp,
+		scope: scope,
+		marks: vm.frames[vm.fp].marks[:0],
+	}
+	vm.fp++
+
+	return nil
+}
+
+// lookup returns the object bound to the name in the environment of
+// the frame or the builtin function, ip is the offset of the instruction
+func (vm *VM) lookup(f *frame, ip int, name string) object.Object {
+	if val, ok := f.cl.Env.Get(name); ok {
+		return val
+	}
+
+	if builtin, ok := builtins.Builtins[name]; ok {
+		return builtin
+	}
+
+	return eval.NewError(f.cl.Fn.Tokens[ip], "identifier `%s` not found", name)
+}
+
+func isError(obj object.Object) bool {
+	if obj != nil {
+		return obj.Type() == object.ERROR
+	}
+	return false
+}
+
+// iterator holds the items of the iterable object of a for loop
+type iterator struct {
+	items []object.Object
+	next  int
+}
+
+// Bool implements the Object Bool method
+func (it *iterator) Bool() bool { return true }
+
+// Type returns the type of the object
+func (it *iterator) Type() object.Type { return "iterator" }
+
+// Inspect returns a stringified version of the object for debugging
+func (it *iterator) Inspect() string { return "<iterator>" }
+
+// ToInterface converts this object to a go-interface
+func (it *iterator) ToInterface() interface{} { return "<ITERATOR>" }
+
+func (it *iterator) String() string { return it.Inspect() }
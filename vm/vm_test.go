@@ -0,0 +1,166 @@
+package vm
+
+import (
+	"testing"
+
+	"github.com/lucasepe/g2d/compiler"
+	"github.com/lucasepe/g2d/lexer"
+	"github.com/lucasepe/g2d/object"
+	"github.com/lucasepe/g2d/parser"
+)
+
+// testRun runs the input, checking that the stack holds only the
+// result and that no loop is left marked on the main frame
+func testRun(t *testing.T, input string) object.Object {
+	t.Helper()
+
+	p := parser.New(lexer.New(input))
+	program := p.ParseProgram()
+	if len(p.Errors()) > 0 {
+		t.Fatalf("parser errors: %v", p.Errors())
+	}
+
+	main, err := compiler.Compile(program)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	vm := New(main, object.NewEnvironment(nil))
+	res := vm.Run()
+	if isError(res) {
+		return res
+	}
+
+	if vm.fp != 1 {
+		t.Errorf("%s: wrong frame pointer. expected=1, got=%d", input, vm.fp)
+	}
+	if vm.sp != 1 {
+		t.Errorf("%s: wrong stack pointer. expected=1, got=%d", input, vm.sp)
+	}
+	if n := len(vm.frames[0].marks); n != 0 {
+		t.Errorf("%s: %d loops left marked", input, n)
+	}
+	return res
+}
+
+func testInteger(t *testing.T, input string, obj object.Object, expected int64) {
+	t.Helper()
+
+	res, ok := obj.(*object.Integer)
+	if !ok {
+		t.Errorf("%s: object is not Integer. got=%T (%+v)", input, obj, obj)
+		return
+	}
+	if res.Value != expected {
+		t.Errorf("%s: object has wrong value. got=%d, want=%d", input, res.Value, expected)
+	}
+}
+
+func TestClosureAssignment(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		// assigning a name bound by an enclosing function binds
+		// a local of the closure, the enclosing one is unchanged
+		{"f := fn() { x := 1; g := fn() { x = x + 1; x }; g() + g() }; f()", 4},
+		{"f := fn() { x := 1; g := fn() { x = x + 1; x }; g(); x }; f()", 1},
+		{"f := fn(x) { g := fn() { x = x * 10; x }; g() + x }; f(2)", 22},
+		{"y := 10; h := fn() { y = y + 1; y }; h()", 11},
+		{"y := 10; h := fn() { y = y + 1; y }; h(); y", 10},
+
+		// the closure reads the value of the enclosing
+		// function at the time of the call
+		{"f := fn() { x := 1; g := fn() { x }; x = 5; g() }; f()", 5},
+		{"f := fn(n) { fn(m) { n + m } }; add := f(3); add(4)", 7},
+
+		// once assigned, the local of the closure wins
+		{"f := fn() { x := 1; g := fn(a) { if (a) { x = 100 }; x }; g(false) + g(true) }; f()", 101},
+	}
+
+	for _, tt := range tests {
+		testInteger(t, tt.input, testRun(t, tt.input), tt.expected)
+	}
+}
+
+func TestLoopUnwinding(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"s := 0; for i in range(3) { for j in range(3) { if (j == 1) { continue }; if (i == 2) { break }; s = s + 1 } }; s", 4},
+		{"s := 0; for i in range(5) { while (true) { s = s + 1; if (s % 2 == 0) { break } }; if (i == 3) { break } }; s", 8},
+		{"s := 0; i := 0; while (i < 4) { i = i + 1; for j in [1, 2, 3] { if (j == i) { break }; s = s + j }; if (i == 2) { continue } }; s", 10},
+
+		// values pushed by the enclosing expressions are kept
+		{"s := 0; for i in range(3) { a := [i, for j in range(3) { if (j == i) { break } }, 2]; s = s + a[0] + a[2] }; s", 9},
+		{"len([1, while (true) { break }, for i in range(3) { if (i == 1) { continue } }, 2])", 4},
+	}
+
+	for _, tt := range tests {
+		testInteger(t, tt.input, testRun(t, tt.input), tt.expected)
+	}
+}
+
+func TestLoopMarksAcrossCalls(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		// returning from a loop leaves the marks of the callee
+		// behind, the frame reused by the next call starts clean
+		{"k := fn(n) { r := 0; for i in range(n) { if (i == 3) { return r }; r = r + i }; r }; t := 0; for i in range(4) { t = t + k(i + 2) }; t", 10},
+		{"k := fn() { for i in range(3) { while (true) { return i } } }; t := 0; for i in range(3) { t = t + k(); if (i == 1) { break } }; t", 0},
+
+		// a loop in the callee doesn't break the loop of the caller
+		{"k := fn(n) { for i in range(n) { if (i == 1) { break } }; n }; t := 0; for i in range(5) { if (i == 4) { break }; t = t + k(i) }; t", 6},
+		{"f := fn(n) { if (n == 0) { return 0 }; s := 0; for i in range(2) { s = s + f(n - 1) + 1; continue }; s }; f(3)", 14},
+	}
+
+	for _, tt := range tests {
+		testInteger(t, tt.input, testRun(t, tt.input), tt.expected)
+	}
+}
+
+func TestLoopMarksReused(t *testing.T) {
+	input := "k := fn() { for i in range(3) { while (true) { return i } } }; for i in range(100) { k() }"
+
+	p := parser.New(lexer.New(input))
+	main, err := compiler.Compile(p.ParseProgram())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	vm := New(main, object.NewEnvironment(nil))
+	if res := vm.Run(); isError(res) {
+		t.Fatal(res.Inspect())
+	}
+
+	// only the marks of the last call are left behind
+	if n := len(vm.frames[1].marks); n != 2 {
+		t.Errorf("wrong number of marks of the callee frame. expected=2, got=%d", n)
+	}
+}
+
+func TestBuiltinErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"range(0, 1, 0)", "ValueError: range() argument #3 `step` must not be zero"},
+		{"len(range(0, 1, 0))", "ValueError: range() argument #3 `step` must not be zero"},
+		{"f := fn(n) { for i in range(n) { if (i == 2) { return len(1) } } }; for j in range(3) { f(j + 1) }", "TypeError: object of type 'int' has no len()"},
+		{"f := fn() { while (true) { g := fn() { range(0, 1, 0) }; g() } }; f(); 1", "ValueError: range() argument #3 `step` must not be zero"},
+	}
+
+	for _, tt := range tests {
+		res, ok := testRun(t, tt.input).(*object.Error)
+		if !ok {
+			t.Errorf("%s: no error object returned. got=%T(%+v)", tt.input, res, res)
+			continue
+		}
+		if res.Message != tt.expected {
+			t.Errorf("%s: wrong error message. expected=%q, got=%q", tt.input, tt.expected, res.Message)
+		}
+	}
+}